@@ -0,0 +1,287 @@
+// Package httpaction provides a configurable execute hook calling an HTTP
+// service, with request templating from the context, retries and mapping of
+// the response back into the context.
+package httpaction
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/leoslamas/dredd-go/rule"
+)
+
+// Config describes an HTTP call. It can be built in Go or decoded from a
+// JSON or YAML document, where durations are written as "2s" or "150ms".
+//
+// URL, header values and Body are text/template templates executed with the
+// context values as data, e.g. "https://api/users/{{.user_id}}" or
+// `{{index . "order.id"}}` for keys that are not identifiers. Values are
+// inserted as is: in URLs, those that are not trusted must be escaped with
+// the pathescape function in paths and the urlquery one in queries, e.g.
+// "https://api/search/{{pathescape .name}}?q={{urlquery .query}}".
+type Config struct {
+	Method  string            `json:"method" yaml:"method"`
+	URL     string            `json:"url" yaml:"url"`
+	Headers map[string]string `json:"headers" yaml:"headers"`
+	Body    string            `json:"body" yaml:"body"`
+	Timeout time.Duration     `json:"timeout" yaml:"timeout"`
+	Retry   RetryPolicy       `json:"retry" yaml:"retry"`
+
+	// StatusKey receives the response status code.
+	StatusKey string `json:"status_key" yaml:"status_key"`
+	// BodyKey receives the raw response body as a string.
+	BodyKey string `json:"body_key" yaml:"body_key"`
+	// JSONKeys maps top-level fields of a JSON response body to context keys.
+	JSONKeys map[string]string `json:"json_keys" yaml:"json_keys"`
+	// ErrorKey receives the error of a failed call.
+	ErrorKey string `json:"error_key" yaml:"error_key"`
+}
+
+// UnmarshalJSON decodes the config, reading its timeout as a duration string.
+func (c *Config) UnmarshalJSON(data []byte) error {
+	type plain Config
+	var doc = struct {
+		*plain
+		Timeout duration `json:"timeout"`
+	}{plain: (*plain)(c), Timeout: duration(c.Timeout)}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+	c.Timeout = time.Duration(doc.Timeout)
+	return nil
+}
+
+// RetryPolicy configures how failed calls are retried. Transport errors and
+// the listed status codes are retried, 5xx responses when none are listed.
+// Retries stop when the GoContext of the run is done.
+type RetryPolicy struct {
+	Attempts int           `json:"attempts" yaml:"attempts"`
+	Backoff  time.Duration `json:"backoff" yaml:"backoff"`
+	Statuses []int         `json:"statuses" yaml:"statuses"`
+}
+
+// UnmarshalJSON decodes the policy, reading its backoff as a duration string.
+func (p *RetryPolicy) UnmarshalJSON(data []byte) error {
+	type plain RetryPolicy
+	var doc = struct {
+		*plain
+		Backoff duration `json:"backoff"`
+	}{plain: (*plain)(p), Backoff: duration(p.Backoff)}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+	p.Backoff = time.Duration(doc.Backoff)
+	return nil
+}
+
+// duration is a time.Duration decoded from JSON as a string such as "2s", or
+// as a number of nanoseconds.
+type duration time.Duration
+
+func (d *duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		var n int64
+		if err := json.Unmarshal(data, &n); err != nil {
+			return fmt.Errorf("invalid duration %s", data)
+		}
+		*d = duration(n)
+		return nil
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = duration(v)
+	return nil
+}
+
+// Action is an execute hook performing the configured HTTP call.
+type Action struct {
+	config  Config
+	client  *http.Client
+	url     *template.Template
+	body    *template.Template
+	headers map[string]*template.Template
+}
+
+// New validates the config and compiles its templates.
+// A nil client uses http.DefaultClient.
+func New(config Config, client *http.Client) (*Action, error) {
+	if config.Method == "" {
+		config.Method = http.MethodGet
+	}
+	if config.URL == "" {
+		return nil, fmt.Errorf("httpaction: missing url")
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	var a = &Action{config: config, client: client, headers: make(map[string]*template.Template)}
+	var err error
+	if a.url, err = template.New("url").Funcs(template.FuncMap{"pathescape": pathEscape}).Parse(config.URL); err != nil {
+		return nil, fmt.Errorf("httpaction: url: %w", err)
+	}
+	if a.body, err = template.New("body").Parse(config.Body); err != nil {
+		return nil, fmt.Errorf("httpaction: body: %w", err)
+	}
+	for name, value := range config.Headers {
+		if a.headers[name], err = template.New(name).Parse(value); err != nil {
+			return nil, fmt.Errorf("httpaction: header %s: %w", name, err)
+		}
+	}
+	return a, nil
+}
+
+// Execute performs the call with the GoContext of the run, so the request is
+// canceled with the run and carries its deadline and tracing values. It is
+// meant to be passed to OnExecute.
+func (a *Action) Execute(ctx rule.Context) {
+	if err := a.ExecuteE(ctx); err != nil && a.config.ErrorKey != "" {
+		ctx.GetRuleContext().Set(a.config.ErrorKey, err)
+	}
+}
+
+// ExecuteE performs the call as Execute does, but returns its error, so the
+// run stops when the call fails. It is meant to be passed to
+// OnExecuteWithError.
+func (a *Action) ExecuteE(ctx rule.Context) error {
+	var rc = ctx.GetRuleContext()
+	return a.call(rc.GoContext(), rc)
+}
+
+func (a *Action) call(ctx context.Context, rc *rule.RuleContext) error {
+	var data = rc.Values()
+	url, err := render(a.url, data)
+	if err != nil {
+		return err
+	}
+	body, err := render(a.body, data)
+	if err != nil {
+		return err
+	}
+	var headers = make(http.Header, len(a.headers))
+	for name, t := range a.headers {
+		value, err := render(t, data)
+		if err != nil {
+			return err
+		}
+		headers.Set(name, value)
+	}
+
+	var attempts = max(a.config.Retry.Attempts, 1)
+	var status int
+	var payload []byte
+	for attempt := 1; ; attempt++ {
+		status, payload, err = a.do(ctx, url, headers, body)
+		if !a.retryable(status, err) || attempt == attempts {
+			break
+		}
+		if err := wait(ctx, a.config.Retry.Backoff); err != nil {
+			return fmt.Errorf("httpaction: %w", err)
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	if a.config.StatusKey != "" {
+		rc.Set(a.config.StatusKey, status)
+	}
+	if status >= 400 {
+		return fmt.Errorf("httpaction: %s %s: status %d", a.config.Method, url, status)
+	}
+	if a.config.BodyKey != "" {
+		rc.Set(a.config.BodyKey, string(payload))
+	}
+	if len(a.config.JSONKeys) > 0 {
+		var fields map[string]interface{}
+		if err := json.Unmarshal(payload, &fields); err != nil {
+			return fmt.Errorf("httpaction: decode response: %w", err)
+		}
+		for field, key := range a.config.JSONKeys {
+			if value, ok := fields[field]; ok {
+				rc.Set(key, value)
+			}
+		}
+	}
+	return nil
+}
+
+func (a *Action) do(ctx context.Context, url string, headers http.Header, body string) (int, []byte, error) {
+	if a.config.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, a.config.Timeout)
+		defer cancel()
+	}
+
+	var reader io.Reader
+	if body != "" {
+		reader = strings.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, a.config.Method, url, reader)
+	if err != nil {
+		return 0, nil, fmt.Errorf("httpaction: %w", err)
+	}
+	req.Header = headers.Clone()
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return 0, nil, fmt.Errorf("httpaction: %w", err)
+	}
+	defer resp.Body.Close()
+
+	payload, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return resp.StatusCode, nil, fmt.Errorf("httpaction: read response: %w", err)
+	}
+	return resp.StatusCode, payload, nil
+}
+
+func (a *Action) retryable(status int, err error) bool {
+	if err != nil {
+		return true
+	}
+	if len(a.config.Retry.Statuses) == 0 {
+		return status >= 500
+	}
+	for _, s := range a.config.Retry.Statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// wait waits for d, or until ctx is done.
+func wait(ctx context.Context, d time.Duration) error {
+	var timer = time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func pathEscape(value interface{}) string {
+	return url.PathEscape(fmt.Sprint(value))
+}
+
+func render(t *template.Template, data map[string]interface{}) (string, error) {
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("httpaction: %w", err)
+	}
+	return buf.String(), nil
+}
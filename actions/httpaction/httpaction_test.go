@@ -0,0 +1,223 @@
+package httpaction
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/leoslamas/dredd-go/rule"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func run(a *Action, values map[string]interface{}) *rule.RuleContext {
+	ruleContext := rule.NewRuleContext()
+	for k, v := range values {
+		ruleContext.Set(k, v)
+	}
+	rule.ChainRuleRunner(ruleContext, rule.NewChainRule().OnExecute(a.Execute))
+	return ruleContext
+}
+
+func TestAction_TemplatesRequestAndMapsResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/users/42/score", r.URL.Path)
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		assert.Equal(t, `{"amount": 99.5}`, string(body))
+		w.Write([]byte(`{"score": 0.8, "tier": "gold"}`))
+	}))
+	defer server.Close()
+
+	a, err := New(Config{
+		Method:    http.MethodPost,
+		URL:       server.URL + "/users/{{.user_id}}/score",
+		Headers:   map[string]string{"Authorization": "Bearer {{.token}}"},
+		Body:      `{"amount": {{index . "order.amount"}}}`,
+		StatusKey: "status",
+		JSONKeys:  map[string]string{"score": "user.score"},
+	}, server.Client())
+	assert.NoError(t, err)
+
+	ruleContext := run(a, map[string]interface{}{"user_id": 42, "token": "secret", "order.amount": 99.5})
+
+	assert.Equal(t, 200, ruleContext.Get("status"))
+	assert.Equal(t, 0.8, ruleContext.Get("user.score"))
+	assert.Nil(t, ruleContext.Get("tier"))
+}
+
+func TestAction_Retries(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	a, err := New(Config{URL: server.URL, BodyKey: "body", Retry: RetryPolicy{Attempts: 3}}, nil)
+	assert.NoError(t, err)
+
+	ruleContext := run(a, nil)
+
+	assert.Equal(t, 3, calls)
+	assert.Equal(t, "ok", ruleContext.Get("body"))
+}
+
+func TestAction_ErrorKey(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	a, err := New(Config{URL: server.URL, ErrorKey: "error", StatusKey: "status", Retry: RetryPolicy{Attempts: 3}}, nil)
+	assert.NoError(t, err)
+
+	ruleContext := run(a, nil)
+
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, 404, ruleContext.Get("status"))
+	assert.EqualError(t, ruleContext.Get("error").(error), "httpaction: GET "+server.URL+": status 404")
+}
+
+func TestAction_ExecuteE(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	a, err := New(Config{URL: server.URL}, nil)
+	assert.NoError(t, err)
+
+	err = rule.ChainRuleRunner(rule.NewRuleContext(), rule.NewChainRule().WithName("fetch").OnExecuteWithError(a.ExecuteE))
+	assert.ErrorContains(t, err, "httpaction: GET "+server.URL+": status 404")
+}
+
+func TestNew_InvalidConfig(t *testing.T) {
+	_, err := New(Config{}, nil)
+	assert.EqualError(t, err, "httpaction: missing url")
+
+	_, err = New(Config{URL: "http://host/{{.id"}, nil)
+	assert.ErrorContains(t, err, "httpaction: url:")
+}
+
+func TestConfig_Declarative(t *testing.T) {
+	var config Config
+	err := yaml.Unmarshal([]byte(`
+method: PUT
+url: http://host/{{.id}}
+timeout: 2s
+retry:
+  attempts: 2
+  statuses: [429]
+json_keys:
+  result: decision
+`), &config)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "PUT", config.Method)
+	assert.Equal(t, "2s", config.Timeout.String())
+	assert.Equal(t, []int{429}, config.Retry.Statuses)
+	assert.Equal(t, "decision", config.JSONKeys["result"])
+}
+
+func TestConfig_JSON(t *testing.T) {
+	var config Config
+	err := json.Unmarshal([]byte(`{"url": "http://host", "timeout": "2s", "retry": {"attempts": 3, "backoff": "150ms"}}`), &config)
+	assert.NoError(t, err)
+	assert.Equal(t, 2*time.Second, config.Timeout)
+	assert.Equal(t, 150*time.Millisecond, config.Retry.Backoff)
+	assert.Equal(t, 3, config.Retry.Attempts)
+
+	err = json.Unmarshal([]byte(`{"timeout": 1000000}`), &config)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Millisecond, config.Timeout)
+	assert.Equal(t, "http://host", config.URL)
+
+	assert.Error(t, json.Unmarshal([]byte(`{"timeout": "soon"}`), &config))
+}
+
+func TestAction_RetriesStopOnCancel(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	a, err := New(Config{URL: server.URL, ErrorKey: "error", Retry: RetryPolicy{Attempts: 5, Backoff: time.Hour}}, nil)
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	ruleContext := rule.NewRuleContext(rule.WithGoContext(ctx))
+	var start = time.Now()
+	rule.ChainRuleRunner(ruleContext, rule.NewChainRule().OnExecute(a.Execute))
+
+	assert.Less(t, time.Since(start), time.Second)
+	assert.Equal(t, 1, calls)
+	assert.ErrorIs(t, ruleContext.Get("error").(error), context.DeadlineExceeded)
+}
+
+func TestAction_EscapesURLValues(t *testing.T) {
+	var path, query string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, query = r.URL.EscapedPath(), r.URL.Query().Get("q")
+	}))
+	defer server.Close()
+
+	a, err := New(Config{URL: server.URL + "/users/{{pathescape .name}}?q={{urlquery .query}}"}, nil)
+	assert.NoError(t, err)
+
+	run(a, map[string]interface{}{"name": "../admin?x=1", "query": "a&b=c"})
+
+	assert.Equal(t, "/users/..%2Fadmin%3Fx=1", path)
+	assert.Equal(t, "a&b=c", query)
+}
+
+type traceKey struct{}
+
+func TestAction_GoContext(t *testing.T) {
+	var traceID interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	var client = server.Client()
+	var transport = client.Transport
+	client.Transport = roundTripper(func(r *http.Request) (*http.Response, error) {
+		traceID = r.Context().Value(traceKey{})
+		return transport.RoundTrip(r)
+	})
+	a, err := New(Config{URL: server.URL, StatusKey: "status", ErrorKey: "error"}, client)
+	assert.NoError(t, err)
+
+	var ctx = context.WithValue(context.Background(), traceKey{}, "trace-1")
+	ruleContext := rule.NewRuleContext(rule.WithGoContext(ctx))
+	rule.ChainRuleRunner(ruleContext, rule.NewChainRule().OnExecute(a.Execute))
+	assert.Equal(t, "trace-1", traceID)
+	assert.Equal(t, 200, ruleContext.Get("status"))
+
+	ctx, cancel := context.WithCancel(ctx)
+	cancel()
+	ruleContext = rule.NewRuleContext(rule.WithGoContext(ctx))
+	rule.ChainRuleRunner(ruleContext, rule.NewChainRule().OnExecute(a.Execute))
+	assert.ErrorIs(t, ruleContext.Get("error").(error), context.Canceled)
+}
+
+type roundTripper func(*http.Request) (*http.Response, error)
+
+func (f roundTripper) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
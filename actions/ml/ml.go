@@ -0,0 +1,80 @@
+// Package ml provides an execute hook calling a machine learning model, so a
+// rule tree can combine rules and model predictions.
+package ml
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/leoslamas/dredd-go/rule"
+)
+
+// Predictor is a pluggable model backend, such as an ONNX runtime session or
+// an HTTP model endpoint.
+type Predictor interface {
+	Predict(ctx context.Context, features map[string]interface{}) (interface{}, error)
+}
+
+// PredictorFunc adapts an ordinary function to the Predictor interface.
+type PredictorFunc func(ctx context.Context, features map[string]interface{}) (interface{}, error)
+
+// Predict calls f(ctx, features).
+func (f PredictorFunc) Predict(ctx context.Context, features map[string]interface{}) (interface{}, error) {
+	return f(ctx, features)
+}
+
+// Action calls a Predictor with selected context keys as features and writes
+// the prediction back into the context.
+type Action struct {
+	predictor Predictor
+	output    string
+	features  []string
+	errorKey  string
+}
+
+// NewAction creates an Action predicting from the given feature keys into the
+// output key. Feature keys missing from the context are not sent.
+func NewAction(predictor Predictor, output string, features ...string) *Action {
+	return &Action{predictor: predictor, output: output, features: features}
+}
+
+// WithErrorKey sets a context key receiving the prediction error, if any.
+// Without it, a failed prediction leaves the context untouched.
+func (a *Action) WithErrorKey(key string) *Action {
+	a.errorKey = key
+	return a
+}
+
+// Execute runs the prediction, passing the GoContext of the run to the
+// Predictor. It is meant to be passed to OnExecute.
+func (a *Action) Execute(ctx rule.Context) {
+	if err := a.predict(ctx.GetRuleContext()); err != nil && a.errorKey != "" {
+		ctx.GetRuleContext().Set(a.errorKey, err)
+	}
+}
+
+// ExecuteE runs the prediction as Execute does, but returns the prediction
+// error, so the run stops when the model fails. It is meant to be passed to
+// OnExecuteWithError.
+func (a *Action) ExecuteE(ctx rule.Context) error {
+	if err := a.predict(ctx.GetRuleContext()); err != nil {
+		return fmt.Errorf("ml: %w", err)
+	}
+	return nil
+}
+
+func (a *Action) predict(rc *rule.RuleContext) error {
+	var features = make(map[string]interface{}, len(a.features))
+	for _, key := range a.features {
+		if value := rc.Get(key); value != nil {
+			features[key] = value
+		}
+	}
+
+	prediction, err := a.predictor.Predict(rc.GoContext(), features)
+	if err != nil {
+		return err
+	}
+	rc.Set(a.output, prediction)
+	return nil
+}
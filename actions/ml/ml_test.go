@@ -0,0 +1,66 @@
+package ml
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/leoslamas/dredd-go/rule"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAction_WritesPrediction(t *testing.T) {
+	var received map[string]interface{}
+	var predictor = PredictorFunc(func(ctx context.Context, features map[string]interface{}) (interface{}, error) {
+		received = features
+		return 0.87, nil
+	})
+
+	r := rule.NewChainRule().OnExecute(NewAction(predictor, "fraud_score", "amount", "country", "missing").Execute)
+
+	ruleContext := rule.NewRuleContext()
+	ruleContext.Set("amount", 250.0)
+	ruleContext.Set("country", "BR")
+	ruleContext.Set("ignored", true)
+
+	rule.ChainRuleRunner(ruleContext, r)
+
+	assert.Equal(t, map[string]interface{}{"amount": 250.0, "country": "BR"}, received)
+	assert.Equal(t, 0.87, ruleContext.Get("fraud_score"))
+}
+
+func TestAction_Error(t *testing.T) {
+	var failure = errors.New("model unavailable")
+	var predictor = PredictorFunc(func(ctx context.Context, features map[string]interface{}) (interface{}, error) {
+		return nil, failure
+	})
+
+	r := rule.NewChainRule().OnExecute(NewAction(predictor, "fraud_score").WithErrorKey("fraud_error").Execute)
+
+	ruleContext := rule.NewRuleContext()
+	rule.ChainRuleRunner(ruleContext, r)
+
+	assert.Nil(t, ruleContext.Get("fraud_score"))
+	assert.Equal(t, failure, ruleContext.Get("fraud_error"))
+
+	r = rule.NewChainRule().WithName("score").OnExecuteWithError(NewAction(predictor, "fraud_score").ExecuteE)
+	var err = rule.ChainRuleRunner(rule.NewRuleContext(), r)
+	assert.EqualError(t, err, `rule "score" in execute: ml: model unavailable`)
+	assert.ErrorIs(t, err, failure)
+}
+
+type traceKey struct{}
+
+func TestAction_GoContext(t *testing.T) {
+	var traceID interface{}
+	var predictor = PredictorFunc(func(ctx context.Context, features map[string]interface{}) (interface{}, error) {
+		traceID = ctx.Value(traceKey{})
+		return 0.5, nil
+	})
+
+	var ctx = context.WithValue(context.Background(), traceKey{}, "trace-1")
+	ruleContext := rule.NewRuleContext(rule.WithGoContext(ctx))
+	rule.ChainRuleRunner(ruleContext, rule.NewChainRule().OnExecute(NewAction(predictor, "fraud_score").Execute))
+
+	assert.Equal(t, "trace-1", traceID)
+}
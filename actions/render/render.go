@@ -0,0 +1,65 @@
+// Package render provides an execute hook rendering a text/template with the
+// context values as data, to build emails, messages or payloads from rules.
+package render
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/leoslamas/dredd-go/rule"
+)
+
+// Action renders a template into a context key.
+type Action struct {
+	output   string
+	tmpl     *template.Template
+	errorKey string
+}
+
+// New parses the template, so syntax errors are reported when the rule is
+// built rather than when it runs. Referencing a key missing from the context
+// is a render error.
+func New(output string, text string, funcs template.FuncMap) (*Action, error) {
+	tmpl, err := template.New(output).Funcs(funcs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("render: %w", err)
+	}
+	return &Action{output: output, tmpl: tmpl}, nil
+}
+
+// Must is like New but panics if the template cannot be parsed.
+func Must(output string, text string, funcs template.FuncMap) *Action {
+	a, err := New(output, text, funcs)
+	if err != nil {
+		panic(err)
+	}
+	return a
+}
+
+// WithErrorKey sets a context key receiving the render error, if any.
+// Without it, a failed render leaves the context untouched.
+func (a *Action) WithErrorKey(key string) *Action {
+	a.errorKey = key
+	return a
+}
+
+// Execute renders the template. It is meant to be passed to OnExecute.
+func (a *Action) Execute(ctx rule.Context) {
+	if err := a.ExecuteE(ctx); err != nil && a.errorKey != "" {
+		ctx.GetRuleContext().Set(a.errorKey, err)
+	}
+}
+
+// ExecuteE renders the template as Execute does, but returns the render
+// error, so the run stops when it fails. It is meant to be passed to
+// OnExecuteWithError.
+func (a *Action) ExecuteE(ctx rule.Context) error {
+	var rc = ctx.GetRuleContext()
+	var b strings.Builder
+	if err := a.tmpl.Execute(&b, rc.Values()); err != nil {
+		return fmt.Errorf("render: %w", err)
+	}
+	rc.Set(a.output, b.String())
+	return nil
+}
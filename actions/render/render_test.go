@@ -0,0 +1,44 @@
+package render
+
+import (
+	"strings"
+	"testing"
+	"text/template"
+
+	"github.com/leoslamas/dredd-go/rule"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAction_Renders(t *testing.T) {
+	a := Must("message", `Hi {{.name | upper}}, your order {{index . "order.id"}} was approved.`,
+		template.FuncMap{"upper": strings.ToUpper})
+
+	ruleContext := rule.NewRuleContext()
+	ruleContext.Set("name", "ana")
+	ruleContext.Set("order.id", 1234)
+
+	rule.ChainRuleRunner(ruleContext, rule.NewChainRule().OnExecute(a.Execute))
+
+	assert.Equal(t, "Hi ANA, your order 1234 was approved.", ruleContext.Get("message"))
+}
+
+func TestNew_InvalidTemplate(t *testing.T) {
+	_, err := New("message", "{{.name", nil)
+	assert.ErrorContains(t, err, "render: template: message:1: unclosed action")
+
+	assert.Panics(t, func() { Must("message", "{{end}}", nil) })
+}
+
+func TestAction_MissingKey(t *testing.T) {
+	a := Must("message", "Hi {{.name}}", nil).WithErrorKey("error")
+
+	ruleContext := rule.NewRuleContext()
+	rule.ChainRuleRunner(ruleContext, rule.NewChainRule().OnExecute(a.Execute))
+
+	assert.Nil(t, ruleContext.Get("message"))
+	assert.ErrorContains(t, ruleContext.Get("error").(error), `map has no entry for key "name"`)
+
+	var err = rule.ChainRuleRunner(rule.NewRuleContext(), rule.NewChainRule().WithName("greet").OnExecuteWithError(a.ExecuteE))
+	assert.ErrorContains(t, err, `rule "greet" in execute: render: `)
+	assert.ErrorContains(t, err, `map has no entry for key "name"`)
+}
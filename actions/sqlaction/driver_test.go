@@ -0,0 +1,87 @@
+package sqlaction
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// fakeDriver is a minimal database/sql driver answering queries from a fixed
+// table of results and recording executed statements.
+type fakeDriver struct {
+	mu      sync.Mutex
+	results map[string][][]driver.Value
+	execs   []string
+}
+
+func openFake(results map[string][][]driver.Value) (*sql.DB, *fakeDriver) {
+	var d = &fakeDriver{results: results}
+	var name = fmt.Sprintf("fake-%p", d)
+	sql.Register(name, d)
+	db, _ := sql.Open(name, "")
+	return db, d
+}
+
+func (d *fakeDriver) Open(string) (driver.Conn, error) { return &fakeConn{d}, nil }
+
+type fakeConn struct{ d *fakeDriver }
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) { return &fakeStmt{c.d, query}, nil }
+func (c *fakeConn) Close() error                              { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error)                 { return nil, errors.New("not supported") }
+
+type fakeStmt struct {
+	d     *fakeDriver
+	query string
+}
+
+func (s *fakeStmt) Close() error  { return nil }
+func (s *fakeStmt) NumInput() int { return -1 }
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	if s.query == "fail" {
+		return nil, errors.New("exec failed")
+	}
+	s.d.mu.Lock()
+	defer s.d.mu.Unlock()
+	s.d.execs = append(s.d.execs, fmt.Sprint(s.query, args))
+	return driver.RowsAffected(1), nil
+}
+
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	var key = fmt.Sprint(s.query, args)
+	rows, ok := s.d.results[key]
+	if !ok {
+		return nil, fmt.Errorf("unexpected query %s", key)
+	}
+	return &fakeRows{rows: rows}, nil
+}
+
+type fakeRows struct {
+	rows [][]driver.Value
+}
+
+func (r *fakeRows) Close() error { return nil }
+
+// Columns names one column per value of the first row, or a single one.
+func (r *fakeRows) Columns() []string {
+	var columns = []string{"value"}
+	if len(r.rows) > 0 {
+		for i := 1; i < len(r.rows[0]); i++ {
+			columns = append(columns, fmt.Sprintf("value%d", i))
+		}
+	}
+	return columns
+}
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
@@ -0,0 +1,141 @@
+// Package sqlaction provides adapters running parameterized SQL queries from
+// rules: predicates for OnEval and an execute hook for inserts and updates.
+//
+// Query parameters are read from context keys and passed as query arguments,
+// never interpolated into the query text. Queries run with the GoContext of
+// the run, so they are canceled with it and carry its tracing values.
+package sqlaction
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/leoslamas/dredd-go/rule"
+)
+
+// DB is the subset of *sql.DB used by the adapters. *sql.Tx and *sql.Conn
+// satisfy it as well, so the caller decides how connections are managed.
+type DB interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// Exists returns an OnEval predicate reporting whether the query returns at
+// least one row, whatever its columns. params are the context keys passed as
+// query arguments.
+// Query errors evaluate to false; use ExistsE to stop the run instead.
+func Exists(db DB, query string, params ...string) func(rule.Context) bool {
+	return lenient(ExistsE(db, query, params...))
+}
+
+// ExistsE is like Exists but returns query errors, for OnEvalWithError, so a
+// failing database stops the run instead of evaluating to false.
+func ExistsE(db DB, query string, params ...string) func(rule.Context) (bool, error) {
+	return func(ctx rule.Context) (bool, error) {
+		var rc = ctx.GetRuleContext()
+		rows, err := db.QueryContext(rc.GoContext(), query, args(rc, params)...)
+		if err != nil {
+			return false, fmt.Errorf("sqlaction: %w", err)
+		}
+		defer rows.Close()
+
+		var found = rows.Next()
+		if err := rows.Err(); err != nil {
+			return false, fmt.Errorf("sqlaction: %w", err)
+		}
+		return found, nil
+	}
+}
+
+// Scalar returns an OnEval predicate running a query returning a single value
+// and reporting whether cmp accepts it. A query returning no row or failing
+// evaluates to false; use ScalarE to stop the run on failures instead.
+func Scalar(db DB, query string, cmp func(value interface{}) bool, params ...string) func(rule.Context) bool {
+	return lenient(ScalarE(db, query, cmp, params...))
+}
+
+// ScalarE is like Scalar but returns query errors, for OnEvalWithError. A
+// query returning no row still evaluates to false.
+func ScalarE(db DB, query string, cmp func(value interface{}) bool, params ...string) func(rule.Context) (bool, error) {
+	return func(ctx rule.Context) (bool, error) {
+		var value interface{}
+		var rc = ctx.GetRuleContext()
+		var err = db.QueryRowContext(rc.GoContext(), query, args(rc, params)...).Scan(&value)
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		if err != nil {
+			return false, fmt.Errorf("sqlaction: %w", err)
+		}
+		return cmp(value), nil
+	}
+}
+
+func lenient(f func(rule.Context) (bool, error)) func(rule.Context) bool {
+	return func(ctx rule.Context) bool {
+		var matched, err = f(ctx)
+		return err == nil && matched
+	}
+}
+
+// ExecAction runs a statement such as an insert or an update.
+type ExecAction struct {
+	db       DB
+	query    string
+	params   []string
+	rowsKey  string
+	errorKey string
+}
+
+// Exec creates an ExecAction running query with the values of the params
+// context keys as arguments.
+func Exec(db DB, query string, params ...string) *ExecAction {
+	return &ExecAction{db: db, query: query, params: params}
+}
+
+// WithRowsKey sets a context key receiving the number of affected rows.
+func (a *ExecAction) WithRowsKey(key string) *ExecAction {
+	a.rowsKey = key
+	return a
+}
+
+// WithErrorKey sets a context key receiving the statement error, if any.
+func (a *ExecAction) WithErrorKey(key string) *ExecAction {
+	a.errorKey = key
+	return a
+}
+
+// Execute runs the statement. It is meant to be passed to OnExecute.
+func (a *ExecAction) Execute(ctx rule.Context) {
+	if err := a.ExecuteE(ctx); err != nil && a.errorKey != "" {
+		ctx.GetRuleContext().Set(a.errorKey, err)
+	}
+}
+
+// ExecuteE runs the statement and returns its error, so the run stops when it
+// fails. It is meant to be passed to OnExecuteWithError.
+func (a *ExecAction) ExecuteE(ctx rule.Context) error {
+	var rc = ctx.GetRuleContext()
+	result, err := a.db.ExecContext(rc.GoContext(), a.query, args(rc, a.params)...)
+	if err == nil && a.rowsKey != "" {
+		var rows int64
+		if rows, err = result.RowsAffected(); err == nil {
+			rc.Set(a.rowsKey, rows)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("sqlaction: %w", err)
+	}
+	return nil
+}
+
+func args(rc *rule.RuleContext, params []string) []interface{} {
+	var values = make([]interface{}, len(params))
+	for i, key := range params {
+		values[i] = rc.Get(key)
+	}
+	return values
+}
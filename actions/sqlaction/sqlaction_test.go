@@ -0,0 +1,144 @@
+package sqlaction
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+
+	"github.com/leoslamas/dredd-go/rule"
+	"github.com/stretchr/testify/assert"
+)
+
+func contextWith(values map[string]interface{}) rule.Context {
+	r := rule.NewChainRule()
+	for k, v := range values {
+		r.GetRuleContext().Set(k, v)
+	}
+	return r
+}
+
+func TestExists(t *testing.T) {
+	db, _ := openFake(map[string][][]driver.Value{
+		"SELECT 1 FROM blocked WHERE user = ?[42]": {{int64(1)}},
+		"SELECT 1 FROM blocked WHERE user = ?[7]":  {},
+	})
+	defer db.Close()
+
+	var blocked = Exists(db, "SELECT 1 FROM blocked WHERE user = ?", "user_id")
+
+	assert.True(t, blocked(contextWith(map[string]interface{}{"user_id": 42})))
+	assert.False(t, blocked(contextWith(map[string]interface{}{"user_id": 7})))
+	assert.False(t, blocked(contextWith(map[string]interface{}{"user_id": "unknown"})))
+
+	db, _ = openFake(map[string][][]driver.Value{
+		"SELECT * FROM blocked WHERE user = ?[42]": {{int64(42), "fraud"}, {int64(42), "chargeback"}},
+	})
+	defer db.Close()
+	assert.True(t, Exists(db, "SELECT * FROM blocked WHERE user = ?", "user_id")(contextWith(map[string]interface{}{"user_id": 42})))
+}
+
+func TestScalar(t *testing.T) {
+	db, _ := openFake(map[string][][]driver.Value{
+		"SELECT count(*) FROM orders WHERE user = ?[42]": {{int64(12)}},
+	})
+	defer db.Close()
+
+	var frequent = Scalar(db, "SELECT count(*) FROM orders WHERE user = ?", func(value interface{}) bool {
+		return value.(int64) > 10
+	}, "user_id")
+
+	assert.True(t, frequent(contextWith(map[string]interface{}{"user_id": 42})))
+	assert.False(t, frequent(contextWith(map[string]interface{}{"user_id": 1})))
+}
+
+func TestExistsE(t *testing.T) {
+	db, _ := openFake(map[string][][]driver.Value{
+		"SELECT 1 FROM blocked WHERE user = ?[42]": {{int64(1)}},
+		"SELECT 1 FROM blocked WHERE user = ?[7]":  {},
+	})
+	defer db.Close()
+
+	var blocked = rule.NewBestFirstRule().WithName("blocked").
+		OnEvalWithError(ExistsE(db, "SELECT 1 FROM blocked WHERE user = ?", "user_id"))
+
+	for _, id := range []int{42, 7} {
+		ruleContext := rule.NewRuleContext()
+		ruleContext.Set("user_id", id)
+		assert.NoError(t, rule.BestFirstRuleRunner(ruleContext, blocked))
+	}
+
+	ruleContext := rule.NewRuleContext()
+	ruleContext.Set("user_id", "unknown")
+	assert.EqualError(t, rule.BestFirstRuleRunner(ruleContext, blocked),
+		`rule "blocked" in eval: sqlaction: unexpected query SELECT 1 FROM blocked WHERE user = ?[unknown]`)
+}
+
+func TestScalarE(t *testing.T) {
+	db, _ := openFake(map[string][][]driver.Value{
+		"SELECT count(*) FROM orders WHERE user = ?[42]": {{int64(12)}},
+		"SELECT count(*) FROM orders WHERE user = ?[7]":  {},
+	})
+	defer db.Close()
+
+	var frequent = ScalarE(db, "SELECT count(*) FROM orders WHERE user = ?", func(value interface{}) bool {
+		return value.(int64) > 10
+	}, "user_id")
+
+	matched, err := frequent(contextWith(map[string]interface{}{"user_id": 42}))
+	assert.True(t, matched)
+	assert.NoError(t, err)
+	matched, err = frequent(contextWith(map[string]interface{}{"user_id": 7}))
+	assert.False(t, matched)
+	assert.NoError(t, err)
+	_, err = frequent(contextWith(map[string]interface{}{"user_id": 1}))
+	assert.Error(t, err)
+}
+
+func TestExec(t *testing.T) {
+	db, fake := openFake(nil)
+	defer db.Close()
+
+	a := Exec(db, "INSERT INTO decisions VALUES (?, ?)", "user_id", "decision").WithRowsKey("rows")
+
+	ruleContext := rule.NewRuleContext()
+	ruleContext.Set("user_id", 42)
+	ruleContext.Set("decision", "approve")
+	rule.ChainRuleRunner(ruleContext, rule.NewChainRule().OnExecute(a.Execute))
+
+	assert.Equal(t, []string{"INSERT INTO decisions VALUES (?, ?)[42 approve]"}, fake.execs)
+	assert.Equal(t, int64(1), ruleContext.Get("rows"))
+}
+
+func TestExec_Error(t *testing.T) {
+	db, _ := openFake(nil)
+	defer db.Close()
+
+	a := Exec(db, "fail").WithErrorKey("error")
+
+	ruleContext := rule.NewRuleContext()
+	rule.ChainRuleRunner(ruleContext, rule.NewChainRule().OnExecute(a.Execute))
+
+	assert.EqualError(t, ruleContext.Get("error").(error), "sqlaction: exec failed")
+
+	var err = rule.ChainRuleRunner(rule.NewRuleContext(), rule.NewChainRule().WithName("save").OnExecuteWithError(a.ExecuteE))
+	assert.EqualError(t, err, `rule "save" in execute: sqlaction: exec failed`)
+}
+
+func TestGoContext(t *testing.T) {
+	db, fake := openFake(map[string][][]driver.Value{
+		"SELECT 1 FROM blocked WHERE user = ?[42]": {{int64(1)}},
+	})
+	defer db.Close()
+
+	var ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	r := rule.NewChainRule()
+	r.SetRuleContext(rule.NewRuleContext(rule.WithGoContext(ctx)))
+	r.GetRuleContext().Set("user_id", 42)
+
+	assert.False(t, Exists(db, "SELECT 1 FROM blocked WHERE user = ?", "user_id")(r))
+
+	Exec(db, "DELETE FROM blocked WHERE user = ?", "user_id").WithErrorKey("error").Execute(r)
+	assert.ErrorIs(t, r.GetRuleContext().Get("error").(error), context.Canceled)
+	assert.Empty(t, fake.execs)
+}
@@ -0,0 +1,153 @@
+// Package backtest runs a candidate rule set over a historical dataset and
+// compares its outcomes with the recorded ones or with a baseline rule set.
+package backtest
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/leoslamas/dredd-go/batch"
+	"github.com/leoslamas/dredd-go/rule"
+)
+
+// NoOutcome is the outcome of a run that did not set the outcome key.
+const NoOutcome = "<none>"
+
+// Failed is the outcome of a run that returned an error, whatever it set.
+const Failed = "<failed>"
+
+// Config describes a backtest.
+type Config struct {
+	// Data reads the dataset records into contexts.
+	Data batch.CSV
+	// Outcome is the context key holding the decision of a run.
+	Outcome string
+	// Actual is the dataset column holding the recorded outcome, if any. It
+	// is removed from the contexts the rule sets run with, and must differ
+	// from Outcome.
+	Actual string
+	// Baseline is the rule set the candidate is compared with, if any. It runs
+	// with a context created by Data, holding the same record.
+	Baseline rule.Program
+}
+
+// Report aggregates the outcomes of a backtest.
+type Report struct {
+	Records int
+	// Candidate, Baseline and Actual are outcome distributions.
+	Candidate map[string]int
+	Baseline  map[string]int
+	Actual    map[string]int
+	// MatchesActual and MatchesBaseline count the records where the candidate
+	// outcome equals the recorded and the baseline outcome.
+	MatchesActual   int
+	MatchesBaseline int
+	// Confusion counts candidate outcomes per recorded outcome:
+	// Confusion[actual][candidate].
+	Confusion map[string]map[string]int
+}
+
+// Run runs the candidate rule set over the records read from r. Failed runs
+// of the candidate or the baseline are counted with the Failed outcome.
+func Run(r io.Reader, config Config, candidate rule.Program) (*Report, error) {
+	if config.Actual != "" && config.Actual == config.Outcome {
+		return nil, fmt.Errorf("backtest: outcome key and actual column are both %q", config.Actual)
+	}
+
+	var report = &Report{
+		Candidate: make(map[string]int),
+		Baseline:  make(map[string]int),
+		Actual:    make(map[string]int),
+		Confusion: make(map[string]map[string]int),
+	}
+
+	count, err := config.Data.Run(r, nil, func(rc *rule.RuleContext) error {
+		var recorded interface{}
+		if config.Actual != "" {
+			recorded = rc.Get(config.Actual)
+			rc.Delete(config.Actual)
+		}
+		var input = rc.Values()
+
+		var outcome = outcomeOf(rc, candidate(rc), config.Outcome)
+		report.Candidate[outcome]++
+
+		if config.Actual != "" {
+			var actual = valueOf(recorded)
+			report.Actual[actual]++
+			if actual == outcome {
+				report.MatchesActual++
+			}
+			if report.Confusion[actual] == nil {
+				report.Confusion[actual] = make(map[string]int)
+			}
+			report.Confusion[actual][outcome]++
+		}
+
+		if config.Baseline != nil {
+			var baseline = config.Data.Context()
+			for k, v := range input {
+				baseline.Set(k, v)
+			}
+
+			var expected = outcomeOf(baseline, config.Baseline(baseline), config.Outcome)
+			report.Baseline[expected]++
+			if expected == outcome {
+				report.MatchesBaseline++
+			}
+		}
+		return nil
+	})
+	report.Records = count
+	if err != nil {
+		return report, fmt.Errorf("backtest: %w", err)
+	}
+	return report, nil
+}
+
+// outcomeOf returns the outcome of a run that returned err.
+func outcomeOf(rc *rule.RuleContext, err error, key string) string {
+	if err != nil {
+		return Failed
+	}
+	return valueOf(rc.Get(key))
+}
+
+func valueOf(value interface{}) string {
+	if value == nil {
+		return NoOutcome
+	}
+	return fmt.Sprint(value)
+}
+
+// String renders the report as a plain text summary.
+func (r *Report) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "records: %d\n", r.Records)
+	writeDistribution(&b, "candidate", r.Candidate)
+	if len(r.Actual) > 0 {
+		writeDistribution(&b, "actual", r.Actual)
+		fmt.Fprintf(&b, "matches actual: %d/%d\n", r.MatchesActual, r.Records)
+	}
+	if len(r.Baseline) > 0 {
+		writeDistribution(&b, "baseline", r.Baseline)
+		fmt.Fprintf(&b, "matches baseline: %d/%d\n", r.MatchesBaseline, r.Records)
+	}
+	return b.String()
+}
+
+func writeDistribution(b *strings.Builder, name string, distribution map[string]int) {
+	var outcomes = make([]string, 0, len(distribution))
+	for outcome := range distribution {
+		outcomes = append(outcomes, outcome)
+	}
+	sort.Strings(outcomes)
+
+	fmt.Fprintf(b, "%s:", name)
+	for _, outcome := range outcomes {
+		fmt.Fprintf(b, " %s=%d", outcome, distribution[outcome])
+	}
+	b.WriteString("\n")
+}
@@ -0,0 +1,133 @@
+package backtest
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/leoslamas/dredd-go/batch"
+	"github.com/leoslamas/dredd-go/rule"
+	"github.com/stretchr/testify/assert"
+)
+
+const dataset = `id,amount,actual
+1,50,approve
+2,150,review
+3,250,review
+4,90,review
+`
+
+func threshold(limit float64) rule.Program {
+	var large = rule.NewBestFirstRule().
+		OnEval(func(ctx rule.Context) bool { return ctx.GetRuleContext().Get("amount").(float64) > limit }).
+		OnExecute(func(ctx rule.Context) { ctx.GetRuleContext().Set("decision", "review") })
+	var fallback = rule.NewBestFirstRule().
+		OnExecute(func(ctx rule.Context) { ctx.GetRuleContext().Set("decision", "approve") })
+
+	return func(rc *rule.RuleContext) error {
+		return rule.BestFirstRuleRunner(rc, large, fallback)
+	}
+}
+
+var data = batch.CSV{Parse: map[string]batch.ParseFunc{"amount": func(field string) (interface{}, error) {
+	return strconv.ParseFloat(field, 64)
+}}}
+
+func TestRun(t *testing.T) {
+	report, err := Run(strings.NewReader(dataset), Config{
+		Data:     data,
+		Outcome:  "decision",
+		Actual:   "actual",
+		Baseline: threshold(200),
+	}, threshold(80))
+
+	assert.NoError(t, err)
+	assert.Equal(t, 4, report.Records)
+	assert.Equal(t, map[string]int{"approve": 1, "review": 3}, report.Candidate)
+	assert.Equal(t, map[string]int{"approve": 3, "review": 1}, report.Baseline)
+	assert.Equal(t, 4, report.MatchesActual)
+	assert.Equal(t, 2, report.MatchesBaseline)
+	assert.Equal(t, map[string]int{"review": 3}, report.Confusion["review"])
+
+	assert.Equal(t, `records: 4
+candidate: approve=1 review=3
+actual: approve=1 review=3
+matches actual: 4/4
+baseline: approve=3 review=1
+matches baseline: 2/4
+`, report.String())
+}
+
+func TestRun_NoOutcome(t *testing.T) {
+	report, err := Run(strings.NewReader(dataset), Config{Data: data, Outcome: "decision"},
+		func(*rule.RuleContext) error { return nil })
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int{NoOutcome: 4}, report.Candidate)
+	assert.Empty(t, report.Actual)
+}
+
+func TestRun_FailedRuns(t *testing.T) {
+	var failing = func(rc *rule.RuleContext) error {
+		if rc.Get("amount").(float64) > 100 {
+			rc.Set("decision", "approve")
+			return errors.New("model down")
+		}
+		return threshold(80)(rc)
+	}
+	report, err := Run(strings.NewReader(dataset), Config{
+		Data:     data,
+		Outcome:  "decision",
+		Actual:   "actual",
+		Baseline: failing,
+	}, failing)
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int{"approve": 1, "review": 1, Failed: 2}, report.Candidate)
+	assert.Equal(t, map[string]int{"approve": 1, "review": 1, Failed: 2}, report.Baseline)
+	assert.Equal(t, map[string]int{"review": 1, Failed: 2}, report.Confusion["review"])
+	assert.Equal(t, 2, report.MatchesActual)
+}
+
+func TestRun_BaselineContext(t *testing.T) {
+	var config = Config{Data: data, Outcome: "decision", Baseline: func(rc *rule.RuleContext) error {
+		rc.Set("decision", rc.Get("region"))
+		return nil
+	}}
+	config.Data.NewContext = func() *rule.RuleContext {
+		return rule.NewRuleContext(rule.WithDefaults(map[string]interface{}{"region": "eu"}))
+	}
+
+	report, err := Run(strings.NewReader(dataset), config, func(rc *rule.RuleContext) error {
+		rc.Set("decision", rc.Get("region"))
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int{"eu": 4}, report.Baseline)
+	assert.Equal(t, 4, report.MatchesBaseline)
+}
+
+func TestRun_ActualHidden(t *testing.T) {
+	var seen []interface{}
+	var peek = func(rc *rule.RuleContext) error {
+		seen = append(seen, rc.Get("actual"))
+		return nil
+	}
+
+	report, err := Run(strings.NewReader(dataset), Config{Data: data, Outcome: "decision", Actual: "actual", Baseline: peek}, peek)
+
+	assert.NoError(t, err)
+	assert.Equal(t, make([]interface{}, 8), seen)
+	assert.Equal(t, map[string]int{"approve": 1, "review": 3}, report.Actual)
+}
+
+func TestRun_Error(t *testing.T) {
+	_, err := Run(strings.NewReader(""), Config{Data: data}, threshold(80))
+
+	assert.EqualError(t, err, "backtest: batch: read header: EOF")
+
+	_, err = Run(strings.NewReader(dataset), Config{Data: data, Outcome: "actual", Actual: "actual"}, threshold(80))
+	assert.EqualError(t, err, `backtest: outcome key and actual column are both "actual"`)
+}
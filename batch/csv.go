@@ -0,0 +1,114 @@
+// Package batch runs rule sets over record files, one RuleContext per
+// record, for offline scoring and backtesting.
+package batch
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/leoslamas/dredd-go/rule"
+)
+
+// ParseFunc converts a CSV field into a context value.
+type ParseFunc func(field string) (interface{}, error)
+
+// CSV streams the records of a CSV file with a header row into rule contexts.
+// Each column becomes a context key holding the field, as a string unless a
+// ParseFunc is registered for the column.
+type CSV struct {
+	// Parse converts fields of the given columns.
+	Parse map[string]ParseFunc
+	// Outputs are the context keys appended as columns to the output file.
+	Outputs []string
+	// NewContext creates the context of each record. It defaults to
+	// rule.NewRuleContext.
+	NewContext func() *rule.RuleContext
+}
+
+// Run reads the records from r, runs the program with the context of each
+// one, and writes the records annotated with the Outputs columns to w, which
+// may be nil. It stops at the first failed run, and returns the number of
+// records processed before it.
+func (c CSV) Run(r io.Reader, w io.Writer, run rule.Program) (int, error) {
+	var reader = csv.NewReader(r)
+	header, err := reader.Read()
+	if err != nil {
+		return 0, fmt.Errorf("batch: read header: %w", err)
+	}
+
+	var writer *csv.Writer
+	if w != nil {
+		writer = csv.NewWriter(w)
+		if err := writer.Write(append(append([]string{}, header...), c.Outputs...)); err != nil {
+			return 0, fmt.Errorf("batch: write header: %w", err)
+		}
+	}
+
+	var count int
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return count, fmt.Errorf("batch: read record %d: %w", count+1, err)
+		}
+
+		rc, err := c.context(header, record)
+		if err != nil {
+			return count, fmt.Errorf("batch: record %d: %w", count+1, err)
+		}
+		if err := run(rc); err != nil {
+			return count, fmt.Errorf("batch: record %d: %w", count+1, err)
+		}
+		count++
+
+		if writer != nil {
+			var row = append([]string{}, record...)
+			for _, key := range c.Outputs {
+				var value = rc.Get(key)
+				if value == nil {
+					row = append(row, "")
+				} else {
+					row = append(row, fmt.Sprint(value))
+				}
+			}
+			if err := writer.Write(row); err != nil {
+				return count, fmt.Errorf("batch: write record %d: %w", count, err)
+			}
+		}
+	}
+
+	if writer != nil {
+		writer.Flush()
+		if err := writer.Error(); err != nil {
+			return count, fmt.Errorf("batch: flush: %w", err)
+		}
+	}
+	return count, nil
+}
+
+// Context returns a new context for a record, as created by NewContext.
+func (c CSV) Context() *rule.RuleContext {
+	if c.NewContext != nil {
+		return c.NewContext()
+	}
+	return rule.NewRuleContext()
+}
+
+func (c CSV) context(header []string, record []string) (*rule.RuleContext, error) {
+	var rc = c.Context()
+	for i, column := range header {
+		var value interface{} = record[i]
+		if parse, ok := c.Parse[column]; ok {
+			var err error
+			if value, err = parse(record[i]); err != nil {
+				return nil, fmt.Errorf("column %s: %w", column, err)
+			}
+		}
+		rc.Set(column, value)
+	}
+	return rc, nil
+}
@@ -0,0 +1,76 @@
+package batch
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/leoslamas/dredd-go/rule"
+	"github.com/stretchr/testify/assert"
+)
+
+func parseFloat(field string) (interface{}, error) {
+	return strconv.ParseFloat(field, 64)
+}
+
+func scoring() rule.Program {
+	var large = rule.NewBestFirstRule().
+		OnEval(func(ctx rule.Context) bool { return ctx.GetRuleContext().Get("amount").(float64) > 100 }).
+		OnExecute(func(ctx rule.Context) { ctx.GetRuleContext().Set("decision", "review") })
+	var fallback = rule.NewBestFirstRule().
+		OnExecute(func(ctx rule.Context) { ctx.GetRuleContext().Set("decision", "approve") })
+
+	return func(rc *rule.RuleContext) error {
+		return rule.BestFirstRuleRunner(rc, large, fallback)
+	}
+}
+
+func TestCSV_Run(t *testing.T) {
+	var in = strings.NewReader("id,amount\n1,50\n2,250\n")
+	var out strings.Builder
+
+	count, err := CSV{
+		Parse:   map[string]ParseFunc{"amount": parseFloat},
+		Outputs: []string{"decision", "missing"},
+	}.Run(in, &out, scoring())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+	assert.Equal(t, "id,amount,decision,missing\n1,50,approve,\n2,250,review,\n", out.String())
+}
+
+func TestCSV_Errors(t *testing.T) {
+	_, err := CSV{}.Run(strings.NewReader(""), nil, scoring())
+	assert.EqualError(t, err, "batch: read header: EOF")
+
+	count, err := CSV{Parse: map[string]ParseFunc{"amount": parseFloat}}.
+		Run(strings.NewReader("id,amount\n1,50\n2,abc\n"), nil, scoring())
+	assert.Equal(t, 1, count)
+	assert.EqualError(t, err, `batch: record 2: column amount: strconv.ParseFloat: parsing "abc": invalid syntax`)
+
+	_, err = CSV{}.Run(strings.NewReader("id,amount\n1\n"), nil, func(*rule.RuleContext) error { return nil })
+	assert.ErrorContains(t, err, "batch: read record 1:")
+
+	count, err = CSV{}.Run(strings.NewReader("id\n1\n2\n"), nil, func(rc *rule.RuleContext) error {
+		if rc.Get("id") == "2" {
+			return errors.New("model down")
+		}
+		return nil
+	})
+	assert.Equal(t, 1, count)
+	assert.EqualError(t, err, "batch: record 2: model down")
+}
+
+func TestCSV_NewContext(t *testing.T) {
+	var contexts []*rule.RuleContext
+	_, err := CSV{NewContext: func() *rule.RuleContext {
+		return rule.NewRuleContext(rule.WithHistory())
+	}}.Run(strings.NewReader("id\n1\n"), nil, func(rc *rule.RuleContext) error {
+		contexts = append(contexts, rc)
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(contexts[0].History()))
+}
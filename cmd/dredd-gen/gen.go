@@ -0,0 +1,361 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"math"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/leoslamas/dredd-go/internal/ident"
+	"gopkg.in/yaml.v3"
+)
+
+// Spec describes a rule set to generate code for.
+// It is read from YAML, and since JSON is a subset of YAML, from JSON as well.
+type Spec struct {
+	Package string     `yaml:"package"`
+	Type    string     `yaml:"type"`
+	Keys    []KeySpec  `yaml:"keys"`
+	Rules   []RuleSpec `yaml:"rules"`
+
+	// Examples are input contexts with their expected outcome.
+	Examples []ExampleSpec `yaml:"examples"`
+
+	// All lists every rule of the tree, parents before their children.
+	All []RuleSpec `yaml:"-"`
+}
+
+// KeySpec describes a context key and the Go type of its value.
+type KeySpec struct {
+	Name        string `yaml:"name"`
+	Ident       string `yaml:"ident"`
+	Type        string `yaml:"type"`
+	Description string `yaml:"description"`
+}
+
+// ExampleSpec describes an input context and the outcome the rule set is
+// expected to produce for it.
+type ExampleSpec struct {
+	Name     string                 `yaml:"name"`
+	Input    map[string]interface{} `yaml:"input"`
+	Expected map[string]interface{} `yaml:"expected"`
+	Decision interface{}            `yaml:"decision"`
+}
+
+// RuleSpec describes a named rule and its children.
+type RuleSpec struct {
+	Name     string     `yaml:"name"`
+	Ident    string     `yaml:"ident"`
+	Children []RuleSpec `yaml:"children"`
+}
+
+// ParseSpec parses a YAML or JSON rule set description.
+func ParseSpec(data []byte) (*Spec, error) {
+	var spec Spec
+	if err := yaml.Unmarshal(data, &spec); err != nil {
+		return nil, fmt.Errorf("parse spec: %w", err)
+	}
+
+	if spec.Package == "" {
+		return nil, fmt.Errorf("parse spec: missing package")
+	}
+	if !token.IsIdentifier(spec.Package) || spec.Package == "_" {
+		return nil, fmt.Errorf("parse spec: invalid package name %q", spec.Package)
+	}
+
+	switch spec.Type {
+	case "", "chain":
+		spec.Type = "ChainRule"
+	case "best-first":
+		spec.Type = "BestFirstRule"
+	case "all-match":
+		spec.Type = "AllMatchRule"
+	case "fallback":
+		spec.Type = "FallbackRule"
+	default:
+		return nil, fmt.Errorf("parse spec: unknown rule type %q", spec.Type)
+	}
+
+	var keys = make(map[string]string, len(spec.Keys))
+	for i := range spec.Keys {
+		var k = &spec.Keys[i]
+		if k.Name == "" {
+			return nil, fmt.Errorf("parse spec: key %d has no name", i)
+		}
+		if k.Ident == "" {
+			k.Ident = identifier(k.Name)
+		}
+		if err := checkIdent("key", k.Name, k.Ident); err != nil {
+			return nil, err
+		}
+		if other, ok := keys[k.Ident]; ok {
+			return nil, fmt.Errorf("parse spec: keys %q and %q have the same identifier %q", other, k.Name, k.Ident)
+		}
+		keys[k.Ident] = k.Name
+		if k.Type == "" {
+			k.Type = "interface{}"
+		}
+		if err := checkType(k.Type); err != nil {
+			return nil, fmt.Errorf("parse spec: key %q: %w", k.Name, err)
+		}
+	}
+
+	if err := resolveRules(spec.Rules); err != nil {
+		return nil, err
+	}
+	spec.All = flatten(spec.Rules)
+
+	var seen = make(map[string]bool, len(spec.All))
+	for _, r := range spec.All {
+		if seen[r.Ident] {
+			return nil, fmt.Errorf("parse spec: duplicate rule %q", r.Ident)
+		}
+		seen[r.Ident] = true
+	}
+
+	for _, e := range spec.Examples {
+		for _, v := range []interface{}{e.Input, e.Expected, e.Decision} {
+			if err := checkLiteral(v); err != nil {
+				return nil, fmt.Errorf("parse spec: example %q: %w", e.Name, err)
+			}
+		}
+	}
+
+	if spec.Type == "ChainRule" && len(spec.Rules) > 1 {
+		return nil, fmt.Errorf("parse spec: chain rule set can only have one root rule")
+	}
+
+	return &spec, nil
+}
+
+func resolveRules(rules []RuleSpec) error {
+	for i := range rules {
+		var r = &rules[i]
+		if r.Name == "" {
+			return fmt.Errorf("parse spec: rule has no name")
+		}
+		if r.Ident == "" {
+			r.Ident = identifier(r.Name)
+		}
+		if err := checkIdent("rule", r.Name, r.Ident); err != nil {
+			return err
+		}
+		if err := resolveRules(r.Children); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func flatten(rules []RuleSpec) []RuleSpec {
+	var all []RuleSpec
+	for _, r := range rules {
+		all = append(all, r)
+		all = append(all, flatten(r.Children)...)
+	}
+	return all
+}
+
+// identifier turns a key or rule name such as "order.total" into an exported
+// Go identifier such as "OrderTotal".
+func identifier(name string) string {
+	var id = ident.Exported(name)
+	if id == "" || unicode.IsDigit([]rune(id)[0]) {
+		id = "X" + id
+	}
+	return id
+}
+
+// methods are the methods of the generated Values and Rules types, which
+// their fields can't be named after.
+var methods = map[string]bool{"Load": true, "Store": true, "Run": true, "Examples": true, "RunExamples": true}
+
+// checkIdent returns an error unless id can name the field of a key or rule.
+func checkIdent(kind, name, id string) error {
+	if !token.IsIdentifier(id) {
+		return fmt.Errorf("parse spec: %s %q: invalid identifier %q", kind, name, id)
+	}
+	if methods[id] {
+		return fmt.Errorf("parse spec: %s %q: identifier %q is reserved, set another ident", kind, name, id)
+	}
+	return nil
+}
+
+// checkType returns an error unless t is a Go type expression the generated
+// file can use without imports, as a field type and in a type assertion.
+func checkType(t string) error {
+	expr, err := parser.ParseExpr(t)
+	if err == nil {
+		var src = "package p\nvar _ = x.(" + t + ")\ntype _ struct {\n\tX " + t + "\n}\n"
+		_, err = parser.ParseFile(token.NewFileSet(), "", src, 0)
+	}
+	if err != nil {
+		return fmt.Errorf("invalid type %q", t)
+	}
+	var qualified bool
+	ast.Inspect(expr, func(n ast.Node) bool {
+		if _, ok := n.(*ast.SelectorExpr); ok {
+			qualified = true
+		}
+		return !qualified
+	})
+	if qualified {
+		return fmt.Errorf("type %q needs an import, types of other packages are not supported", t)
+	}
+	return nil
+}
+
+// checkLiteral returns an error unless the value only holds JSON values, which
+// literal can format. YAML timestamps, for one, are decoded as time.Time.
+func checkLiteral(v interface{}) error {
+	switch v := v.(type) {
+	case nil, bool, int, string:
+		return nil
+	case float64:
+		if math.IsInf(v, 0) || math.IsNaN(v) {
+			return fmt.Errorf("unsupported value %v, only finite numbers are", v)
+		}
+		return nil
+	case []interface{}:
+		for _, x := range v {
+			if err := checkLiteral(x); err != nil {
+				return err
+			}
+		}
+		return nil
+	case map[string]interface{}:
+		for _, x := range v {
+			if err := checkLiteral(x); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("unsupported value %v of type %T, quote it as a string", v, v)
+}
+
+// comment formats text as the lines of a comment, indented by one tab.
+// Control characters, which Go source can't hold, are replaced with spaces.
+func comment(text string) string {
+	var lines = strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.Map(func(r rune) rune {
+			if r != '\t' && unicode.IsControl(r) || r == '\uFEFF' {
+				return ' '
+			}
+			return r
+		}, strings.ToValidUTF8(line, " "))
+	}
+	return strings.Join(lines, "\n\t// ")
+}
+
+// literal formats a value decoded from the spec as a Go expression.
+func literal(v interface{}) string {
+	if v == nil {
+		return "nil"
+	}
+	return fmt.Sprintf("%#v", v)
+}
+
+// Generate renders the Go source for the given spec.
+func Generate(spec *Spec) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := sourceTemplate.Execute(&buf, spec); err != nil {
+		return nil, fmt.Errorf("generate: %w", err)
+	}
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("generate: %w", err)
+	}
+	return src, nil
+}
+
+var sourceTemplate = template.Must(template.New("source").Funcs(template.FuncMap{"literal": literal, "comment": comment}).Parse(`// Code generated by dredd-gen. DO NOT EDIT.
+
+package {{.Package}}
+
+import "github.com/leoslamas/dredd-go/rule"
+{{if .Keys}}
+// Context keys of the rule set.
+const (
+{{- range .Keys}}
+{{- if .Description}}
+	// Key{{.Ident}}: {{comment .Description}}
+{{- end}}
+	Key{{.Ident}} = {{printf "%q" .Name}}
+{{- end}}
+)
+
+// Values holds the typed values of the rule set context keys.
+type Values struct {
+{{- range .Keys}}
+	{{.Ident}} {{.Type}}
+{{- end}}
+}
+
+// Load reads the typed values from the rule context.
+// Missing keys or values of another type keep their zero value.
+func (v *Values) Load(rc *rule.RuleContext) {
+{{- range .Keys}}
+	if x, ok := rc.Get(Key{{.Ident}}).({{.Type}}); ok {
+		v.{{.Ident}} = x
+	}
+{{- end}}
+}
+
+// Store writes the typed values into the rule context.
+func (v *Values) Store(rc *rule.RuleContext) {
+{{- range .Keys}}
+	rc.Set(Key{{.Ident}}, v.{{.Ident}})
+{{- end}}
+}
+{{end}}
+{{- if .Rules}}
+// Rules holds the rules of the rule set.
+type Rules struct {
+{{- range .All}}
+	{{.Ident}} *rule.BaseRule[rule.{{$.Type}}]
+{{- end}}
+}
+
+// NewRules creates the rules of the rule set and wires their children.
+func NewRules() *Rules {
+	var r = &Rules{
+{{- range .All}}
+		{{.Ident}}: rule.New{{$.Type}}(),
+{{- end}}
+	}
+{{- range .All}}
+{{- if .Children}}
+	r.{{.Ident}}.AddChildren({{range $i, $c := .Children}}{{if $i}}, {{end}}r.{{$c.Ident}}{{end}})
+{{- end}}
+{{- end}}
+	return r
+}
+
+// Run runs the root rules within the given rule context.
+func (r *Rules) Run(ruleContext *rule.RuleContext) error {
+	return rule.{{.Type}}Runner(ruleContext{{range .Rules}}, r.{{.Ident}}{{end}})
+}
+{{if .Examples}}
+// Examples returns the examples of the rule set.
+func (r *Rules) Examples() []rule.Example {
+	return []rule.Example{
+{{- range .Examples}}
+		{Name: {{printf "%q" .Name}}, Input: {{literal .Input}}, Expected: {{literal .Expected}}, Decision: {{literal .Decision}}},
+{{- end}}
+	}
+}
+
+// RunExamples checks that the rule set produces the outcome of every example.
+func (r *Rules) RunExamples() error {
+	return rule.SelfTest(r.Run, r.Examples()...)
+}
+{{end}}{{end}}`))
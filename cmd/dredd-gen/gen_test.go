@@ -0,0 +1,173 @@
+package main
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testSpec = `
+package: checkout
+type: chain
+keys:
+  - name: order.total
+    type: float64
+    description: Total amount of the order.
+  - name: approved
+    type: bool
+rules:
+  - name: validate
+    children:
+      - name: charge
+        children:
+          - name: notify
+examples:
+  - name: small order
+    input:
+      order.total: 10.5
+      items: [{sku: A1, since: "2024-01-02"}]
+    expected:
+      approved: true
+`
+
+func TestParseSpec(t *testing.T) {
+	spec, err := ParseSpec([]byte(testSpec))
+	assert.NoError(t, err)
+	assert.Equal(t, "checkout", spec.Package)
+	assert.Equal(t, "ChainRule", spec.Type)
+	assert.Equal(t, "OrderTotal", spec.Keys[0].Ident)
+	assert.Equal(t, 3, len(spec.All))
+	assert.Equal(t, "Notify", spec.All[2].Ident)
+}
+
+func TestParseSpec_JSON(t *testing.T) {
+	spec, err := ParseSpec([]byte(`{"package": "p", "type": "best-first", "rules": [{"name": "a"}, {"name": "b"}]}`))
+	assert.NoError(t, err)
+	assert.Equal(t, "BestFirstRule", spec.Type)
+	assert.Equal(t, 2, len(spec.All))
+
+	spec, err = ParseSpec([]byte(`{"package": "p", "type": "all-match", "rules": [{"name": "a"}, {"name": "b"}]}`))
+	assert.NoError(t, err)
+	assert.Equal(t, "AllMatchRule", spec.Type)
+
+	spec, err = ParseSpec([]byte(`{"package": "p", "type": "fallback", "rules": [{"name": "a"}]}`))
+	assert.NoError(t, err)
+	assert.Equal(t, "FallbackRule", spec.Type)
+}
+
+func TestParseSpec_Errors(t *testing.T) {
+	_, err := ParseSpec([]byte(`type: chain`))
+	assert.EqualError(t, err, "parse spec: missing package")
+
+	_, err = ParseSpec([]byte("package: p\ntype: unknown"))
+	assert.EqualError(t, err, `parse spec: unknown rule type "unknown"`)
+
+	_, err = ParseSpec([]byte("package: p\nrules: [{name: a}, {name: b}]"))
+	assert.EqualError(t, err, "parse spec: chain rule set can only have one root rule")
+
+	_, err = ParseSpec([]byte("package: p\ntype: best-first\nrules: [{name: a}, {name: a}]"))
+	assert.EqualError(t, err, `parse spec: duplicate rule "A"`)
+
+	_, err = ParseSpec([]byte("package: p\nkeys: [{name: user_id}, {name: user.id}]"))
+	assert.EqualError(t, err, `parse spec: keys "user_id" and "user.id" have the same identifier "UserId"`)
+
+	_, err = ParseSpec([]byte("package: 1p"))
+	assert.EqualError(t, err, `parse spec: invalid package name "1p"`)
+
+	_, err = ParseSpec([]byte("package: p\nkeys: [{name: a, type: \"int // c\"}]"))
+	assert.EqualError(t, err, `parse spec: key "a": invalid type "int // c"`)
+
+	_, err = ParseSpec([]byte("package: p\nexamples: [{name: a, input: {x: .nan}}]"))
+	assert.EqualError(t, err, `parse spec: example "a": unsupported value NaN, only finite numbers are`)
+
+	_, err = ParseSpec([]byte("package: p\nkeys: [{name: a, ident: a-b}]"))
+	assert.EqualError(t, err, `parse spec: key "a": invalid identifier "a-b"`)
+
+	_, err = ParseSpec([]byte("package: p\nkeys: [{name: store}]"))
+	assert.EqualError(t, err, `parse spec: key "store": identifier "Store" is reserved, set another ident`)
+
+	_, err = ParseSpec([]byte("package: p\nrules: [{name: run}]"))
+	assert.EqualError(t, err, `parse spec: rule "run": identifier "Run" is reserved, set another ident`)
+
+	_, err = ParseSpec([]byte("package: p\nkeys: [{name: ttl, type: time.Duration}]"))
+	assert.EqualError(t, err, `parse spec: key "ttl": type "time.Duration" needs an import, types of other packages are not supported`)
+
+	_, err = ParseSpec([]byte("package: p\nkeys: [{name: ttl, type: \"map[string\"}]"))
+	assert.EqualError(t, err, `parse spec: key "ttl": invalid type "map[string"`)
+
+	_, err = ParseSpec([]byte("package: p\nexamples: [{name: a, input: {since: [2024-01-02]}}]"))
+	assert.EqualError(t, err, `parse spec: example "a": unsupported value 2024-01-02 00:00:00 +0000 UTC of type time.Time, quote it as a string`)
+}
+
+func TestGenerate_MultilineDescription(t *testing.T) {
+	spec, err := ParseSpec([]byte("package: checkout\nkeys: [{name: ttl, type: int, description: \"time\\nto\\x00live\"}]"))
+	assert.NoError(t, err)
+
+	src, err := Generate(spec)
+	assert.NoError(t, err)
+	assert.Contains(t, string(src), "// KeyTtl: time\n\t// to live\n")
+	typeCheck(t, src)
+}
+
+func TestIdentifier(t *testing.T) {
+	assert.Equal(t, "OrderTotal", identifier("order.total"))
+	assert.Equal(t, "UserId", identifier("user_id"))
+	assert.Equal(t, "X3ds", identifier("3ds"))
+}
+
+func TestGenerate(t *testing.T) {
+	spec, err := ParseSpec([]byte(testSpec))
+	assert.NoError(t, err)
+
+	src, err := Generate(spec)
+	assert.NoError(t, err)
+
+	var code = string(src)
+	assert.Contains(t, code, "// Code generated by dredd-gen. DO NOT EDIT.")
+	assert.Contains(t, code, `KeyOrderTotal = "order.total"`)
+	assert.Contains(t, code, "OrderTotal float64")
+	assert.Contains(t, code, "if x, ok := rc.Get(KeyApproved).(bool); ok {")
+	assert.Contains(t, code, "Validate *rule.BaseRule[rule.ChainRule]")
+	assert.Contains(t, code, "r.Validate.AddChildren(r.Charge)")
+	assert.Contains(t, code, "r.Charge.AddChildren(r.Notify)")
+	assert.Contains(t, code, "rule.ChainRuleRunner(ruleContext, r.Validate)")
+	assert.Contains(t, code, `{Name: "small order", Input: map[string]interface{}{"items": []interface{}{map[string]interface{}{"since": "2024-01-02", "sku": "A1"}}, "order.total": 10.5}, Expected: map[string]interface{}{"approved": true}, Decision: nil},`)
+	assert.Contains(t, code, "return rule.SelfTest(r.Run, r.Examples()...)")
+	typeCheck(t, src)
+}
+
+// typeCheck fails the test unless the generated source compiles against the
+// rule package.
+func typeCheck(t *testing.T, src []byte) {
+	t.Helper()
+	var fset = token.NewFileSet()
+	file, err := parser.ParseFile(fset, "rules_gen.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var config = types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	if _, err := config.Check("checkout", fset, []*ast.File{file}, nil); err != nil {
+		t.Fatalf("generated code doesn't compile: %v\n%s", err, src)
+	}
+}
+
+func FuzzParseSpec(f *testing.F) {
+	f.Add([]byte(testSpec))
+	f.Add([]byte(`{"package": "p", "type": "best-first", "rules": [{"name": "a", "children": [{"name": "b"}]}]}`))
+	f.Add([]byte("package: p\nkeys: [{name: ttl, type: \"map[string][]int\", description: \"time\\nto live\"}]"))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		spec, err := ParseSpec(data)
+		if err != nil {
+			return
+		}
+		if _, err := Generate(spec); err != nil {
+			t.Fatalf("parsed spec doesn't generate: %v", err)
+		}
+	})
+}
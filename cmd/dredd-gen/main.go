@@ -0,0 +1,52 @@
+// Command dredd-gen generates typed Go wrappers from a YAML or JSON rule set
+// description: named constants for context keys, a typed Values struct to
+// load and store them, and constructors wiring the described rule tree.
+//
+// Usage:
+//
+//	//go:generate go run github.com/leoslamas/dredd-go/cmd/dredd-gen -in rules.yaml -out rules_gen.go
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+)
+
+func main() {
+	var in = flag.String("in", "", "rule set description (YAML or JSON)")
+	var out = flag.String("out", "", "generated Go file (default stdout)")
+	flag.Parse()
+
+	if err := run(*in, *out); err != nil {
+		fmt.Fprintln(os.Stderr, "dredd-gen:", err)
+		os.Exit(1)
+	}
+}
+
+func run(in, out string) error {
+	if in == "" {
+		return fmt.Errorf("missing -in")
+	}
+
+	data, err := os.ReadFile(in)
+	if err != nil {
+		return err
+	}
+
+	spec, err := ParseSpec(data)
+	if err != nil {
+		return err
+	}
+
+	src, err := Generate(spec)
+	if err != nil {
+		return err
+	}
+
+	if out == "" {
+		_, err = os.Stdout.Write(src)
+		return err
+	}
+	return os.WriteFile(out, src, 0o644)
+}
@@ -0,0 +1,28 @@
+// Package ident turns context key names into Go identifiers, for the code
+// generated by rule.KeySet and dredd-gen.
+package ident
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Exported returns name in CamelCase, dropping the characters other than
+// letters and digits and upper-casing the letters after them, so "order.total"
+// and "order_total" both become "OrderTotal".
+func Exported(name string) string {
+	var b strings.Builder
+	var upper = true
+	for _, c := range name {
+		if !unicode.IsLetter(c) && !unicode.IsDigit(c) {
+			upper = true
+			continue
+		}
+		if upper {
+			c = unicode.ToUpper(c)
+			upper = false
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
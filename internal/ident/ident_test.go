@@ -0,0 +1,16 @@
+package ident
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExported(t *testing.T) {
+	assert.Equal(t, "OrderTotal", Exported("order.total"))
+	assert.Equal(t, "OrderTotal", Exported("order_total"))
+	assert.Equal(t, "UserId", Exported("user-id"))
+	assert.Equal(t, "Café", Exported("café"))
+	assert.Equal(t, "1st", Exported("1st"))
+	assert.Equal(t, "", Exported("--"))
+}
@@ -0,0 +1,271 @@
+// Package refdata loads reference tables, such as country lists or price
+// tables, into a rule.GlobalContext and keeps them fresh on a schedule.
+//
+// Each table is published under its name as a single global value, so rules
+// read it with rc.Get(name) like any other key.
+package refdata
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/leoslamas/dredd-go/rule"
+)
+
+// Source loads the current content of a table.
+type Source interface {
+	Load(ctx context.Context) (interface{}, error)
+}
+
+// SourceFunc adapts an ordinary function to the Source interface.
+type SourceFunc func(ctx context.Context) (interface{}, error)
+
+// Load calls f(ctx).
+func (f SourceFunc) Load(ctx context.Context) (interface{}, error) {
+	return f(ctx)
+}
+
+// Decoder turns raw file or HTTP content into a table.
+type Decoder func(data []byte) (interface{}, error)
+
+// JSON decodes a JSON document into maps, slices and scalars.
+func JSON(data []byte) (interface{}, error) {
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// Lines decodes one entry per line into a set, a map[string]bool. Blank lines
+// and lines starting with # are skipped.
+func Lines(data []byte) (interface{}, error) {
+	var set = make(map[string]bool)
+	var scanner = bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		var line = strings.TrimSpace(scanner.Text())
+		if line != "" && !strings.HasPrefix(line, "#") {
+			set[line] = true
+		}
+	}
+	return set, scanner.Err()
+}
+
+// File returns a Source reading the file at path.
+func File(path string, decode Decoder) Source {
+	return SourceFunc(func(context.Context) (interface{}, error) {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		return decode(data)
+	})
+}
+
+// HTTP returns a Source fetching url with a GET request. Responses with a
+// status other than 200 are errors.
+func HTTP(client *http.Client, url string, decode Decoder) Source {
+	return SourceFunc(func(ctx context.Context) (interface{}, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("GET %s: status %d", url, resp.StatusCode)
+		}
+		data, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, err
+		}
+		return decode(data)
+	})
+}
+
+// Queryer is the subset of *sql.DB used by SQL.
+type Queryer interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// SQL returns a Source running a query. Rows of a single column are loaded
+// into a set, a map[string]bool; rows of two columns into a map from the first
+// column to the second.
+func SQL(db Queryer, query string, args ...interface{}) Source {
+	return SourceFunc(func(ctx context.Context) (interface{}, error) {
+		rows, err := db.QueryContext(ctx, query, args...)
+		if err != nil {
+			return nil, err
+		}
+		defer rows.Close()
+
+		columns, err := rows.Columns()
+		if err != nil {
+			return nil, err
+		}
+		var set = make(map[string]bool)
+		var table = make(map[string]interface{})
+		for rows.Next() {
+			var key string
+			var value interface{}
+			switch len(columns) {
+			case 1:
+				err = rows.Scan(&key)
+				set[key] = true
+			case 2:
+				err = rows.Scan(&key, &value)
+				table[key] = value
+			default:
+				return nil, fmt.Errorf("query returns %d columns, want 1 or 2", len(columns))
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+		if len(columns) == 1 {
+			return set, nil
+		}
+		return table, nil
+	})
+}
+
+// TableStats describes the freshness of a table.
+type TableStats struct {
+	Name string
+	// LoadedAt is the time of the last successful load, zero if none.
+	LoadedAt time.Time
+	// Failures counts the loads that failed since the last successful one.
+	Failures  int
+	LastError error
+}
+
+// Age returns how long ago the table was last loaded successfully.
+func (s TableStats) Age(now time.Time) time.Duration {
+	return now.Sub(s.LoadedAt)
+}
+
+type table struct {
+	name   string
+	source Source
+	every  time.Duration
+	due    time.Time
+	stats  TableStats
+}
+
+// Loader loads tables into a GlobalContext. It is safe for concurrent use.
+type Loader struct {
+	global *rule.GlobalContext
+	mu     sync.Mutex
+	tables []*table
+	now    func() time.Time
+}
+
+// NewLoader creates a Loader publishing tables into global.
+func NewLoader(global *rule.GlobalContext) *Loader {
+	return &Loader{global: global, now: time.Now}
+}
+
+// Add registers a table loaded from source and refreshed every interval by Run.
+func (l *Loader) Add(name string, source Source, every time.Duration) *Loader {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.tables = append(l.tables, &table{name: name, source: source, every: every, stats: TableStats{Name: name}})
+	return l
+}
+
+// Refresh loads every table now. A table that fails to load keeps its previous
+// content; the errors of all the failed tables are joined.
+func (l *Loader) Refresh(ctx context.Context) error {
+	return l.refresh(ctx, func(*table) bool { return true })
+}
+
+func (l *Loader) refresh(ctx context.Context, due func(*table) bool) error {
+	l.mu.Lock()
+	var tables []*table
+	for _, t := range l.tables {
+		if due(t) {
+			tables = append(tables, t)
+		}
+	}
+	l.mu.Unlock()
+
+	var errs []error
+	for _, t := range tables {
+		var value, err = t.source.Load(ctx)
+		var now = l.now()
+
+		l.mu.Lock()
+		t.due = now.Add(t.every)
+		if err != nil {
+			t.stats.Failures++
+			t.stats.LastError = err
+			errs = append(errs, fmt.Errorf("refdata: table %s: %w", t.name, err))
+		} else {
+			t.stats = TableStats{Name: t.name, LoadedAt: now}
+			l.global.Set(t.name, value)
+		}
+		l.mu.Unlock()
+	}
+	return errors.Join(errs...)
+}
+
+// Run refreshes the tables as they become due until ctx is done. Load errors
+// are reported to onError, which may be nil, and recorded in the stats.
+func (l *Loader) Run(ctx context.Context, tick time.Duration, onError func(error)) {
+	var ticker = time.NewTicker(tick)
+	defer ticker.Stop()
+	for {
+		var now = l.now()
+		if err := l.refresh(ctx, func(t *table) bool { return !now.Before(t.due) }); err != nil && onError != nil {
+			onError(err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Stats returns the freshness of every table, sorted by name.
+func (l *Loader) Stats() []TableStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var stats = make([]TableStats, 0, len(l.tables))
+	for _, t := range l.tables {
+		stats = append(stats, t.stats)
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Name < stats[j].Name })
+	return stats
+}
+
+// Stale returns the sorted names of the tables not loaded successfully within
+// maxAge, including those never loaded.
+func (l *Loader) Stale(maxAge time.Duration) []string {
+	var now = l.now()
+	var names []string
+	for _, s := range l.Stats() {
+		if s.LoadedAt.IsZero() || s.Age(now) > maxAge {
+			names = append(names, s.Name)
+		}
+	}
+	return names
+}
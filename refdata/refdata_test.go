@@ -0,0 +1,123 @@
+package refdata
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/leoslamas/dredd-go/rule"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFile(t *testing.T) {
+	var path = filepath.Join(t.TempDir(), "blocked.txt")
+	assert.NoError(t, os.WriteFile(path, []byte("# blocked users\nalice\n\nbob\n"), 0o600))
+
+	table, err := File(path, Lines).Load(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]bool{"alice": true, "bob": true}, table)
+
+	_, err = File(filepath.Join(t.TempDir(), "missing"), Lines).Load(context.Background())
+	assert.Error(t, err)
+}
+
+func TestHTTP(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/prices" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{"basic": 10, "pro": 25}`))
+	}))
+	defer server.Close()
+
+	table, err := HTTP(server.Client(), server.URL+"/prices", JSON).Load(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"basic": 10.0, "pro": 25.0}, table)
+
+	_, err = HTTP(server.Client(), server.URL+"/missing", JSON).Load(context.Background())
+	assert.EqualError(t, err, "GET "+server.URL+"/missing: status 404")
+}
+
+func TestSQL(t *testing.T) {
+	var db = openFake([]string{"code"}, [][]driver.Value{{"PT"}, {"ES"}})
+	table, err := SQL(db, "SELECT code FROM countries").Load(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]bool{"PT": true, "ES": true}, table)
+
+	db = openFake([]string{"plan", "price"}, [][]driver.Value{{"basic", int64(10)}})
+	table, err = SQL(db, "SELECT plan, price FROM prices").Load(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"basic": int64(10)}, table)
+
+	db = openFake([]string{"a", "b", "c"}, [][]driver.Value{{1, 2, 3}})
+	_, err = SQL(db, "SELECT a, b, c FROM t").Load(context.Background())
+	assert.EqualError(t, err, "query returns 3 columns, want 1 or 2")
+}
+
+func TestLoader_Refresh(t *testing.T) {
+	var now = time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	var failure = errors.New("unavailable")
+	var fail = false
+	var global = rule.NewGlobalContext(map[string]interface{}{"config": "kept"})
+	var loader = NewLoader(global).
+		Add("countries", SourceFunc(func(context.Context) (interface{}, error) {
+			return map[string]bool{"PT": true}, nil
+		}), time.Hour).
+		Add("prices", SourceFunc(func(context.Context) (interface{}, error) {
+			if fail {
+				return nil, failure
+			}
+			return map[string]interface{}{"basic": 10}, nil
+		}), time.Minute)
+	loader.now = func() time.Time { return now }
+
+	assert.Equal(t, []string{"countries", "prices"}, loader.Stale(time.Hour))
+	assert.NoError(t, loader.Refresh(context.Background()))
+
+	rc := rule.NewRuleContext(rule.WithGlobal(global))
+	assert.Equal(t, map[string]bool{"PT": true}, rc.Get("countries"))
+	assert.Equal(t, "kept", rc.Get("config"))
+
+	fail = true
+	now = now.Add(2 * time.Hour)
+	err := loader.Refresh(context.Background())
+	assert.EqualError(t, err, "refdata: table prices: unavailable")
+	assert.ErrorIs(t, err, failure)
+	assert.Equal(t, map[string]interface{}{"basic": 10}, global.Get("prices"))
+
+	var stats = loader.Stats()
+	assert.Equal(t, "prices", stats[1].Name)
+	assert.Equal(t, 1, stats[1].Failures)
+	assert.Equal(t, failure, stats[1].LastError)
+	assert.Equal(t, 2*time.Hour, stats[1].Age(now))
+	assert.Equal(t, []string{"prices"}, loader.Stale(time.Hour))
+}
+
+func TestLoader_Run(t *testing.T) {
+	var loads int32
+	var global = rule.NewGlobalContext(nil)
+	var loader = NewLoader(global).Add("table", SourceFunc(func(context.Context) (interface{}, error) {
+		return atomic.AddInt32(&loads, 1), nil
+	}), time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var done = make(chan struct{})
+	go func() {
+		loader.Run(ctx, time.Millisecond, nil)
+		close(done)
+	}()
+	assert.Eventually(t, func() bool { return atomic.LoadInt32(&loads) >= 3 }, time.Second, time.Millisecond)
+	cancel()
+	<-done
+
+	assert.NotNil(t, global.Get("table"))
+	assert.Empty(t, loader.Stale(time.Hour))
+}
@@ -0,0 +1,152 @@
+// Package routing sends the outcomes of rule set runs to sinks, such as
+// channels, callbacks or queue topics, according to the values the runs
+// decided, so dispatching a decision doesn't need to live in execute hooks.
+package routing
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/leoslamas/dredd-go/rule"
+)
+
+// Any matches any value of a routed key, as long as it is set.
+var Any = any(anyValue{})
+
+type anyValue struct{}
+
+// Outcome is a routed run outcome.
+type Outcome struct {
+	Time time.Time
+	// Key and Value are the routed key and its value at the end of the run.
+	Key   string
+	Value interface{}
+	// Values holds all the context values at the end of the run.
+	Values map[string]interface{}
+	// Decision is the aggregated decision of the run, if any was set.
+	Decision *rule.ScoredDecision
+}
+
+// Sink receives routed outcomes.
+type Sink interface {
+	Send(outcome Outcome) error
+}
+
+// SinkFunc adapts an ordinary function to the Sink interface, for callbacks.
+type SinkFunc func(outcome Outcome) error
+
+// Send calls f(outcome).
+func (f SinkFunc) Send(outcome Outcome) error {
+	return f(outcome)
+}
+
+// Channel returns a Sink sending outcomes on ch. Sends block until the
+// outcome is received.
+func Channel(ch chan<- Outcome) Sink {
+	return SinkFunc(func(outcome Outcome) error {
+		ch <- outcome
+		return nil
+	})
+}
+
+// Publisher publishes messages to the topics of a queue or broker.
+type Publisher interface {
+	Publish(topic string, message []byte) error
+}
+
+// Message is the JSON message published by Topic sinks.
+type Message struct {
+	Time       time.Time              `json:"time"`
+	Key        string                 `json:"key"`
+	Value      interface{}            `json:"value"`
+	Values     map[string]interface{} `json:"values"`
+	Decision   interface{}            `json:"decision,omitempty"`
+	Confidence float64                `json:"confidence,omitempty"`
+}
+
+// Topic returns a Sink publishing outcomes to a topic as JSON Messages.
+func Topic(publisher Publisher, topic string) Sink {
+	return SinkFunc(func(outcome Outcome) error {
+		var m = Message{Time: outcome.Time, Key: outcome.Key, Value: outcome.Value, Values: outcome.Values}
+		if outcome.Decision != nil {
+			m.Decision, m.Confidence = outcome.Decision.Value, outcome.Decision.Confidence
+		}
+		data, err := json.Marshal(m)
+		if err != nil {
+			return fmt.Errorf("encode message: %w", err)
+		}
+		return publisher.Publish(topic, data)
+	})
+}
+
+type route struct {
+	key   string
+	value interface{}
+	sinks []Sink
+}
+
+func (r route) match(values map[string]interface{}) (interface{}, bool) {
+	var value, ok = values[r.key]
+	if !ok {
+		return nil, false
+	}
+	return value, r.value == Any || reflect.DeepEqual(r.value, value)
+}
+
+// Router maps the values of context keys at the end of a run to sinks.
+// Configure it before use; dispatching is then safe for concurrent use.
+type Router struct {
+	routes []route
+}
+
+// New creates a Router without routes.
+func New() *Router {
+	return &Router{}
+}
+
+// Route sends the outcome of the runs ending with the key set to value, or to
+// any value when value is Any, to the sinks. Every matching route is taken,
+// in the order they were added.
+func (r *Router) Route(key string, value interface{}, sinks ...Sink) *Router {
+	r.routes = append(r.routes, route{key: key, value: value, sinks: sinks})
+	return r
+}
+
+// Dispatch sends the outcome of the run of the context to the sinks of the
+// matching routes. A failing sink doesn't prevent the others from receiving
+// the outcome; all the errors are returned, joined.
+func (r *Router) Dispatch(rc *rule.RuleContext) error {
+	var errs []error
+	var values = rc.Values()
+	for _, route := range r.routes {
+		var value, ok = route.match(values)
+		if !ok {
+			continue
+		}
+
+		var outcome = Outcome{Time: rc.Now(), Key: route.key, Value: value, Values: rc.Values()}
+		if decision, ok := rc.Decision(); ok {
+			outcome.Decision = &decision
+		}
+		for _, sink := range route.sinks {
+			if err := sink.Send(outcome); err != nil {
+				errs = append(errs, fmt.Errorf("routing: %s=%v: %w", route.key, value, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Wrap returns a program dispatching the outcome of each successful run of p.
+// Runs failing with an error are not routed.
+func (r *Router) Wrap(p rule.Program) rule.Program {
+	return func(rc *rule.RuleContext) error {
+		if err := p(rc); err != nil {
+			return err
+		}
+		return r.Dispatch(rc)
+	}
+}
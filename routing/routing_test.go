@@ -0,0 +1,77 @@
+package routing
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/leoslamas/dredd-go/rule"
+	"github.com/stretchr/testify/assert"
+)
+
+type publisher map[string][][]byte
+
+func (p publisher) Publish(topic string, message []byte) error {
+	p[topic] = append(p[topic], message)
+	return nil
+}
+
+func decide(verdict string) rule.Program {
+	return rule.NewProgram(rule.NewChainRule().OnExecute(func(ctx rule.Context) {
+		ctx.GetRuleContext().Set("verdict", verdict)
+		ctx.GetRuleContext().SetDecision(verdict, 0.9)
+	}))
+}
+
+func TestRouter(t *testing.T) {
+	var now = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	var ch = make(chan Outcome, 1)
+	var called []string
+	var pub = publisher{}
+
+	var router = New().
+		Route("verdict", "deny", Channel(ch), Topic(pub, "denials")).
+		Route("verdict", Any, SinkFunc(func(o Outcome) error {
+			called = append(called, o.Value.(string))
+			return nil
+		})).
+		Route("missing", Any, SinkFunc(func(Outcome) error { panic("unreachable") }))
+
+	rc := rule.NewRuleContext(rule.WithClock(rule.ClockFunc(func() time.Time { return now })))
+	assert.NoError(t, router.Wrap(decide("deny"))(rc))
+
+	var outcome = <-ch
+	assert.Equal(t, "verdict", outcome.Key)
+	assert.Equal(t, "deny", outcome.Value)
+	assert.Equal(t, map[string]interface{}{"verdict": "deny"}, outcome.Values)
+	assert.Equal(t, "deny", outcome.Decision.Value)
+	assert.Equal(t, []string{"deny"}, called)
+
+	var m Message
+	assert.Equal(t, 1, len(pub["denials"]))
+	assert.NoError(t, json.Unmarshal(pub["denials"][0], &m))
+	assert.Equal(t, Message{Time: now, Key: "verdict", Value: "deny", Values: map[string]interface{}{"verdict": "deny"},
+		Decision: "deny", Confidence: 0.9}, m)
+
+	rc = rule.NewRuleContext()
+	assert.NoError(t, router.Wrap(decide("allow"))(rc))
+	assert.Empty(t, ch)
+	assert.Equal(t, []string{"deny", "allow"}, called)
+}
+
+func TestRouter_Errors(t *testing.T) {
+	var received int
+	var router = New().Route("verdict", Any,
+		SinkFunc(func(Outcome) error { return errors.New("queue down") }),
+		SinkFunc(func(Outcome) error { received++; return nil }),
+	)
+
+	rc := rule.NewRuleContext()
+	assert.EqualError(t, router.Wrap(decide("deny"))(rc), "routing: verdict=deny: queue down")
+	assert.Equal(t, 1, received)
+
+	var failing = func(*rule.RuleContext) error { return errors.New("boom") }
+	assert.EqualError(t, router.Wrap(failing)(rule.NewRuleContext()), "boom")
+	assert.Equal(t, 1, received)
+}
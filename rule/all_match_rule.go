@@ -0,0 +1,31 @@
+package rule
+
+type AllMatchRule struct {
+	*BaseRule[AllMatchRule]
+}
+
+func NewAllMatchRule() *BaseRule[AllMatchRule] {
+	return &BaseRule[AllMatchRule]{
+		ruleType:      AllMatchRuleType,
+		context:       NewRuleContext(),
+		children:      make([]*BaseRule[AllMatchRule], 0),
+		onEval:        func(r Context) bool { return true },
+		onPreExecute:  func(r Context) {},
+		onExecute:     func(r Context) {},
+		onPostExecute: func(r Context) {},
+	}
+}
+
+// AllMatchRuleRunner executes a list of AllMatchRule rules within a given RuleContext.
+// Unlike BestFirstRuleRunner, it does not stop at the first matching rule: every rule
+// whose OnEval returns true is executed, followed by its children, in order.
+//
+// Parameters:
+//   - ruleContext: A pointer to the RuleContext in which the rules will be executed.
+//   - rules: A slice of pointers to AllMatchRule objects to be executed.
+//
+// Returns:
+//   - The *RuleError stopping the run, if any.
+func AllMatchRuleRunner[T any](ruleContext *RuleContext, rules ...*BaseRule[T]) error {
+	return RuleRunner(AllMatchRuleType, ruleContext, rules...)
+}
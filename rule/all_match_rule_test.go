@@ -0,0 +1,58 @@
+package rule
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewAllMatchRule(t *testing.T) {
+	rule := NewAllMatchRule()
+	assert.Equal(t, AllMatchRuleType, rule.RuleType())
+	assert.Equal(t, "all-match", rule.RuleType().String())
+	assert.NotNil(t, rule.context)
+	assert.Empty(t, rule.children)
+}
+
+func notifier(channel string, match bool) *BaseRule[AllMatchRule] {
+	return NewAllMatchRule().WithName(channel).
+		OnEval(func(Context) bool { return match }).
+		OnExecute(func(ctx Context) {
+			var rc = ctx.GetRuleContext()
+			var sent, _ = rc.Get("sent").([]string)
+			rc.Set("sent", append(sent, channel))
+		})
+}
+
+func TestAllMatchRuleRunner(t *testing.T) {
+	ruleContext := NewRuleContext()
+
+	assert.NoError(t, AllMatchRuleRunner[AllMatchRule](ruleContext))
+	assert.NoError(t, AllMatchRuleRunner(ruleContext,
+		notifier("email", true),
+		notifier("sms", false).AddChildren(notifier("sms-backup", true)),
+		notifier("push", true).AddChildren(notifier("watch", true), notifier("tablet", false), notifier("tv", true)),
+		notifier("slack", true),
+	))
+
+	assert.Equal(t, []string{"email", "push", "watch", "tv", "slack"}, ruleContext.Get("sent"))
+}
+
+func TestAllMatchRuleRunner_StopsAtError(t *testing.T) {
+	ruleContext := NewRuleContext()
+	var failing = NewAllMatchRule().WithName("failing").OnInit(func() error { return errors.New("boom") })
+
+	var err = AllMatchRuleRunner(ruleContext, notifier("email", true), failing, notifier("sms", true))
+
+	assert.EqualError(t, err, `rule "failing" in init: boom`)
+	assert.Equal(t, []string{"email"}, ruleContext.Get("sent"))
+}
+
+func TestNewAssertRule_AllMatch(t *testing.T) {
+	ruleContext := NewRuleContext()
+	assert.NoError(t, AllMatchRuleRunner(ruleContext,
+		NewAssertRule[AllMatchRule]("always").AddChildren(notifier("email", true), notifier("sms", true)),
+	))
+	assert.Equal(t, []string{"email", "sms"}, ruleContext.Get("sent"))
+}
@@ -0,0 +1,65 @@
+package rule
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// NewLabel creates a pseudo-rule documenting the tree with a text. It is never
+// evaluated nor executed; runners skip over it as if it were not there, but it
+// is recorded in the evaluations of a context created with WithHistory.
+func NewLabel[T any](text string) *BaseRule[T] {
+	return &BaseRule[T]{name: text, ruleType: ruleTypeOf[T](), context: NewRuleContext(), annotation: true}
+}
+
+// NewGroup creates a named pseudo-rule structuring the tree. It is never
+// evaluated nor executed: its children run in its place, as if they were
+// children of its parent, and for best-first rules the siblings after the
+// group run only when none of its children matched.
+func NewGroup[T any](name string, rules ...*BaseRule[T]) *BaseRule[T] {
+	var r = NewLabel[T](name)
+	return r.AddChildren(rules...)
+}
+
+// IsAnnotation reports whether the rule is a label or a group.
+func (r *BaseRule[T]) IsAnnotation() bool {
+	return r.annotation
+}
+
+// PrintTree writes an indented outline of the rules and their descendants,
+// with labels shown as comments and groups in brackets.
+func PrintTree[T any](w io.Writer, rules ...*BaseRule[T]) error {
+	return printTree(w, rules, 0)
+}
+
+func printTree[T any](w io.Writer, rules []*BaseRule[T], depth int) error {
+	for _, r := range rules {
+		var text string
+		switch {
+		case r.annotation && len(r.children) == 0:
+			text = "# " + r.name
+		case r.annotation:
+			text = "[" + r.name + "]"
+		case r.name == "":
+			text = "(unnamed)"
+		default:
+			text = r.name
+		}
+		if _, err := fmt.Fprintf(w, "%s%s\n", strings.Repeat("  ", depth), text); err != nil {
+			return err
+		}
+		if err := printTree(w, r.children, depth+1); err != nil {
+			return err
+		}
+		if len(r.elseChildren) > 0 {
+			if _, err := fmt.Fprintf(w, "%selse\n", strings.Repeat("  ", depth+1)); err != nil {
+				return err
+			}
+			if err := printTree(w, r.elseChildren, depth+2); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
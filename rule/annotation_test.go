@@ -0,0 +1,54 @@
+package rule
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewGroup_BestFirst(t *testing.T) {
+	var fired []string
+	var rule = func(name string, matches bool) *BaseRule[BestFirstRule] {
+		return NewBestFirstRule().WithName(name).
+			OnEval(func(Context) bool { return matches }).
+			OnExecute(func(Context) { fired = append(fired, name) })
+	}
+
+	assert.NoError(t, BestFirstRuleRunner(NewRuleContext(),
+		NewGroup("fraud", rule("blocked", false), NewLabel[BestFirstRule]("velocity checks go here"), rule("stolen", false)),
+		rule("fallback", true),
+	))
+	assert.Equal(t, []string{"fallback"}, fired)
+
+	fired = nil
+	assert.NoError(t, BestFirstRuleRunner(NewRuleContext(),
+		NewGroup("fraud", rule("blocked", false), rule("stolen", true)),
+		rule("fallback", true),
+	))
+	assert.Equal(t, []string{"stolen"}, fired)
+}
+
+func TestNewGroup_Chain(t *testing.T) {
+	var executed = false
+	var group = NewGroup("checks", NewChainRule().OnExecute(func(Context) { executed = true }))
+	rc := NewRuleContext(WithHistory())
+
+	assert.NoError(t, ChainRuleRunner(rc, NewChainRule().WithName("root").AddChildren(group)))
+	assert.True(t, executed)
+	assert.True(t, group.IsAnnotation())
+	assert.Equal(t, Evaluation{Rule: group, Result: true, Annotation: true}, rc.Evaluations()[1])
+}
+
+func TestPrintTree(t *testing.T) {
+	var out strings.Builder
+	assert.NoError(t, PrintTree(&out,
+		NewGroup("fraud",
+			NewLabel[BestFirstRule]("card checks"),
+			NewBestFirstRule().WithName("blocked"),
+		),
+		NewBestFirstRule().WithName("fallback").AddChildren(NewBestFirstRule()),
+	))
+
+	assert.Equal(t, "[fraud]\n  # card checks\n  blocked\nfallback\n  (unnamed)\n", out.String())
+}
@@ -0,0 +1,55 @@
+package rule
+
+import "fmt"
+
+// AssertionError reports an invariant violated in an assert rule.
+type AssertionError struct {
+	Message string
+	// Invariant is the position of the violated invariant in the rule.
+	Invariant int
+}
+
+func (e *AssertionError) Error() string {
+	return fmt.Sprintf("assertion failed: %s (invariant %d)", e.Message, e.Invariant)
+}
+
+// NewAssertRule creates a guard rule checking invariants over the context. When
+// they all hold, the rule passes, as a rule whose OnEval returned true, and its
+// children run. Otherwise the run stops with a *RuleError wrapping an
+// *AssertionError with the message.
+//
+// T is the kind of the tree the rule is part of, such as ChainRule or BestFirstRule.
+func NewAssertRule[T any](message string, invariants ...func(Context) bool) *BaseRule[T] {
+	var r = &BaseRule[T]{
+		ruleType:      ruleTypeOf[T](),
+		context:       NewRuleContext(),
+		onEval:        func(r Context) bool { return true },
+		onPreExecute:  func(r Context) {},
+		onExecute:     func(r Context) {},
+		onPostExecute: func(r Context) {},
+	}
+	r.assert = func(ctx Context) error {
+		for i, invariant := range invariants {
+			if !invariant(ctx) {
+				return &AssertionError{Message: message, Invariant: i}
+			}
+		}
+		return nil
+	}
+	return r
+}
+
+func ruleTypeOf[T any]() RuleType {
+	switch any(*new(T)).(type) {
+	case BestFirstRule:
+		return BestFirstRuleType
+	case AllMatchRule:
+		return AllMatchRuleType
+	case ParallelRule:
+		return ParallelRuleType
+	case FallbackRule:
+		return FallbackRuleType
+	default:
+		return ChainRuleType
+	}
+}
@@ -0,0 +1,43 @@
+package rule
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewAssertRule(t *testing.T) {
+	var charged = false
+	var positive = func(ctx Context) bool { return ctx.GetRuleContext().Get("amount").(int) > 0 }
+	var hasCurrency = func(ctx Context) bool { return ctx.GetRuleContext().Get("currency") != nil }
+	var charge = NewChainRule().OnExecute(func(Context) { charged = true })
+	var root = NewChainRule().AddChildren(
+		NewAssertRule[ChainRule]("order must be valid", positive, hasCurrency).WithName("valid order").AddChildren(charge),
+	)
+
+	rc := NewRuleContext()
+	rc.Set("amount", 10)
+	rc.Set("currency", "EUR")
+	assert.NoError(t, ChainRuleRunner(rc, root))
+	assert.True(t, charged)
+
+	charged = false
+	rc.Delete("currency")
+	err := ChainRuleRunner(rc, root)
+	assert.EqualError(t, err, `rule "valid order" in assert: assertion failed: order must be valid (invariant 1)`)
+	var assertion *AssertionError
+	assert.ErrorAs(t, err, &assertion)
+	assert.Equal(t, 1, assertion.Invariant)
+	assert.False(t, charged)
+}
+
+func TestNewAssertRule_BestFirst(t *testing.T) {
+	var guard = NewAssertRule[BestFirstRule]("always", func(Context) bool { return true })
+	var fired = false
+
+	assert.NoError(t, BestFirstRuleRunner(NewRuleContext(), guard.AddChildren(
+		NewBestFirstRule().OnExecute(func(Context) { fired = true }),
+		NewBestFirstRule().AddChildren(NewBestFirstRule()),
+	)))
+	assert.True(t, fired)
+}
@@ -0,0 +1,78 @@
+package rule
+
+import "errors"
+
+// WithBacktracking makes best-first runs backtrack: when the subtree of the
+// matching rule fails, because none of the children of a matching rule along
+// it matches or because of an error, the run goes on with the next sibling as
+// if the rule had not matched, instead of committing to the first match.
+//
+// The values, provenance, decisions and reasons written by a failed subtree
+// are rolled back; the history and evaluations keep them. The errors of failed
+// subtrees are only returned, joined, when no sibling succeeds.
+func WithBacktracking() ContextOption {
+	return func(rc *RuleContext) {
+		rc.backtracking = true
+	}
+}
+
+// runBacktracking runs best-first rules until one of them succeeds, rolling
+// back the context after each failure.
+func runBacktracking[T any](rc *RuleContext, rules []*BaseRule[T]) (bool, error) {
+	var failures []error
+	for _, r := range rules {
+		var saved = rc.save()
+		r.SetRuleContext(rc)
+		var next, err = r.fire()
+		if err == nil && !next {
+			return false, nil
+		}
+		rc.rollback(saved)
+		if err != nil {
+			failures = append(failures, err)
+		}
+	}
+	return true, errors.Join(failures...)
+}
+
+// explore runs the children of the matched best-first rule, reporting whether
+// the run should backtrack because none of them succeeded. Leaves succeed.
+func (r *BaseRule[T]) explore() (bool, error) {
+	if len(r.children) == 0 {
+		return false, nil
+	}
+	if r.indexChildren {
+		if child, ok := r.dispatchChild(); ok {
+			if child == nil {
+				return true, nil
+			}
+			child.SetRuleContext(r.GetRuleContext())
+			return child.fire()
+		}
+	}
+	return runRules(BestFirstRuleType, r.GetRuleContext(), r.children)
+}
+
+// savedContext is the state of a context restored when backtracking.
+type savedContext struct {
+	context    map[string]interface{}
+	provenance map[string]Context
+	decisions  int
+	reasons    int
+}
+
+func (rc *RuleContext) save() savedContext {
+	var saved = savedContext{context: copyMap(rc.context), decisions: len(rc.decisions), reasons: len(rc.reasons)}
+	if rc.provenance != nil {
+		saved.provenance = rc.Provenance()
+	}
+	return saved
+}
+
+func (rc *RuleContext) rollback(saved savedContext) {
+	rc.context = saved.context
+	rc.provenance = saved.provenance
+	rc.decisions = rc.decisions[:saved.decisions]
+	rc.reasons = rc.reasons[:saved.reasons]
+	rc.shared = nil
+}
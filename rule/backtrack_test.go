@@ -0,0 +1,103 @@
+package rule
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func routes() []*BaseRule[BestFirstRule] {
+	var set = func(key string, value interface{}) func(Context) {
+		return func(ctx Context) { ctx.GetRuleContext().Set(key, value) }
+	}
+	return []*BaseRule[BestFirstRule]{
+		NewBestFirstRule().WithName("express").OnExecute(set("carrier", "express")).AddChildren(
+			NewBestFirstRule().WithName("small").WhenKeyBetween("weight", 0, 5).OnExecute(set("box", "small")),
+		),
+		NewBestFirstRule().WithName("freight").OnExecute(set("carrier", "freight")).AddChildren(
+			NewBestFirstRule().WithName("pallet").OnExecute(func(ctx Context) {
+				ctx.GetRuleContext().Set("box", "pallet")
+				ctx.GetRuleContext().SetDecision("freight", 1)
+			}),
+		),
+	}
+}
+
+func TestWithBacktracking(t *testing.T) {
+	ruleContext := NewRuleContext()
+	ruleContext.Set("weight", 20)
+	assert.NoError(t, BestFirstRuleRunner(ruleContext, routes()...))
+	assert.Equal(t, map[string]interface{}{"weight": 20, "carrier": "express"}, ruleContext.Values())
+
+	ruleContext = NewRuleContext(WithBacktracking(), WithHistory())
+	ruleContext.Set("weight", 20)
+	assert.NoError(t, BestFirstRuleRunner(ruleContext, routes()...))
+	assert.Equal(t, map[string]interface{}{"weight": 20, "carrier": "freight", "box": "pallet"}, ruleContext.Values())
+	assert.Equal(t, "freight", ruleContext.Provenance()["carrier"].(Named).GetName())
+	assert.Len(t, ruleContext.Decisions(), 1)
+	assert.Len(t, ruleContext.Evaluations(), 4)
+
+	ruleContext = NewRuleContext(WithBacktracking())
+	ruleContext.Set("weight", 2)
+	assert.NoError(t, BestFirstRuleRunner(ruleContext, routes()...))
+	assert.Equal(t, map[string]interface{}{"weight": 2, "carrier": "express", "box": "small"}, ruleContext.Values())
+}
+
+func TestWithBacktracking_Nested(t *testing.T) {
+	var leaf = func(name string, ok bool) *BaseRule[BestFirstRule] {
+		return NewBestFirstRule().WithName(name).
+			OnEval(func(Context) bool { return ok }).
+			OnExecute(func(ctx Context) { ctx.GetRuleContext().Set("path", ctx.(Named).GetName()) })
+	}
+	var rules = []*BaseRule[BestFirstRule]{
+		NewBestFirstRule().WithName("a").AddChildren(
+			NewBestFirstRule().WithName("a1").AddChildren(leaf("a1x", false)),
+			NewBestFirstRule().WithName("a2").AddChildren(leaf("a2x", false), leaf("a2y", true)),
+		),
+		leaf("b", true),
+	}
+
+	ruleContext := NewRuleContext(WithBacktracking())
+	assert.NoError(t, BestFirstRuleRunner(ruleContext, rules...))
+	assert.Equal(t, "a2y", ruleContext.Get("path"))
+
+	rules[0].GetChildren()[1].GetChildren()[1].OnEval(func(Context) bool { return false })
+	ruleContext = NewRuleContext(WithBacktracking())
+	assert.NoError(t, BestFirstRuleRunner(ruleContext, rules...))
+	assert.Equal(t, "b", ruleContext.Get("path"))
+}
+
+func TestWithBacktracking_Errors(t *testing.T) {
+	var failing = func(name string) *BaseRule[BestFirstRule] {
+		return NewBestFirstRule().WithName(name).OnExecuteWithError(func(Context) error { return errors.New("unavailable") })
+	}
+	var fallback = NewBestFirstRule().WithName("fallback").OnExecute(func(ctx Context) { ctx.GetRuleContext().Set("ok", true) })
+
+	ruleContext := NewRuleContext(WithBacktracking())
+	assert.NoError(t, BestFirstRuleRunner(ruleContext, NewBestFirstRule().AddChildren(failing("primary")), fallback))
+	assert.Equal(t, true, ruleContext.Get("ok"))
+
+	var err = BestFirstRuleRunner(NewRuleContext(WithBacktracking()), failing("primary"), failing("secondary"))
+	assert.EqualError(t, err, "rule \"primary\" in execute: unavailable\nrule \"secondary\" in execute: unavailable")
+}
+
+func TestWithBacktracking_DispatchIndex(t *testing.T) {
+	var rules = []*BaseRule[BestFirstRule]{
+		NewBestFirstRule().WithName("indexed").WithDispatchIndex().AddChildren(
+			NewBestFirstRule().WhenKeyIn("country", "de"),
+			NewBestFirstRule().WhenKeyIn("country", "fr"),
+		),
+		NewBestFirstRule().WithName("default").OnExecute(func(ctx Context) { ctx.GetRuleContext().Set("default", true) }),
+	}
+
+	ruleContext := NewRuleContext(WithBacktracking())
+	ruleContext.Set("country", "fr")
+	assert.NoError(t, BestFirstRuleRunner(ruleContext, rules...))
+	assert.Nil(t, ruleContext.Get("default"))
+
+	ruleContext = NewRuleContext(WithBacktracking())
+	ruleContext.Set("country", "us")
+	assert.NoError(t, BestFirstRuleRunner(ruleContext, rules...))
+	assert.Equal(t, true, ruleContext.Get("default"))
+}
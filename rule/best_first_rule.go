@@ -6,7 +6,7 @@ type BestFirstRule struct {
 
 func NewBestFirstRule() *BaseRule[BestFirstRule] {
 	return &BaseRule[BestFirstRule]{
-		ruleType:      bestFirstRuleType,
+		ruleType:      BestFirstRuleType,
 		context:       NewRuleContext(),
 		children:      make([]*BaseRule[BestFirstRule], 0),
 		onEval:        func(r Context) bool { return true },
@@ -23,6 +23,9 @@ func NewBestFirstRule() *BaseRule[BestFirstRule] {
 // Parameters:
 //   - ruleContext: A pointer to the RuleContext in which the rules will be executed.
 //   - rules: A slice of pointers to BestFirstRule objects to be executed.
-func BestFirstRuleRunner[T any](ruleContext *RuleContext, rules ...*BaseRule[T]) {
-	RuleRunner(bestFirstRuleType, ruleContext, rules...)
+//
+// Returns:
+//   - The *RuleError stopping the run, if any.
+func BestFirstRuleRunner[T any](ruleContext *RuleContext, rules ...*BaseRule[T]) error {
+	return RuleRunner(BestFirstRuleType, ruleContext, rules...)
 }
@@ -8,8 +8,8 @@ import (
 
 func TestNewBestFirstRule(t *testing.T) {
 	rule := NewBestFirstRule()
-	if rule.ruleType != bestFirstRuleType {
-		t.Errorf("Expected ruleType to be %v, got %v", bestFirstRuleType, rule.ruleType)
+	if rule.ruleType != BestFirstRuleType {
+		t.Errorf("Expected ruleType to be %v, got %v", BestFirstRuleType, rule.ruleType)
 	}
 	if rule.context == nil {
 		t.Error("Expected context to be initialized, got nil")
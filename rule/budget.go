@@ -0,0 +1,60 @@
+package rule
+
+// Cost annotates the rule with an abstract cost, such as its latency or the
+// price of the services it calls, charged to the run budget every time the
+// rule is fired.
+func (r *BaseRule[T]) Cost(cost float64) *BaseRule[T] {
+	r.cost = cost
+	return r
+}
+
+// Optional marks the rule as optional: it is skipped, as if its OnEval returned
+// false, when the run cannot afford it. Rules are required by default and
+// always run, even over budget.
+func (r *BaseRule[T]) Optional() *BaseRule[T] {
+	r.optional = true
+	return r
+}
+
+// WithBudget limits the total cost of the rules fired in the RuleContext.
+// Optional rules whose cost exceeds the remaining budget are skipped.
+func WithBudget(budget float64) ContextOption {
+	return func(rc *RuleContext) {
+		rc.budget = &budget
+	}
+}
+
+// Spent returns the total cost of the rules fired so far.
+func (rc *RuleContext) Spent() float64 {
+	return rc.spent
+}
+
+// Skipped returns the optional rules skipped during the run, in order, for
+// exceeding the budget or the soft deadline.
+func (rc *RuleContext) Skipped() []Context {
+	return append([]Context(nil), rc.skipped...)
+}
+
+func (rc *RuleContext) admit(r Context, cost float64, optional bool) bool {
+	if rc.share != nil {
+		rc.share.mu.Lock()
+		defer rc.share.mu.Unlock()
+		rc.spent = rc.share.spent
+	}
+	if !rc.affords(cost, optional) {
+		rc.skipped = append(rc.skipped, r)
+		return false
+	}
+	rc.spent += cost
+	if rc.share != nil {
+		rc.share.spent = rc.spent
+	}
+	return true
+}
+
+// affords reports whether admit would let a rule of the given cost run,
+// without charging it.
+func (rc *RuleContext) affords(cost float64, optional bool) bool {
+	var deadline = rc.pastDeadline()
+	return !optional || !deadline && (rc.budget == nil || rc.spent+cost <= *rc.budget)
+}
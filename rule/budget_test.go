@@ -0,0 +1,53 @@
+package rule
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRuleContext_Budget(t *testing.T) {
+	var fired []string
+	var rule = func(name string, cost float64) *BaseRule[ChainRule] {
+		return NewChainRule().WithName(name).Cost(cost).
+			OnEval(func(Context) bool { return true }).
+			OnExecute(func(Context) { fired = append(fired, name) })
+	}
+
+	var enrich = rule("enrich", 5).Optional()
+	var score = rule("score", 4)
+	var lookup = rule("lookup", 3).Optional()
+	var decide = rule("decide", 1)
+	rc := NewRuleContext(WithBudget(10))
+	ChainRuleRunner(rc, score.AddChildren(enrich.AddChildren(lookup.AddChildren(decide))))
+
+	assert.Equal(t, []string{"score", "enrich"}, fired)
+	assert.Equal(t, 9.0, rc.Spent())
+	assert.Equal(t, []Context{lookup}, rc.Skipped())
+}
+
+func TestRuleContext_BudgetSkipsToNextSibling(t *testing.T) {
+	var fired []string
+	var rule = func(name string, cost float64) *BaseRule[BestFirstRule] {
+		return NewBestFirstRule().WithName(name).Cost(cost).
+			OnEval(func(Context) bool { return true }).
+			OnExecute(func(Context) { fired = append(fired, name) })
+	}
+
+	rc := NewRuleContext(WithBudget(2))
+	BestFirstRuleRunner(rc, rule("expensive", 10).Optional(), rule("cheap", 1).Optional())
+
+	assert.Equal(t, []string{"cheap"}, fired)
+}
+
+func TestRuleContext_RequiredRulesIgnoreBudget(t *testing.T) {
+	var fired = false
+	rc := NewRuleContext(WithBudget(1))
+	ChainRuleRunner(rc, NewChainRule().Cost(5).
+		OnEval(func(Context) bool { return true }).
+		OnExecute(func(Context) { fired = true }))
+
+	assert.True(t, fired)
+	assert.Equal(t, 5.0, rc.Spent())
+	assert.Empty(t, rc.Skipped())
+}
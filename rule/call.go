@@ -0,0 +1,323 @@
+package rule
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// Registry holds named programs that call rules can invoke. It is safe for
+// concurrent use.
+type Registry struct {
+	mu       sync.RWMutex
+	programs map[string]Program
+	lazy     map[string]*lazyProgram
+}
+
+// NewRegistry creates an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{programs: make(map[string]Program), lazy: make(map[string]*lazyProgram)}
+}
+
+// Register adds a program under the given name. It panics if the name is
+// already registered.
+func (r *Registry) Register(name string, program Program) *Registry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.programs[name]; ok {
+		panic(fmt.Sprintf("program %q is already registered", name))
+	}
+	r.programs[name] = program
+	return r
+}
+
+// RegisterCompiler adds a program under the given name, compiled by compile,
+// such as a function decoding a rule set and passing it to NewProgram. The
+// program is compiled on its first run, once even when several runs start at
+// the same time; a failed compilation fails the run and is retried by the
+// next one. Call Preload at startup to compile instead all the programs
+// upfront and fail fast. It panics if the name is already registered.
+func (r *Registry) RegisterCompiler(name string, compile func() (Program, error)) *Registry {
+	var l = &lazyProgram{compile: compile}
+	r.Register(name, func(rc *RuleContext) error {
+		program, err := l.get()
+		if err != nil {
+			return fmt.Errorf("compile program %q: %w", name, err)
+		}
+		return program(rc)
+	})
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.lazy[name] = l
+	return r
+}
+
+// Preload compiles the programs registered with RegisterCompiler that are not
+// compiled yet, and returns the errors of those that fail to.
+func (r *Registry) Preload() error {
+	r.mu.RLock()
+	var lazy = make(map[string]*lazyProgram, len(r.lazy))
+	var names = make([]string, 0, len(r.lazy))
+	for name, l := range r.lazy {
+		lazy[name] = l
+		names = append(names, name)
+	}
+	r.mu.RUnlock()
+	sort.Strings(names)
+
+	var errs []error
+	for _, name := range names {
+		if _, err := lazy[name].get(); err != nil {
+			errs = append(errs, fmt.Errorf("compile program %q: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// lazyProgram is a program compiled on first use.
+type lazyProgram struct {
+	mu      sync.Mutex
+	compile func() (Program, error)
+	program atomic.Pointer[Program]
+}
+
+func (l *lazyProgram) get() (Program, error) {
+	if program := l.program.Load(); program != nil {
+		return *program, nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if program := l.program.Load(); program != nil {
+		return *program, nil
+	}
+	program, err := l.compile()
+	if err != nil {
+		return nil, err
+	}
+	l.program.Store(&program)
+	return program, nil
+}
+
+// Lookup returns the program registered under the given name.
+func (r *Registry) Lookup(name string) (Program, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	program, ok := r.programs[name]
+	return program, ok
+}
+
+// Names returns the sorted names of the registered programs.
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var names = make([]string, 0, len(r.programs))
+	for name := range r.programs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+type call struct {
+	inputs    []string
+	outputs   []string
+	inputMap  MapKeys
+	outputMap MapKeys
+	namespace string
+}
+
+// MapKeys renames context keys, from the key on the left to the key on the
+// right, so rule sets written with different key names can interoperate.
+type MapKeys map[string]string
+
+// Rename returns a copy of values with the mapped keys renamed. Keys that are
+// not in the mapping keep their name.
+func (m MapKeys) Rename(values map[string]interface{}) map[string]interface{} {
+	var renamed = make(map[string]interface{}, len(values))
+	for k, v := range values {
+		if to, ok := m[k]; ok {
+			k = to
+		}
+		renamed[k] = v
+	}
+	return renamed
+}
+
+// Inverse returns the mapping renaming keys back to their original name.
+func (m MapKeys) Inverse() MapKeys {
+	var inverse = make(MapKeys, len(m))
+	for from, to := range m {
+		inverse[to] = from
+	}
+	return inverse
+}
+
+// CallOption configures how a call rule maps the context in and out of the
+// program it invokes.
+type CallOption func(*call)
+
+// CallInputs restricts the values passed to the program to the given keys.
+// By default every value of the context is passed.
+func CallInputs(keys ...string) CallOption {
+	return func(c *call) {
+		c.inputs = append(c.inputs, keys...)
+	}
+}
+
+// CallOutputs restricts the values copied back from the program to the given
+// keys, as named by the program. By default every value the program added or
+// changed is copied back.
+func CallOutputs(keys ...string) CallOption {
+	return func(c *call) {
+		c.outputs = append(c.outputs, keys...)
+	}
+}
+
+// CallInputMap renames the values passed to the program, from the keys of the
+// calling context to the keys the program reads.
+func CallInputMap(m MapKeys) CallOption {
+	return func(c *call) {
+		c.inputMap = m
+	}
+}
+
+// CallOutputMap renames the values copied back from the program, from the keys
+// the program writes to the keys of the calling context.
+func CallOutputMap(m MapKeys) CallOption {
+	return func(c *call) {
+		c.outputMap = m
+	}
+}
+
+// CallNamespace prefixes the keys copied back from the program with the
+// namespace and a dot, after CallOutputMap renamed them. The call fails rather
+// than overwrite a value of the calling context that was not written by the
+// call rule itself, so composed rule sets can't clobber each other's outputs.
+func CallNamespace(namespace string) CallOption {
+	return func(c *call) {
+		c.namespace = namespace
+	}
+}
+
+// NewCallRule creates a rule invoking the program registered under the given
+// name as a step of the tree. The program runs in a new RuleContext holding
+// the input values and sharing the settings of the run, and its outputs,
+// decisions and reasons are written back to the calling context before the
+// children of the rule run. The program is looked up when the rule
+// executes; an unknown name or an error of the program stops the run.
+//
+// T is the kind of the tree the rule is part of, such as ChainRule or BestFirstRule.
+func NewCallRule[T any](registry *Registry, name string, opts ...CallOption) *BaseRule[T] {
+	var c = &call{}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	var r = &BaseRule[T]{
+		name:          name,
+		ruleType:      ruleTypeOf[T](),
+		context:       NewRuleContext(),
+		onEval:        func(r Context) bool { return true },
+		onPreExecute:  func(r Context) {},
+		onExecute:     func(r Context) {},
+		onPostExecute: func(r Context) {},
+	}
+	r.onExecuteErr = func(ctx Context) error {
+		var program, ok = registry.Lookup(name)
+		if !ok {
+			return fmt.Errorf("program %q is not registered", name)
+		}
+		return c.invoke(ctx.GetRuleContext(), program)
+	}
+	r.nameHook(PhaseExecute, "")
+	return r
+}
+
+// callContext returns the context a called program runs in. It shares the
+// settings of the run with the calling context: clock, run ID, strict keys,
+// GoContext, global values, defaults, tracer, and the budget and soft deadline
+// left.
+func (rc *RuleContext) callContext() *RuleContext {
+	rc.pastDeadline()
+	var sub = NewRuleContext(WithClock(rc.clock), WithRunID(rc.runID))
+	sub.strict = rc.strict
+	sub.goContext = rc.goContext
+	sub.global = rc.global
+	sub.defaults = rc.defaults
+	sub.tracer = rc.tracer
+	sub.budget = rc.budget
+	sub.spent = rc.spent
+	sub.share = rc.share
+	sub.softDeadline = rc.softDeadline
+	sub.deadline = rc.deadline
+	sub.degrade = rc.degrade
+	sub.degraded = rc.degraded
+	return sub
+}
+
+func (c *call) invoke(rc *RuleContext, program Program) error {
+	var input = make(map[string]interface{})
+	if c.inputs == nil {
+		input = rc.Values()
+	} else {
+		for _, k := range c.inputs {
+			if v, ok := rc.lookup(k); ok {
+				input[k] = v
+			}
+		}
+	}
+	input = c.inputMap.Rename(input)
+
+	var sub = rc.callContext()
+	for k, v := range input {
+		sub.Set(k, v)
+	}
+	var err = program(sub)
+	rc.spent = sub.spent
+	rc.degraded = rc.degraded || sub.degraded
+	if err != nil {
+		return err
+	}
+	rc.reasons = append(rc.reasons, sub.reasons...)
+	rc.decisions = append(rc.decisions, sub.decisions...)
+
+	var output = make(map[string]interface{})
+	if c.outputs == nil {
+		for k, v := range sub.context {
+			if before, ok := input[k]; !ok || !reflect.DeepEqual(before, v) {
+				output[k] = v
+			}
+		}
+	} else {
+		for _, k := range c.outputs {
+			if v, ok := sub.context[k]; ok {
+				output[k] = v
+			}
+		}
+	}
+	output = c.outputMap.Rename(output)
+	if c.namespace != "" {
+		var namespaced = make(map[string]interface{}, len(output))
+		for k, v := range output {
+			var key = c.namespace + "." + k
+			if _, exists := rc.context[key]; exists && rc.provenance[key] != rc.current {
+				return fmt.Errorf("namespaced key %q collides with a value not written by the call", key)
+			}
+			namespaced[key] = v
+		}
+		output = namespaced
+	}
+
+	var keys = make([]string, 0, len(output))
+	for k := range output {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		rc.Set(k, output[k])
+	}
+	return nil
+}
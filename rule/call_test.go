@@ -0,0 +1,186 @@
+package rule
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func scoring(rc *RuleContext) error {
+	return ChainRuleRunner(rc, NewChainRule().OnExecute(func(ctx Context) {
+		var rc = ctx.GetRuleContext()
+		rc.Set("score", rc.Get("amount").(int)/10)
+		rc.Set("internal", true)
+	}))
+}
+
+func TestNewCallRule(t *testing.T) {
+	var registry = NewRegistry().Register("scoring", scoring)
+	var after interface{}
+	var call = NewCallRule[ChainRule](registry, "scoring").AddChildren(
+		NewChainRule().OnExecute(func(ctx Context) { after = ctx.GetRuleContext().Get("score") }),
+	)
+
+	rc := NewRuleContext()
+	rc.Set("amount", 50)
+	assert.NoError(t, ChainRuleRunner(rc, call))
+
+	assert.Equal(t, map[string]interface{}{"amount": 50, "score": 5, "internal": true}, rc.Values())
+	assert.Equal(t, 5, after)
+	assert.Equal(t, call, rc.Provenance()["score"])
+}
+
+func TestNewCallRule_Mapping(t *testing.T) {
+	var seen map[string]interface{}
+	var registry = NewRegistry().Register("scoring", func(rc *RuleContext) error {
+		seen = rc.Values()
+		return scoring(rc)
+	})
+
+	rc := NewRuleContext()
+	rc.Set("amount", 50)
+	rc.Set("card", "4111")
+	assert.NoError(t, ChainRuleRunner(rc, NewCallRule[ChainRule](registry, "scoring", CallInputs("amount"), CallOutputs("score"))))
+
+	assert.Equal(t, map[string]interface{}{"amount": 50}, seen)
+	assert.Equal(t, map[string]interface{}{"amount": 50, "card": "4111", "score": 5}, rc.Values())
+}
+
+func TestNewCallRule_Errors(t *testing.T) {
+	var cause = errors.New("boom")
+	var registry = NewRegistry().Register("failing", func(*RuleContext) error { return cause })
+
+	assert.ErrorIs(t, BestFirstRuleRunner(NewRuleContext(), NewCallRule[BestFirstRule](registry, "failing")), cause)
+	assert.EqualError(t, BestFirstRuleRunner(NewRuleContext(), NewCallRule[BestFirstRule](registry, "missing")),
+		`rule "missing" in execute: program "missing" is not registered`)
+	assert.Panics(t, func() { registry.Register("failing", scoring) })
+	assert.Equal(t, []string{"failing"}, registry.Names())
+}
+
+func TestNewCallRule_MapKeys(t *testing.T) {
+	var registry = NewRegistry().Register("scoring", scoring)
+
+	rc := NewRuleContext()
+	rc.Set("order.total", 80)
+	assert.NoError(t, ChainRuleRunner(rc, NewCallRule[ChainRule](registry, "scoring",
+		CallInputMap(MapKeys{"order.total": "amount"}),
+		CallOutputs("score"),
+		CallOutputMap(MapKeys{"score": "order.score"}),
+	)))
+
+	assert.Equal(t, map[string]interface{}{"order.total": 80, "order.score": 8}, rc.Values())
+}
+
+func TestMapKeys(t *testing.T) {
+	var m = MapKeys{"order.total": "amount"}
+
+	assert.Equal(t, map[string]interface{}{"amount": 1, "currency": "EUR"}, m.Rename(map[string]interface{}{"order.total": 1, "currency": "EUR"}))
+	assert.Equal(t, MapKeys{"amount": "order.total"}, m.Inverse())
+	assert.Equal(t, map[string]interface{}{"a": 1}, MapKeys(nil).Rename(map[string]interface{}{"a": 1}))
+}
+
+func TestNewCallRule_Namespace(t *testing.T) {
+	var registry = NewRegistry().Register("scoring", scoring)
+	var call = func() *BaseRule[ChainRule] {
+		return NewCallRule[ChainRule](registry, "scoring", CallOutputs("score"), CallNamespace("fraud"))
+	}
+
+	rc := NewRuleContext()
+	rc.Set("amount", 50)
+	var first = call()
+	assert.NoError(t, ChainRuleRunner(rc, first))
+	assert.Equal(t, 5, rc.Get("fraud.score"))
+	assert.NoError(t, ChainRuleRunner(rc, first))
+
+	err := ChainRuleRunner(rc, call().WithName("other"))
+	assert.EqualError(t, err, `rule "other" in execute: namespaced key "fraud.score" collides with a value not written by the call`)
+
+	rc = NewRuleContext()
+	rc.Set("amount", 50)
+	rc.Set("fraud.score", 0)
+	assert.Error(t, ChainRuleRunner(rc, call()))
+	assert.Equal(t, 0, rc.Get("fraud.score"))
+}
+
+func TestNewCallRule_GoContext(t *testing.T) {
+	type traceKey struct{}
+	var traceID interface{}
+	var registry = NewRegistry().Register("tracing", func(rc *RuleContext) error {
+		traceID = rc.GoContext().Value(traceKey{})
+		return nil
+	})
+
+	var ctx = context.WithValue(context.Background(), traceKey{}, "trace-1")
+	assert.NoError(t, ChainRuleRunner(NewRuleContext(WithGoContext(ctx)), NewCallRule[ChainRule](registry, "tracing")))
+	assert.Equal(t, "trace-1", traceID)
+}
+
+func TestNewCallRule_RunSettings(t *testing.T) {
+	var registry = NewRegistry().Register("limits", func(rc *RuleContext) error {
+		return ChainRuleRunner(rc, NewChainRule().Cost(3).OnExecute(func(ctx Context) {
+			var rc = ctx.GetRuleContext()
+			rc.Set("over", rc.Get("amount").(int) > rc.Get("limit").(int))
+			rc.AddReason("LIMIT_CHECKED")
+			rc.SetDecision("review", 0.8)
+		}))
+	})
+
+	rc := NewRuleContext(WithStrictKeys(), WithGlobal(NewGlobalContext(map[string]interface{}{"limit": 100})), WithBudget(10))
+	rc.Set("amount", 150)
+	assert.NoError(t, ChainRuleRunner(rc, NewCallRule[ChainRule](registry, "limits").Cost(2)))
+
+	assert.Equal(t, true, rc.Get("over"))
+	decision, ok := rc.Decision()
+	assert.True(t, ok)
+	assert.Equal(t, "review", decision.Value)
+	assert.Equal(t, "LIMIT_CHECKED", rc.Reasons()[0].Code)
+	assert.Equal(t, 5.0, rc.Spent())
+}
+
+func TestRegistry_RegisterCompiler(t *testing.T) {
+	var compiled atomic.Int32
+	var registry = NewRegistry().RegisterCompiler("scoring", func() (Program, error) {
+		compiled.Add(1)
+		return scoring, nil
+	})
+	assert.Equal(t, int32(0), compiled.Load())
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rc := NewRuleContext()
+			rc.Set("amount", 50)
+			assert.NoError(t, ChainRuleRunner(rc, NewCallRule[ChainRule](registry, "scoring")))
+			assert.Equal(t, 5, rc.Get("score"))
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), compiled.Load())
+	assert.NoError(t, registry.Preload())
+	assert.Equal(t, int32(1), compiled.Load())
+}
+
+func TestRegistry_Preload(t *testing.T) {
+	var cause = errors.New("bad rule set")
+	var attempts int
+	var registry = NewRegistry().
+		RegisterCompiler("broken", func() (Program, error) {
+			attempts++
+			return nil, cause
+		}).
+		RegisterCompiler("scoring", func() (Program, error) { return scoring, nil })
+
+	var err = registry.Preload()
+	assert.ErrorIs(t, err, cause)
+	assert.EqualError(t, err, `compile program "broken": bad rule set`)
+
+	assert.EqualError(t, ChainRuleRunner(NewRuleContext(), NewCallRule[ChainRule](registry, "broken")),
+		`rule "broken" in execute: compile program "broken": bad rule set`)
+	assert.Equal(t, 2, attempts)
+}
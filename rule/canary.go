@@ -0,0 +1,84 @@
+package rule
+
+import (
+	"fmt"
+	"hash/fnv"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// WithRunID identifies the run of the RuleContext. Canary rules use it to
+// decide deterministically whether the run is part of their rollout.
+func WithRunID(id string) ContextOption {
+	return func(rc *RuleContext) {
+		rc.runID = id
+	}
+}
+
+// Canary makes the rule eligible for only the given percentage of runs, between
+// 0 and 100; in the other runs it is skipped as if its OnEval returned false.
+//
+// Runs are picked by hashing the rule name, or its path when it is unnamed,
+// with the context run ID, set with WithRunID. Without one, the scalar values
+// of the context, as the first canary rule of the run finds them, stand for
+// it. The same run always gets the same outcome and raising the percentage
+// only adds runs. Unnamed roots share their path, "", so name them to roll
+// them out independently.
+func (r *BaseRule[T]) Canary(percent float64) *BaseRule[T] {
+	if percent < 0 || percent > 100 {
+		panic(fmt.Sprintf("canary percentage %v is not between 0 and 100", percent))
+	}
+	r.canary = &percent
+	return r
+}
+
+func (r *BaseRule[T]) inCanary() bool {
+	if r.canary == nil {
+		return true
+	}
+
+	var salt = r.name
+	if salt == "" {
+		salt = r.Path()
+	}
+	var h = fnv.New64a()
+	fmt.Fprintf(h, "%s\x00", salt)
+	if rc := r.GetRuleContext(); rc != nil {
+		h.Write([]byte(rc.runKey()))
+	}
+	return float64(h.Sum64()%10000) < *r.canary*100
+}
+
+// runKey returns the run ID or, without one, a key made of the scalar values
+// of the context, computed once per run so that the writes of the run don't
+// move it to another bucket. Other values, such as pointers, don't format the
+// same from one run to the next and are left out.
+func (rc *RuleContext) runKey() string {
+	if rc.runID != "" {
+		return rc.runID
+	}
+	if rc.canaryKey != "" {
+		return rc.canaryKey
+	}
+
+	var keys = make([]string, 0, len(rc.context))
+	for k := range rc.context {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	b.WriteString("values\x00")
+	for _, k := range keys {
+		var v = rc.context[k]
+		switch reflect.ValueOf(v).Kind() {
+		case reflect.Bool, reflect.String,
+			reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+			reflect.Float32, reflect.Float64:
+			fmt.Fprintf(&b, "%s=%T(%v)\x00", k, v, v)
+		}
+	}
+	rc.canaryKey = b.String()
+	return rc.canaryKey
+}
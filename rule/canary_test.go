@@ -0,0 +1,90 @@
+package rule
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func canaryRuns(percent float64, runs int) int {
+	var fired = 0
+	for i := 0; i < runs; i++ {
+		rule := NewChainRule().WithName("risky").Canary(percent).
+			OnEval(func(Context) bool { return true }).
+			OnExecute(func(Context) { fired++ })
+		ChainRuleRunner(NewRuleContext(WithRunID(fmt.Sprint("run-", i))), rule)
+	}
+	return fired
+}
+
+func TestBaseRule_Canary(t *testing.T) {
+	assert.Equal(t, 0, canaryRuns(0, 1000))
+	assert.Equal(t, 1000, canaryRuns(100, 1000))
+	assert.InDelta(t, 250, canaryRuns(25, 1000), 50)
+}
+
+func TestBaseRule_CanaryIsDeterministic(t *testing.T) {
+	var eligible = func(percent float64, ctx *RuleContext) bool {
+		rule := NewChainRule().WithName("risky").Canary(percent).OnEval(func(Context) bool { return true })
+		rule.SetRuleContext(ctx)
+		return rule.eval()
+	}
+
+	for i := 0; i < 100; i++ {
+		var id = fmt.Sprint("run-", i)
+		var first = eligible(30, NewRuleContext(WithRunID(id)))
+		assert.Equal(t, first, eligible(30, NewRuleContext(WithRunID(id))))
+		if first {
+			assert.True(t, eligible(60, NewRuleContext(WithRunID(id))))
+		}
+	}
+
+	var input = NewRuleContext()
+	input.Set("user", 42)
+	assert.Equal(t, eligible(50, input), eligible(50, input))
+}
+
+func TestBaseRule_CanaryRunKey(t *testing.T) {
+	var eligible = func(r *BaseRule[ChainRule], ctx *RuleContext) bool {
+		r.SetRuleContext(ctx)
+		return r.eval()
+	}
+	var risky = NewChainRule().WithName("risky").Canary(50).OnEval(func(Context) bool { return true })
+
+	for i := 0; i < 100; i++ {
+		var input = NewRuleContext()
+		input.Set("user", i)
+		input.Set("session", &struct{ id int }{i})
+		var first = eligible(risky, input)
+		input.Set("decision", "approve")
+		assert.Equal(t, first, eligible(risky, input))
+
+		var again = NewRuleContext()
+		again.Set("user", i)
+		again.Set("session", &struct{ id int }{i})
+		assert.Equal(t, first, eligible(risky, again))
+	}
+}
+
+func TestBaseRule_CanaryUnnamed(t *testing.T) {
+	var root = NewAllMatchRule()
+	for i := 0; i < 20; i++ {
+		root.AddChildren(NewAllMatchRule().Canary(50).OnEval(func(Context) bool { return true }))
+	}
+
+	var in = 0
+	for _, child := range root.children {
+		child.SetRuleContext(NewRuleContext(WithRunID("run")))
+		if child.eval() {
+			in++
+		}
+	}
+	assert.Greater(t, in, 0)
+	assert.Less(t, in, 20)
+}
+
+func TestBaseRule_CanaryInvalidPercent(t *testing.T) {
+	assert.Panics(t, func() { NewChainRule().Canary(101) })
+	assert.Panics(t, func() { NewChainRule().Canary(-1) })
+}
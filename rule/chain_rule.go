@@ -6,7 +6,7 @@ type ChainRule struct {
 
 func NewChainRule() *BaseRule[ChainRule] {
 	return &BaseRule[ChainRule]{
-		ruleType:      chainRuleType,
+		ruleType:      ChainRuleType,
 		context:       NewRuleContext(),
 		children:      make([]*BaseRule[ChainRule], 0),
 		onEval:        func(r Context) bool { return true },
@@ -17,15 +17,17 @@ func NewChainRule() *BaseRule[ChainRule] {
 }
 
 // ChainRuleRunner executes a chain of rules within a given rule context.
-// It expects a non-empty slice of ChainRule pointers, but will panic if more than one rule is provided.
+// It expects a slice of at most one ChainRule pointer.
 // The function sets the rule context for the provided rule and then fires the rule.
 //
 // Parameters:
 //   - ruleContext: A pointer to the RuleContext in which the rules will be executed.
 //   - rules: A slice of pointers to ChainRule. Must contain exactly one rule.
 //
-// Panics:
-//   - If the length of the rules slice is greater than one.
-func ChainRuleRunner[T any](ruleContext *RuleContext, rules ...*BaseRule[T]) {
-	RuleRunner(chainRuleType, ruleContext, rules...)
+// Returns:
+//   - The *RuleError stopping the run, if any. When more than one rule is
+//     provided, a *RuleError naming the second one and wrapping
+//     ErrTooManyChildren, without running any.
+func ChainRuleRunner[T any](ruleContext *RuleContext, rules ...*BaseRule[T]) error {
+	return RuleRunner(ChainRuleType, ruleContext, rules...)
 }
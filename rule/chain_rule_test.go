@@ -1,6 +1,7 @@
 package rule
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -8,8 +9,8 @@ import (
 
 func TestNewChainRule(t *testing.T) {
 	rule := NewChainRule()
-	if rule.ruleType != chainRuleType {
-		t.Errorf("Expected ruleType to be %v, got %v", chainRuleType, rule.ruleType)
+	if rule.ruleType != ChainRuleType {
+		t.Errorf("Expected ruleType to be %v, got %v", ChainRuleType, rule.ruleType)
 	}
 	if rule.context == nil {
 		t.Error("Expected context to be initialized")
@@ -42,12 +43,9 @@ func TestChainRuleRunner(t *testing.T) {
 	}
 
 	// Test with more than one rule
-	defer func() {
-		if r := recover(); r == nil {
-			t.Error("Expected panic but did not get one")
-		}
-	}()
-	ChainRuleRunner(ruleContext, rule, rule)
+	if err := ChainRuleRunner(ruleContext, rule, rule); !errors.Is(err, ErrTooManyChildren) {
+		t.Errorf("Expected ErrTooManyChildren, got %v", err)
+	}
 }
 
 func TestChainRuleContext(t *testing.T) {
@@ -162,13 +160,18 @@ func TestChainRule_ShouldNotRunChildOnEvalFalse(t *testing.T) {
 	})
 }
 
-func TestChainRule_ShouldPanicWhenProvidingSiblingRuleToRunner(t *testing.T) {
-	rule := NewChainRule()
-	rule2 := NewChainRule()
-
-	assert.PanicsWithValue(t, "ChainRuleRunner only supports one rule", func() {
-		ChainRuleRunner(NewRuleContext(), rule, rule2)
-	})
+func TestChainRule_ShouldFailWhenProvidingSiblingRuleToRunner(t *testing.T) {
+	var executed = false
+	rule := NewChainRule().OnExecute(func(Context) { executed = true })
+	rule2 := NewChainRule().WithName("rule_2")
+
+	var err = ChainRuleRunner(NewRuleContext(), rule, rule2)
+	assert.ErrorIs(t, err, ErrTooManyChildren)
+	var ruleErr *RuleError
+	assert.ErrorAs(t, err, &ruleErr)
+	assert.Equal(t, Context(rule2), ruleErr.Rule)
+	assert.EqualError(t, err, `rule "rule_2": ChainRule can only have one child`)
+	assert.False(t, executed)
 }
 
 func TestChainRule_ShouldPanicWhenProvidingSiblingRulesToRule(t *testing.T) {
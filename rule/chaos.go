@@ -0,0 +1,165 @@
+package rule
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// ErrInjectedFault is the error of the execute hooks failed by a Chaos.
+var ErrInjectedFault = errors.New("injected fault")
+
+// FaultKind is the kind of a fault injected by a Chaos.
+type FaultKind int
+
+const (
+	// FaultError fails an execute hook with ErrInjectedFault instead of running it.
+	FaultError FaultKind = iota
+	// FaultDelay delays an execute hook.
+	FaultDelay
+	// FaultCancel cancels the GoContext of the run before an execute hook.
+	FaultCancel
+)
+
+func (k FaultKind) String() string {
+	switch k {
+	case FaultError:
+		return "error"
+	case FaultDelay:
+		return "delay"
+	case FaultCancel:
+		return "cancel"
+	}
+	return fmt.Sprintf("FaultKind(%d)", int(k))
+}
+
+// Fault is a fault injected by a Chaos in the rule at Path.
+type Fault struct {
+	Kind FaultKind
+	Path string
+}
+
+// Chaos injects random faults in the execute hooks of the programs it wraps,
+// to test how rule sets behave under failure, such as whether fallback rules
+// or retries recover from errors. Each kind of fault is injected with its own
+// probability before every execute hook. It is meant for tests and is safe
+// for concurrent use.
+type Chaos struct {
+	mu         sync.Mutex
+	rand       func() float64
+	errorRate  float64
+	delayRate  float64
+	delay      time.Duration
+	cancelRate float64
+	paths      map[string]bool
+	faults     []Fault
+}
+
+// NewChaos creates a Chaos injecting no faults until configured.
+func NewChaos() *Chaos {
+	return &Chaos{rand: rand.Float64}
+}
+
+// Errors fails the given fraction of execute hooks, between 0 and 1, with
+// ErrInjectedFault, as if the hook had returned it.
+func (c *Chaos) Errors(rate float64) *Chaos {
+	c.errorRate = rate
+	return c
+}
+
+// Delays delays the given fraction of execute hooks by d, or until the
+// GoContext of the run is canceled. The delay passes on the Clock of the run:
+// on a SimClock, the clock is advanced by d instead of waiting, so delays
+// exceed soft deadlines without slowing simulations down.
+func (c *Chaos) Delays(rate float64, d time.Duration) *Chaos {
+	c.delayRate = rate
+	c.delay = d
+	return c
+}
+
+// Cancellations cancels the GoContext of the run before the given fraction of
+// execute hooks. The hooks still run and see the canceled context.
+func (c *Chaos) Cancellations(rate float64) *Chaos {
+	c.cancelRate = rate
+	return c
+}
+
+// Only restricts the faults to the rules at the given paths, as returned by
+// Path. Faults are injected in every rule by default.
+func (c *Chaos) Only(paths ...string) *Chaos {
+	c.paths = make(map[string]bool, len(paths))
+	for _, p := range paths {
+		c.paths[p] = true
+	}
+	return c
+}
+
+// WithRand sets the source of random numbers in [0, 1) deciding which faults
+// are injected, for reproducible runs.
+func (c *Chaos) WithRand(f func() float64) *Chaos {
+	c.rand = f
+	return c
+}
+
+// Faults returns the faults injected so far, in order.
+func (c *Chaos) Faults() []Fault {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]Fault(nil), c.faults...)
+}
+
+// Wrap returns a Program running program with faults injected.
+func (c *Chaos) Wrap(program Program) Program {
+	return func(rc *RuleContext) error {
+		var ctx, cancel = context.WithCancel(rc.GoContext())
+		defer cancel()
+
+		var goContext, chaos = rc.goContext, rc.chaos
+		rc.goContext = ctx
+		rc.chaos = &chaosRun{chaos: c, cancel: cancel}
+		defer func() { rc.goContext, rc.chaos = goContext, chaos }()
+		return program(rc)
+	}
+}
+
+// chaosRun is a run of a program wrapped by a Chaos.
+type chaosRun struct {
+	chaos  *Chaos
+	cancel context.CancelFunc
+}
+
+// inject injects the faults drawn for the execute hook of the rule at path.
+func (run *chaosRun) inject(rc *RuleContext, path string) error {
+	var c = run.chaos
+	if c.paths != nil && !c.paths[path] {
+		return nil
+	}
+
+	c.mu.Lock()
+	var cancel = c.rand() < c.cancelRate
+	var delay = c.rand() < c.delayRate
+	var fail = c.rand() < c.errorRate
+	for _, f := range []struct {
+		kind     FaultKind
+		injected bool
+	}{{FaultCancel, cancel}, {FaultDelay, delay}, {FaultError, fail}} {
+		if f.injected {
+			c.faults = append(c.faults, Fault{Kind: f.kind, Path: path})
+		}
+	}
+	c.mu.Unlock()
+
+	if cancel {
+		run.cancel()
+	}
+	if delay {
+		rc.sleep(rc.GoContext(), c.delay)
+	}
+	if fail {
+		return ErrInjectedFault
+	}
+	return nil
+}
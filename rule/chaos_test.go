@@ -0,0 +1,85 @@
+package rule
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChaos_Errors(t *testing.T) {
+	var set = func(key string) func(Context) {
+		return func(ctx Context) { ctx.GetRuleContext().Set(key, true) }
+	}
+	var program = NewProgram(
+		NewFallbackRule().WithName("primary").OnExecute(set("primary")),
+		NewFallbackRule().WithName("secondary").OnExecute(set("secondary")),
+	)
+
+	var chaos = NewChaos().Errors(1).Only("primary")
+	ruleContext := NewRuleContext()
+	assert.NoError(t, chaos.Wrap(program)(ruleContext))
+	assert.Equal(t, map[string]interface{}{"secondary": true}, ruleContext.Values())
+	assert.Equal(t, []Fault{{Kind: FaultError, Path: "primary"}}, chaos.Faults())
+
+	var err = NewChaos().Errors(1).Wrap(program)(NewRuleContext())
+	assert.ErrorIs(t, err, ErrInjectedFault)
+}
+
+func TestChaos_Cancellations(t *testing.T) {
+	var loop = NewLoopRule[ChainRule](10).WithName("poll")
+	var ctx = context.WithValue(context.Background(), traceKey{}, "trace-1")
+	ruleContext := NewRuleContext(WithGoContext(ctx))
+
+	var err = NewChaos().Cancellations(1).Wrap(NewProgram(loop))(ruleContext)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, ctx, ruleContext.GoContext())
+	assert.NoError(t, ruleContext.GoContext().Err())
+}
+
+type traceKey struct{}
+
+func TestChaos_Delays(t *testing.T) {
+	var program = NewProgram(NewChainRule().WithName("lookup"))
+
+	var start = time.Now()
+	assert.NoError(t, NewChaos().Delays(1, 20*time.Millisecond).Wrap(program)(NewRuleContext()))
+	assert.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+
+	var ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	start = time.Now()
+	assert.NoError(t, NewChaos().Delays(1, time.Hour).Wrap(program)(NewRuleContext(WithGoContext(ctx))))
+	assert.Less(t, time.Since(start), time.Minute)
+}
+
+func TestChaos_DelaysOnClock(t *testing.T) {
+	var program = NewProgram(NewChainRule().WithName("lookup").AddChildren(
+		NewChainRule().WithName("enrich").Optional().OnExecute(func(ctx Context) { ctx.GetRuleContext().Set("enriched", true) })))
+	var chaos = NewChaos().Delays(1, time.Hour).Only("lookup")
+
+	var clock = NewSimClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	var start = time.Now()
+	ruleContext := NewRuleContext(WithClock(clock), WithSoftDeadline(time.Minute, nil))
+	assert.NoError(t, chaos.Wrap(program)(ruleContext))
+	assert.Less(t, time.Since(start), time.Minute)
+	assert.Equal(t, time.Date(2024, 1, 1, 1, 0, 0, 0, time.UTC), clock.Now())
+	assert.True(t, ruleContext.Degraded())
+	assert.Nil(t, ruleContext.Get("enriched"))
+}
+
+func TestChaos_Rand(t *testing.T) {
+	var draws = []float64{0.9, 0.9, 0.1, 0.9, 0.9, 0.9}
+	var chaos = NewChaos().Errors(0.5).Cancellations(0.5).WithRand(func() float64 {
+		var d = draws[0]
+		draws = draws[1:]
+		return d
+	})
+	var rule = NewChainRule().WithName("root").AddChildren(NewChainRule().WithName("child"))
+
+	var err = chaos.Wrap(NewProgram(rule))(NewRuleContext())
+	assert.ErrorIs(t, err, ErrInjectedFault)
+	assert.Equal(t, []Fault{{Kind: FaultError, Path: "root"}}, chaos.Faults())
+	assert.Equal(t, "cancel", FaultCancel.String())
+}
@@ -0,0 +1,56 @@
+package rule
+
+import (
+	"context"
+	"time"
+)
+
+// Clock provides the current time to rules, so time-based conditions can be
+// tested with a fixed or simulated time.
+type Clock interface {
+	Now() time.Time
+}
+
+// ClockFunc adapts an ordinary function to the Clock interface.
+type ClockFunc func() time.Time
+
+// Now calls f().
+func (f ClockFunc) Now() time.Time {
+	return f()
+}
+
+// SystemClock is the Clock reading the system time.
+var SystemClock Clock = ClockFunc(time.Now)
+
+// WithClock sets the Clock of the RuleContext. It defaults to SystemClock.
+func WithClock(clock Clock) ContextOption {
+	return func(rc *RuleContext) {
+		rc.clock = clock
+	}
+}
+
+// Now returns the current time according to the context Clock.
+func (rc *RuleContext) Now() time.Time {
+	if rc.clock == nil {
+		return SystemClock.Now()
+	}
+	return rc.clock.Now()
+}
+
+// sleep waits for d according to the context Clock, or until ctx is done. A
+// virtual clock such as SimClock is advanced by d instead, so the wait takes
+// no real time but still counts against soft deadlines.
+func (rc *RuleContext) sleep(ctx context.Context, d time.Duration) {
+	if clock, ok := rc.clock.(interface{ Advance(time.Duration) }); ok {
+		if ctx.Err() == nil {
+			clock.Advance(d)
+		}
+		return
+	}
+	var timer = time.NewTimer(d)
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+		timer.Stop()
+	}
+}
@@ -0,0 +1,23 @@
+package rule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRuleContext_Now(t *testing.T) {
+	var fixed = time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, fixed, NewRuleContext(WithClock(ClockFunc(func() time.Time { return fixed }))).Now())
+	assert.WithinDuration(t, time.Now(), NewRuleContext().Now(), time.Minute)
+}
+
+func TestHistory_UsesClock(t *testing.T) {
+	var fixed = time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	rc := NewRuleContext(WithHistory(), WithClock(ClockFunc(func() time.Time { return fixed })))
+	rc.Set("key", "value")
+
+	assert.Equal(t, fixed, rc.History()[0].Time)
+}
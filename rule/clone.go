@@ -0,0 +1,64 @@
+package rule
+
+import "reflect"
+
+// Clone returns an independent copy of the context: its values, defaults,
+// options, and what was recorded so far, such as history, provenance and
+// decisions. Values are copied shallowly. Collectors shared between contexts,
+// such as a Debugger, KeyStats or Tracer, stay shared.
+func (rc *RuleContext) Clone() *RuleContext {
+	var clone = *rc
+	clone.context = copyMap(rc.context)
+	clone.defaults = copyMap(rc.defaults)
+	clone.shared = nil
+	clone.current = nil
+	clone.failure = nil
+	if rc.provenance != nil {
+		clone.provenance = rc.Provenance()
+	}
+	clone.history = append([]HistoryEntry(nil), rc.history...)
+	clone.evaluations = append([]Evaluation(nil), rc.evaluations...)
+	clone.reads = append([]KeyRead(nil), rc.reads...)
+	clone.reasons = append([]Reason(nil), rc.reasons...)
+	clone.decisions = append([]ScoredDecision(nil), rc.decisions...)
+	clone.skipped = append([]Context(nil), rc.skipped...)
+	clone.usage = append([]RuleDuration(nil), rc.usage...)
+	clone.nested = nil
+	clone.watch = nil
+	if rc.budget != nil {
+		var budget = *rc.budget
+		clone.budget = &budget
+	}
+	return &clone
+}
+
+// Equal reports whether both contexts hold the same values, defaults
+// included. Values of the same key are compared with cmp, or with
+// reflect.DeepEqual when cmp is nil.
+func (rc *RuleContext) Equal(other *RuleContext, cmp func(key string, a, b interface{}) bool) bool {
+	var a, b = rc.Values(), other.Values()
+	if len(a) != len(b) {
+		return false
+	}
+	for k, va := range a {
+		var vb, ok = b[k]
+		if !ok {
+			return false
+		}
+		if cmp == nil && !reflect.DeepEqual(va, vb) || cmp != nil && !cmp(k, va, vb) {
+			return false
+		}
+	}
+	return true
+}
+
+func copyMap(m map[string]interface{}) map[string]interface{} {
+	if m == nil {
+		return nil
+	}
+	var c = make(map[string]interface{}, len(m))
+	for k, v := range m {
+		c[k] = v
+	}
+	return c
+}
@@ -0,0 +1,47 @@
+package rule
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRuleContext_Clone(t *testing.T) {
+	rc := NewRuleContext(WithHistory(), WithDefaults(map[string]interface{}{"currency": "EUR"}), WithBudget(10))
+	rc.Set("amount", 10)
+	rc.SetDecision("approve", 0.5)
+
+	clone := rc.Clone()
+	assert.True(t, clone.Equal(rc, nil))
+	assert.Equal(t, rc.History(), clone.History())
+
+	clone.Set("amount", 20)
+	clone.Set("status", "review")
+	clone.SetDecision("review", 0.9)
+	assert.NoError(t, ChainRuleRunner(clone, NewChainRule().Cost(3)))
+
+	assert.Equal(t, map[string]interface{}{"amount": 10, "currency": "EUR"}, rc.Values())
+	assert.Equal(t, 1, len(rc.History()))
+	assert.Equal(t, 1, len(rc.Decisions()))
+	assert.Equal(t, 0.0, rc.Spent())
+	assert.Equal(t, 3.0, clone.Spent())
+	assert.Equal(t, "EUR", clone.Get("currency"))
+	assert.False(t, clone.Equal(rc, nil))
+}
+
+func TestRuleContext_Equal(t *testing.T) {
+	var x, y = 0.1, 0.2
+	a := NewRuleContext()
+	a.Set("score", x+y)
+	b := NewRuleContext(WithDefaults(map[string]interface{}{"score": 0.3}))
+
+	assert.False(t, a.Equal(b, nil))
+	assert.True(t, a.Equal(b, func(key string, x, y interface{}) bool {
+		return math.Abs(x.(float64)-y.(float64)) < 1e-9
+	}))
+
+	b.Set("extra", true)
+	assert.False(t, a.Equal(b, func(string, interface{}, interface{}) bool { return true }))
+	assert.True(t, NewRuleContext().Equal(NewRuleContext(), nil))
+}
@@ -0,0 +1,116 @@
+package rule
+
+import (
+	"reflect"
+	"sort"
+)
+
+// RunDiff describes where two runs diverged.
+type RunDiff struct {
+	// Diverged reports whether the runs evaluated rules differently.
+	Diverged bool
+	// Step is the position, in the recorded evaluations, of the first
+	// evaluation that differs, or -1 when the runs did not diverge.
+	Step int
+	// RuleA and RuleB are the rules evaluated at Step in each run; one of them
+	// is nil when a run stopped evaluating before the other.
+	RuleA, RuleB Context
+	// ResultA and ResultB are the eval outcomes at Step in each run.
+	ResultA, ResultB bool
+	// Writes lists the keys written by rules whose final values differ.
+	Writes []WriteDiff
+}
+
+// WriteDiff is a key with a different final value in each run.
+type WriteDiff struct {
+	Key  string
+	A, B interface{}
+	InA  bool
+	InB  bool
+}
+
+// Equal reports whether the runs took the same path and wrote the same values.
+func (d RunDiff) Equal() bool {
+	return !d.Diverged && len(d.Writes) == 0
+}
+
+// CompareRuns compares two runs recorded with WithHistory and reports the first
+// rule evaluated differently and the keys written with different values.
+// Rules are matched by name when both are named, and by identity otherwise,
+// where copies made with Clone, such as those run by NewProgram, match the
+// rule they were cloned from.
+func CompareRuns(a, b *RuleContext) RunDiff {
+	var diff = RunDiff{Step: -1}
+
+	var evalsA, evalsB = a.Evaluations(), b.Evaluations()
+	for i := 0; i < max(len(evalsA), len(evalsB)); i++ {
+		var ea, eb Evaluation
+		if i < len(evalsA) {
+			ea = evalsA[i]
+		}
+		if i < len(evalsB) {
+			eb = evalsB[i]
+		}
+		if ea.Result == eb.Result && sameRule(ea.Rule, eb.Rule) {
+			continue
+		}
+
+		diff.Diverged = true
+		diff.Step = i
+		diff.RuleA, diff.RuleB = ea.Rule, eb.Rule
+		diff.ResultA, diff.ResultB = ea.Result, eb.Result
+		break
+	}
+
+	var keys = writtenKeys(a)
+	for k := range writtenKeys(b) {
+		keys[k] = true
+	}
+
+	var sorted = make([]string, 0, len(keys))
+	for k := range keys {
+		sorted = append(sorted, k)
+	}
+	sort.Strings(sorted)
+
+	for _, k := range sorted {
+		va, inA := a.context[k]
+		vb, inB := b.context[k]
+		if inA != inB || !reflect.DeepEqual(va, vb) {
+			diff.Writes = append(diff.Writes, WriteDiff{Key: k, A: va, B: vb, InA: inA, InB: inB})
+		}
+	}
+	return diff
+}
+
+func sameRule(a, b Context) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	if nameOf(a) != "" && nameOf(b) != "" {
+		return nameOf(a) == nameOf(b)
+	}
+	return identityOf(a) == identityOf(b)
+}
+
+// identityOf returns the rule a rule was cloned from, or the Context itself.
+func identityOf(c Context) Context {
+	if r, ok := c.(interface{ identity() Context }); ok {
+		return r.identity()
+	}
+	return c
+}
+
+func (r *BaseRule[T]) identity() Context {
+	return r.original()
+}
+
+func writtenKeys(rc *RuleContext) map[string]bool {
+	var keys = make(map[string]bool)
+	for _, entry := range rc.History() {
+		if entry.Rule != nil {
+			keys[entry.Key] = true
+		}
+	}
+	return keys
+}
@@ -0,0 +1,76 @@
+package rule
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func compareRun(amount int) *RuleContext {
+	rules := []*BaseRule[BestFirstRule]{
+		NewBestFirstRule().WithName("large").
+			OnEval(func(ctx Context) bool { return ctx.GetRuleContext().Get("amount").(int) > 100 }).
+			OnExecute(func(ctx Context) { ctx.GetRuleContext().Set("decision", "review") }),
+		NewBestFirstRule().WithName("default").
+			OnExecute(func(ctx Context) { ctx.GetRuleContext().Set("decision", "approve") }),
+	}
+
+	ruleContext := NewRuleContext(WithHistory())
+	ruleContext.Set("amount", amount)
+	BestFirstRuleRunner(ruleContext, rules...)
+	return ruleContext
+}
+
+func TestCompareRuns_Equal(t *testing.T) {
+	diff := CompareRuns(compareRun(50), compareRun(60))
+
+	assert.True(t, diff.Equal())
+	assert.Equal(t, -1, diff.Step)
+}
+
+func TestCompareRuns_Diverged(t *testing.T) {
+	diff := CompareRuns(compareRun(50), compareRun(500))
+
+	assert.False(t, diff.Equal())
+	assert.True(t, diff.Diverged)
+	assert.Equal(t, 0, diff.Step)
+	assert.Equal(t, "large", diff.RuleA.(Named).GetName())
+	assert.False(t, diff.ResultA)
+	assert.True(t, diff.ResultB)
+	assert.Equal(t, []WriteDiff{{Key: "decision", A: "approve", B: "review", InA: true, InB: true}}, diff.Writes)
+}
+
+func TestCompareRuns_OneRunStoppedEarlier(t *testing.T) {
+	diff := CompareRuns(compareRun(500), compareRun(50))
+
+	assert.Equal(t, 0, diff.Step)
+
+	a := NewRuleContext(WithHistory())
+	b := compareRun(50)
+	diff = CompareRuns(a, b)
+	assert.Nil(t, diff.RuleA)
+	assert.Equal(t, "large", diff.RuleB.(Named).GetName())
+	assert.Equal(t, []WriteDiff{{Key: "decision", B: "approve", InB: true}}, diff.Writes)
+}
+
+func TestCompareRuns_UnnamedClones(t *testing.T) {
+	var unnamed = NewBestFirstRule().OnEval(func(Context) bool { return true })
+	var run = func() *RuleContext {
+		ruleContext := NewRuleContext(WithHistory())
+		BestFirstRuleRunner(ruleContext, unnamed.Clone())
+		return ruleContext
+	}
+
+	assert.True(t, CompareRuns(run(), run()).Equal())
+}
+
+func TestEvaluations_Recorded(t *testing.T) {
+	ruleContext := compareRun(50)
+
+	evaluations := ruleContext.Evaluations()
+	assert.Equal(t, 2, len(evaluations))
+	assert.Equal(t, "default", evaluations[1].Rule.(Named).GetName())
+	assert.True(t, evaluations[1].Result)
+
+	assert.Nil(t, NewRuleContext().Evaluations())
+}
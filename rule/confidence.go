@@ -0,0 +1,92 @@
+package rule
+
+import "fmt"
+
+// ScoredDecision is a decision set by a rule together with its confidence.
+type ScoredDecision struct {
+	Value      interface{}
+	Confidence float64
+	// Rule is the rule that set the decision, or nil outside of a run.
+	Rule Context
+}
+
+// DecisionPolicy aggregates the decisions set during a run into one, or
+// reports false when they don't aggregate, so the run has no decision.
+// It is only called with at least one decision.
+type DecisionPolicy func(decisions []ScoredDecision) (ScoredDecision, bool)
+
+// LastDecision keeps the last decision set. It is the default policy.
+func LastDecision(decisions []ScoredDecision) (ScoredDecision, bool) {
+	return decisions[len(decisions)-1], true
+}
+
+// MaxConfidence keeps the decision with the highest confidence, the earliest
+// one on ties.
+func MaxConfidence(decisions []ScoredDecision) (ScoredDecision, bool) {
+	var best = decisions[0]
+	for _, d := range decisions[1:] {
+		if d.Confidence > best.Confidence {
+			best = d
+		}
+	}
+	return best, true
+}
+
+// WeightedAverage averages numeric decision values weighted by their
+// confidence. The aggregated confidence is the mean confidence and the
+// aggregated decision has no rule. Non-numeric values are ignored; without
+// numeric values of positive confidence, there is no decision.
+func WeightedAverage(decisions []ScoredDecision) (ScoredDecision, bool) {
+	var sum, weights, confidence float64
+	var n int
+	for _, d := range decisions {
+		v, ok := toFloat(d.Value)
+		if !ok {
+			continue
+		}
+		sum += v * d.Confidence
+		weights += d.Confidence
+		confidence += d.Confidence
+		n++
+	}
+
+	if n == 0 || weights == 0 {
+		return ScoredDecision{}, false
+	}
+	return ScoredDecision{Value: sum / weights, Confidence: confidence / float64(n)}, true
+}
+
+// WithDecisionPolicy sets how the decisions of a run are aggregated by Decision.
+func WithDecisionPolicy(policy DecisionPolicy) ContextOption {
+	return func(rc *RuleContext) {
+		rc.policy = policy
+	}
+}
+
+// SetDecision records a decision with a confidence between 0 and 1.
+// It panics if the confidence is out of range.
+func (rc *RuleContext) SetDecision(value interface{}, confidence float64) {
+	if confidence < 0 || confidence > 1 {
+		panic(fmt.Sprintf("decision confidence %v out of range [0, 1]", confidence))
+	}
+	rc.decisions = append(rc.decisions, ScoredDecision{Value: value, Confidence: confidence, Rule: rc.current})
+}
+
+// Decisions returns the decisions recorded during the run, in order.
+func (rc *RuleContext) Decisions() []ScoredDecision {
+	return append([]ScoredDecision{}, rc.decisions...)
+}
+
+// Decision returns the decisions of the run aggregated by the decision policy,
+// and false if no decision was recorded or the policy could not aggregate them.
+func (rc *RuleContext) Decision() (ScoredDecision, bool) {
+	if len(rc.decisions) == 0 {
+		return ScoredDecision{}, false
+	}
+
+	var policy = rc.policy
+	if policy == nil {
+		policy = LastDecision
+	}
+	return policy(rc.Decisions())
+}
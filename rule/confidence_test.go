@@ -0,0 +1,69 @@
+package rule
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func decisionRun(opts ...ContextOption) *RuleContext {
+	rules := []*BaseRule[ChainRule]{
+		NewChainRule().WithName("velocity").OnExecute(func(ctx Context) {
+			ctx.GetRuleContext().SetDecision(80, 0.9)
+		}),
+		NewChainRule().WithName("device").OnExecute(func(ctx Context) {
+			ctx.GetRuleContext().SetDecision(20, 0.3)
+		}),
+	}
+	rules[0].AddChildren(rules[1])
+
+	ruleContext := NewRuleContext(opts...)
+	ChainRuleRunner(ruleContext, rules[0])
+	return ruleContext
+}
+
+func TestDecision_None(t *testing.T) {
+	_, ok := NewRuleContext().Decision()
+	assert.False(t, ok)
+}
+
+func TestDecision_LastByDefault(t *testing.T) {
+	decision, ok := decisionRun().Decision()
+
+	assert.True(t, ok)
+	assert.Equal(t, 20, decision.Value)
+	assert.Equal(t, "device", decision.Rule.(Named).GetName())
+}
+
+func TestDecision_MaxConfidence(t *testing.T) {
+	decision, _ := decisionRun(WithDecisionPolicy(MaxConfidence)).Decision()
+
+	assert.Equal(t, 80, decision.Value)
+	assert.Equal(t, 0.9, decision.Confidence)
+	assert.Equal(t, "velocity", decision.Rule.(Named).GetName())
+}
+
+func TestDecision_WeightedAverage(t *testing.T) {
+	ruleContext := decisionRun(WithDecisionPolicy(WeightedAverage))
+	decision, _ := ruleContext.Decision()
+
+	assert.InDelta(t, 65.0, decision.Value, 1e-9)
+	assert.InDelta(t, 0.6, decision.Confidence, 1e-9)
+	assert.Equal(t, 2, len(ruleContext.Decisions()))
+
+	_, ok := WeightedAverage([]ScoredDecision{{Value: "deny", Confidence: 1}})
+	assert.False(t, ok)
+	_, ok = WeightedAverage([]ScoredDecision{{Value: 1.0, Confidence: 0}})
+	assert.False(t, ok)
+
+	ruleContext = NewRuleContext(WithDecisionPolicy(WeightedAverage))
+	ruleContext.SetDecision("deny", 1)
+	_, ok = ruleContext.Decision()
+	assert.False(t, ok)
+}
+
+func TestSetDecision_PanicsOnInvalidConfidence(t *testing.T) {
+	assert.PanicsWithValue(t, "decision confidence 1.5 out of range [0, 1]", func() {
+		NewRuleContext().SetDecision(true, 1.5)
+	})
+}
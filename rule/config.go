@@ -0,0 +1,110 @@
+package rule
+
+import (
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ConfigSource provides deployment configuration values by name.
+type ConfigSource interface {
+	Lookup(name string) (string, bool)
+}
+
+// ConfigMap is a ConfigSource backed by a map.
+type ConfigMap map[string]string
+
+// Lookup returns the value of name in the map.
+func (m ConfigMap) Lookup(name string) (string, bool) {
+	value, ok := m[name]
+	return value, ok
+}
+
+type envSource struct{}
+
+func (envSource) Lookup(name string) (string, bool) {
+	return os.LookupEnv(name)
+}
+
+// Env is a ConfigSource reading environment variables.
+var Env ConfigSource = envSource{}
+
+type cachedConfig struct {
+	mu     sync.Mutex
+	source ConfigSource
+	ttl    time.Duration
+	clock  Clock
+	values map[string]cachedValue
+}
+
+type cachedValue struct {
+	value   string
+	ok      bool
+	expires time.Time
+}
+
+// CachedConfig wraps a ConfigSource so each value is looked up at most once
+// per ttl. A ttl of zero caches values forever.
+func CachedConfig(source ConfigSource, ttl time.Duration) ConfigSource {
+	return CachedConfigClock(source, ttl, SystemClock)
+}
+
+// CachedConfigClock is like CachedConfig, reading the time from clock, such
+// as the SimClock of a Simulation.
+func CachedConfigClock(source ConfigSource, ttl time.Duration, clock Clock) ConfigSource {
+	return &cachedConfig{source: source, ttl: ttl, clock: clock, values: make(map[string]cachedValue)}
+}
+
+func (c *cachedConfig) Lookup(name string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var now = c.clock.Now()
+	if v, ok := c.values[name]; ok && (c.ttl == 0 || now.Before(v.expires)) {
+		return v.value, v.ok
+	}
+
+	value, ok := c.source.Lookup(name)
+	c.values[name] = cachedValue{value: value, ok: ok, expires: now.Add(c.ttl)}
+	return value, ok
+}
+
+// ConfigIsSet returns an OnEval predicate reporting whether the configuration
+// value is set.
+func ConfigIsSet(source ConfigSource, name string) func(Context) bool {
+	return func(Context) bool {
+		_, ok := source.Lookup(name)
+		return ok
+	}
+}
+
+// ConfigEquals returns an OnEval predicate reporting whether the configuration
+// value equals value.
+func ConfigEquals(source ConfigSource, name string, value string) func(Context) bool {
+	return func(Context) bool {
+		v, ok := source.Lookup(name)
+		return ok && v == value
+	}
+}
+
+// ConfigEnabled returns an OnEval predicate reporting whether the
+// configuration value is a true boolean, as parsed by strconv.ParseBool.
+// Unset or invalid values are false.
+func ConfigEnabled(source ConfigSource, name string) func(Context) bool {
+	return func(Context) bool {
+		v, _ := source.Lookup(name)
+		enabled, err := strconv.ParseBool(v)
+		return err == nil && enabled
+	}
+}
+
+// LoadConfig returns an execute hook copying the configuration value into the
+// context key. Unset values leave the context untouched.
+func LoadConfig(source ConfigSource, name string, key string) func(Context) {
+	return func(ctx Context) {
+		if v, ok := source.Lookup(name); ok {
+			ctx.GetRuleContext().Set(key, v)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package rule
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type countingConfig struct {
+	ConfigMap
+	lookups int
+}
+
+func (c *countingConfig) Lookup(name string) (string, bool) {
+	c.lookups++
+	return c.ConfigMap.Lookup(name)
+}
+
+func TestConfigPredicates(t *testing.T) {
+	var config = ConfigMap{"region": "eu", "new_checkout": "true", "beta": "maybe"}
+	var ctx = NewChainRule()
+
+	assert.True(t, ConfigIsSet(config, "region")(ctx))
+	assert.False(t, ConfigIsSet(config, "zone")(ctx))
+	assert.True(t, ConfigEquals(config, "region", "eu")(ctx))
+	assert.False(t, ConfigEquals(config, "region", "us")(ctx))
+	assert.True(t, ConfigEnabled(config, "new_checkout")(ctx))
+	assert.False(t, ConfigEnabled(config, "beta")(ctx))
+	assert.False(t, ConfigEnabled(config, "missing")(ctx))
+}
+
+func TestEnv(t *testing.T) {
+	t.Setenv("DREDD_TEST_ENV", "staging")
+
+	assert.True(t, ConfigEquals(Env, "DREDD_TEST_ENV", "staging")(NewChainRule()))
+}
+
+func TestLoadConfig(t *testing.T) {
+	rule := NewChainRule().OnExecute(LoadConfig(ConfigMap{"region": "eu"}, "region", "deploy.region"))
+
+	ruleContext := NewRuleContext()
+	ChainRuleRunner(ruleContext, rule)
+
+	assert.Equal(t, "eu", ruleContext.Get("deploy.region"))
+}
+
+func TestCachedConfig(t *testing.T) {
+	var source = &countingConfig{ConfigMap: ConfigMap{"region": "eu"}}
+	var config = CachedConfig(source, 0)
+
+	for i := 0; i < 3; i++ {
+		value, ok := config.Lookup("region")
+		assert.True(t, ok)
+		assert.Equal(t, "eu", value)
+		_, ok = config.Lookup("zone")
+		assert.False(t, ok)
+	}
+	assert.Equal(t, 2, source.lookups)
+}
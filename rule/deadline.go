@@ -0,0 +1,95 @@
+package rule
+
+import "time"
+
+// DegradePolicy is called once when the soft deadline of a run elapses, before
+// the first optional rule is skipped, to adjust the partial outcome.
+type DegradePolicy func(rc *RuleContext)
+
+// DegradeTo returns a DegradePolicy that sets a fallback decision when no
+// rule has decided yet. Required rules still running may override it.
+func DegradeTo(value interface{}, confidence float64) DegradePolicy {
+	return func(rc *RuleContext) {
+		if _, ok := rc.Decision(); !ok {
+			rc.SetDecision(value, confidence)
+		}
+	}
+}
+
+// WithSoftDeadline skips the optional rules fired more than d after the first
+// rule of the run, while required rules still run to completion. The degrade
+// policy, which may be nil, is applied when the deadline is first exceeded.
+func WithSoftDeadline(d time.Duration, degrade DegradePolicy) ContextOption {
+	return func(rc *RuleContext) {
+		rc.softDeadline = d
+		rc.degrade = degrade
+	}
+}
+
+// Degraded reports whether the soft deadline elapsed during the run.
+func (rc *RuleContext) Degraded() bool {
+	return rc.degraded
+}
+
+func (rc *RuleContext) pastDeadline() bool {
+	if rc.softDeadline <= 0 {
+		return false
+	}
+	if rc.deadline.IsZero() {
+		rc.deadline = rc.Now().Add(rc.softDeadline)
+	}
+	if !rc.degraded && rc.Now().After(rc.deadline) {
+		rc.degraded = true
+		if rc.degrade != nil {
+			var current = rc.current
+			rc.current = nil
+			rc.degrade(rc)
+			rc.current = current
+		}
+	}
+	return rc.degraded
+}
+
+// RuleDuration is the time a rule spent during a run.
+type RuleDuration struct {
+	Rule     Context
+	Duration time.Duration
+}
+
+// DeadlineUsage returns how much time each rule fired during a run with a
+// soft deadline took, in the order the rules were first fired, so the rules
+// eating the latency budget stand out without tracing. A rule is charged for
+// its hooks, not for its children, and for every time it was fired; a
+// parallel rule is also charged for waiting for its children. Time is read
+// from the Clock of the context. Runs without a soft deadline record nothing.
+func (rc *RuleContext) DeadlineUsage() []RuleDuration {
+	return append([]RuleDuration(nil), rc.usage...)
+}
+
+// timeRule starts charging r for the time until the returned function is
+// called, minus the time of the rules fired meanwhile.
+func (rc *RuleContext) timeRule(r Context) func() {
+	rc.charge(r, 0)
+	var start = rc.Now()
+	rc.nested = append(rc.nested, 0)
+	return func() {
+		var elapsed = rc.Now().Sub(start)
+		var n = len(rc.nested) - 1
+		var own = elapsed - rc.nested[n]
+		rc.nested = rc.nested[:n]
+		if n > 0 {
+			rc.nested[n-1] += elapsed
+		}
+		rc.charge(r, own)
+	}
+}
+
+func (rc *RuleContext) charge(r Context, d time.Duration) {
+	for i := range rc.usage {
+		if rc.usage[i].Rule == r {
+			rc.usage[i].Duration += d
+			return
+		}
+	}
+	rc.usage = append(rc.usage, RuleDuration{Rule: r, Duration: d})
+}
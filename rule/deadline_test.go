@@ -0,0 +1,98 @@
+package rule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRuleContext_SoftDeadline(t *testing.T) {
+	var now = time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	var fired []string
+	var rule = func(name string, took time.Duration) *BaseRule[ChainRule] {
+		return NewChainRule().WithName(name).
+			OnEval(func(Context) bool { return true }).
+			OnExecute(func(Context) {
+				fired = append(fired, name)
+				now = now.Add(took)
+			})
+	}
+
+	var slow = rule("slow", 80*time.Millisecond).Optional()
+	var enrich = rule("enrich", 0).Optional()
+	var decide = rule("decide", 0).OnExecute(func(ctx Context) {
+		fired = append(fired, "decide")
+		ctx.GetRuleContext().SetDecision("approve", 0.9)
+	})
+	var extra = rule("extra", 0).Optional()
+	rc := NewRuleContext(
+		WithClock(ClockFunc(func() time.Time { return now })),
+		WithSoftDeadline(50*time.Millisecond, DegradeTo("review", 0.1)),
+	)
+	ChainRuleRunner(rc, slow.AddChildren(enrich.AddChildren(extra)))
+	ChainRuleRunner(rc, decide)
+
+	assert.Equal(t, []string{"slow", "decide"}, fired)
+	assert.True(t, rc.Degraded())
+	assert.Equal(t, []Context{enrich}, rc.Skipped())
+
+	var decision, _ = rc.Decision()
+	assert.Equal(t, "approve", decision.Value)
+	assert.Equal(t, []ScoredDecision{{Value: "review", Confidence: 0.1}, {Value: "approve", Confidence: 0.9, Rule: decide}}, rc.Decisions())
+}
+
+func TestRuleContext_SoftDeadlineNotReached(t *testing.T) {
+	var fired = false
+	rc := NewRuleContext(WithSoftDeadline(time.Hour, nil))
+	ChainRuleRunner(rc, NewChainRule().Optional().
+		OnEval(func(Context) bool { return true }).
+		OnExecute(func(Context) { fired = true }))
+
+	assert.True(t, fired)
+	assert.False(t, rc.Degraded())
+}
+
+func TestRuleContext_DeadlineUsage(t *testing.T) {
+	var now = time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	var rule = func(name string, took time.Duration) *BaseRule[BestFirstRule] {
+		return NewBestFirstRule().WithName(name).OnExecute(func(Context) { now = now.Add(took) })
+	}
+
+	var fraud = rule("fraud", 30*time.Millisecond)
+	var score = rule("score", 5*time.Millisecond)
+	var tree = rule("root", time.Millisecond).AddChildren(
+		rule("skipped", 0).OnEval(func(Context) bool { now = now.Add(2 * time.Millisecond); return false }),
+		fraud.AddChildren(score),
+	)
+	rc := NewRuleContext(
+		WithClock(ClockFunc(func() time.Time { return now })),
+		WithSoftDeadline(time.Second, nil),
+	)
+	assert.NoError(t, BestFirstRuleRunner(rc, tree))
+	assert.NoError(t, BestFirstRuleRunner(rc, score))
+
+	assert.Equal(t, []RuleDuration{
+		{Rule: tree, Duration: time.Millisecond},
+		{Rule: tree.GetChildren()[0], Duration: 2 * time.Millisecond},
+		{Rule: fraud, Duration: 30 * time.Millisecond},
+		{Rule: score, Duration: 10 * time.Millisecond},
+	}, rc.DeadlineUsage())
+	assert.Len(t, rc.Clone().DeadlineUsage(), 4)
+
+	rc = NewRuleContext()
+	assert.NoError(t, BestFirstRuleRunner(rc, rule("untimed", 0)))
+	assert.Empty(t, rc.DeadlineUsage())
+}
+
+func TestRuleContext_DeadlineUsageParallel(t *testing.T) {
+	rc := NewRuleContext(WithSoftDeadline(time.Second, nil))
+	var a, b = NewChainRule().WithName("a"), NewChainRule().WithName("b")
+	assert.NoError(t, RuleRunner(ChainRuleType, rc, NewParallelRule[ChainRule]().AddChildren(a, b)))
+
+	var rules []Context
+	for _, u := range rc.DeadlineUsage() {
+		rules = append(rules, u.Rule)
+	}
+	assert.ElementsMatch(t, []Context{a, b}, rules[1:])
+}
@@ -0,0 +1,207 @@
+package rule
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Command tells the Debugger how to proceed with the pending rule.
+type Command int
+
+const (
+	// Step runs the pending rule and pauses before the next one.
+	Step Command = iota
+	// Continue runs the pending rule and the rest of the run without pausing.
+	Continue
+	// Skip does not run the pending rule, as if its OnEval returned false.
+	Skip
+)
+
+// Pause describes the state of a run paused before a rule.
+type Pause struct {
+	// Rule is the rule about to be evaluated, or the rule writing Key.
+	Rule Context
+	// Context is the RuleContext of the run.
+	Context *RuleContext
+	// Key is the written key when the pause was triggered by a key breakpoint.
+	Key string
+}
+
+// Inject sets a key in the context of the paused run.
+func (p Pause) Inject(key string, value interface{}) {
+	p.Context.Set(key, value)
+}
+
+// Debugger pauses a run before each rule is evaluated and asks a handler how
+// to proceed. Attach it to a run with WithDebugger.
+type Debugger struct {
+	handler   func(Pause) Command
+	stepping  bool
+	paused    bool
+	ruleBreak map[string]bool
+	pathBreak map[string]bool
+	keyBreak  map[string]bool
+}
+
+// NewDebugger creates a Debugger calling handler before each rule.
+func NewDebugger(handler func(Pause) Command) *Debugger {
+	return &Debugger{
+		handler:   handler,
+		stepping:  true,
+		ruleBreak: make(map[string]bool),
+		pathBreak: make(map[string]bool),
+		keyBreak:  make(map[string]bool),
+	}
+}
+
+// BreakOnRule sets breakpoints before the rules with the given names.
+// Once a breakpoint is set, the run no longer pauses before every rule but
+// continues until a breakpoint is hit; returning Step from the handler resumes
+// stepping.
+func (d *Debugger) BreakOnRule(names ...string) *Debugger {
+	for _, name := range names {
+		d.ruleBreak[name] = true
+	}
+	d.stepping = false
+	return d
+}
+
+// BreakOnPath sets breakpoints before the rules with the given paths, as
+// returned by Path. Like BreakOnRule, it makes the run continue until a
+// breakpoint is hit.
+func (d *Debugger) BreakOnPath(paths ...string) *Debugger {
+	for _, path := range paths {
+		d.pathBreak[path] = true
+	}
+	d.stepping = false
+	return d
+}
+
+// BreakOnKey sets breakpoints on writes to the given keys: the handler is
+// called right after a rule sets or deletes one of them, with Pause.Key set.
+// Returning Skip from such a pause has no effect.
+// Like BreakOnRule, it makes the run continue until a breakpoint is hit.
+func (d *Debugger) BreakOnKey(keys ...string) *Debugger {
+	for _, key := range keys {
+		d.keyBreak[key] = true
+	}
+	d.stepping = false
+	return d
+}
+
+// WithDebugger attaches a Debugger to the runs using the RuleContext.
+func WithDebugger(d *Debugger) ContextOption {
+	return func(rc *RuleContext) {
+		rc.debugger = d
+	}
+}
+
+// pause reports whether the rule should run.
+func (d *Debugger) pause(r Context, rc *RuleContext) bool {
+	if !d.stepping && !d.ruleBreak[nameOf(r)] && !d.breakOnPath(r) {
+		return true
+	}
+	return d.call(Pause{Rule: r, Context: rc}) != Skip
+}
+
+func (d *Debugger) breakOnPath(r Context) bool {
+	if len(d.pathBreak) == 0 {
+		return false
+	}
+	var p, ok = r.(interface{ Path() string })
+	return ok && d.pathBreak[p.Path()]
+}
+
+func (d *Debugger) written(rc *RuleContext, key string) {
+	if d.keyBreak[key] && !d.paused {
+		d.call(Pause{Rule: rc.current, Context: rc, Key: key})
+	}
+}
+
+func (d *Debugger) call(p Pause) Command {
+	d.paused = true
+	defer func() { d.paused = false }()
+
+	var cmd = d.handler(p)
+	switch cmd {
+	case Step:
+		d.stepping = true
+	case Continue:
+		d.stepping = false
+	}
+	return cmd
+}
+
+// Prompt returns a Debugger handler driven by text commands, for interactive
+// debugging from a terminal:
+//
+//	step (s)              run the pending rule and pause before the next one
+//	continue (c)          run until the end
+//	skip (k)              skip the pending rule
+//	print (p)             print the context
+//	set <key> <value>     inject a string value into the context
+//
+// The handler continues the run when in is exhausted.
+func Prompt(in io.Reader, out io.Writer) func(Pause) Command {
+	var scanner = bufio.NewScanner(in)
+	return func(p Pause) Command {
+		if p.Key != "" {
+			fmt.Fprintf(out, "paused after %s wrote %q\n", ruleLabel(p.Rule), p.Key)
+		} else {
+			fmt.Fprintf(out, "paused before %s\n", ruleLabel(p.Rule))
+		}
+		for {
+			fmt.Fprint(out, "> ")
+			if !scanner.Scan() {
+				return Continue
+			}
+
+			var fields = strings.Fields(scanner.Text())
+			if len(fields) == 0 {
+				continue
+			}
+			switch fields[0] {
+			case "step", "s":
+				return Step
+			case "continue", "c":
+				return Continue
+			case "skip", "k":
+				return Skip
+			case "print", "p":
+				printContext(out, p.Context)
+			case "set":
+				if len(fields) < 3 {
+					fmt.Fprintln(out, "usage: set <key> <value>")
+					continue
+				}
+				p.Inject(fields[1], strings.Join(fields[2:], " "))
+			default:
+				fmt.Fprintf(out, "unknown command %q\n", fields[0])
+			}
+		}
+	}
+}
+
+func ruleLabel(r Context) string {
+	if r == nil {
+		return "caller"
+	}
+	if name := nameOf(r); name != "" {
+		return fmt.Sprintf("rule %q", name)
+	}
+	return "unnamed rule"
+}
+
+func printContext(out io.Writer, rc *RuleContext) {
+	var keys = make([]string, 0, len(rc.context))
+	for k := range rc.context {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(out, "  %s = %v\n", k, rc.context[k])
+	}
+}
@@ -0,0 +1,150 @@
+package rule
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func debuggerRules() []*BaseRule[BestFirstRule] {
+	var rules []*BaseRule[BestFirstRule]
+	for _, name := range []string{"first", "second"} {
+		var n = name
+		rules = append(rules, NewBestFirstRule().WithName(n).
+			OnEval(func(ctx Context) bool { return ctx.GetRuleContext().Get("pick") == n }).
+			OnExecute(func(ctx Context) { ctx.GetRuleContext().Set("picked", n) }))
+	}
+	return rules
+}
+
+func TestDebugger_StepsThroughRules(t *testing.T) {
+	var paused []string
+	debugger := NewDebugger(func(p Pause) Command {
+		paused = append(paused, p.Rule.(Named).GetName())
+		return Step
+	})
+
+	ruleContext := NewRuleContext(WithDebugger(debugger))
+	ruleContext.Set("pick", "second")
+
+	BestFirstRuleRunner(ruleContext, debuggerRules()...)
+
+	assert.Equal(t, []string{"first", "second"}, paused)
+	assert.Equal(t, "second", ruleContext.Get("picked"))
+}
+
+func TestDebugger_Continue(t *testing.T) {
+	var calls int
+	debugger := NewDebugger(func(p Pause) Command {
+		calls++
+		return Continue
+	})
+
+	ruleContext := NewRuleContext(WithDebugger(debugger))
+	ruleContext.Set("pick", "second")
+
+	BestFirstRuleRunner(ruleContext, debuggerRules()...)
+
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, "second", ruleContext.Get("picked"))
+}
+
+func TestDebugger_SkipAndInject(t *testing.T) {
+	debugger := NewDebugger(func(p Pause) Command {
+		if p.Rule.(Named).GetName() == "first" {
+			p.Inject("pick", "second")
+			return Skip
+		}
+		return Step
+	})
+
+	ruleContext := NewRuleContext(WithDebugger(debugger))
+	ruleContext.Set("pick", "first")
+
+	BestFirstRuleRunner(ruleContext, debuggerRules()...)
+
+	assert.Equal(t, "second", ruleContext.Get("picked"))
+}
+
+func TestPrompt(t *testing.T) {
+	var out strings.Builder
+	in := strings.NewReader("bogus\nset pick second\np\nskip\nstep\n")
+
+	ruleContext := NewRuleContext(WithDebugger(NewDebugger(Prompt(in, &out))))
+	ruleContext.Set("pick", "first")
+
+	BestFirstRuleRunner(ruleContext, debuggerRules()...)
+
+	assert.Equal(t, "second", ruleContext.Get("picked"))
+	assert.Equal(t, `paused before rule "first"
+> unknown command "bogus"
+> >   pick = second
+> paused before rule "second"
+> `, out.String())
+}
+
+func TestDebugger_BreakOnRule(t *testing.T) {
+	var paused []string
+	debugger := NewDebugger(func(p Pause) Command {
+		paused = append(paused, p.Rule.(Named).GetName())
+		return Continue
+	}).BreakOnRule("second")
+
+	ruleContext := NewRuleContext(WithDebugger(debugger))
+	ruleContext.Set("pick", "second")
+
+	BestFirstRuleRunner(ruleContext, debuggerRules()...)
+
+	assert.Equal(t, []string{"second"}, paused)
+}
+
+func TestDebugger_BreakOnRuleThenStep(t *testing.T) {
+	var paused []string
+	debugger := NewDebugger(func(p Pause) Command {
+		paused = append(paused, p.Rule.(Named).GetName())
+		return Step
+	}).BreakOnRule("first")
+
+	ruleContext := NewRuleContext(WithDebugger(debugger))
+	ruleContext.Set("pick", "second")
+
+	BestFirstRuleRunner(ruleContext, debuggerRules()...)
+
+	assert.Equal(t, []string{"first", "second"}, paused)
+}
+
+func TestDebugger_BreakOnKey(t *testing.T) {
+	var pauses []Pause
+	debugger := NewDebugger(func(p Pause) Command {
+		pauses = append(pauses, p)
+		p.Inject("picked", "overridden")
+		return Continue
+	}).BreakOnKey("picked")
+
+	ruleContext := NewRuleContext(WithDebugger(debugger))
+	ruleContext.Set("pick", "first")
+
+	BestFirstRuleRunner(ruleContext, debuggerRules()...)
+
+	assert.Equal(t, 1, len(pauses))
+	assert.Equal(t, "picked", pauses[0].Key)
+	assert.Equal(t, "first", pauses[0].Rule.(Named).GetName())
+	assert.Equal(t, "overridden", ruleContext.Get("picked"))
+}
+
+func TestDebugger_BreakOnPath(t *testing.T) {
+	var paused []string
+	debugger := NewDebugger(func(p Pause) Command {
+		paused = append(paused, p.Rule.(*BaseRule[BestFirstRule]).Path())
+		return Continue
+	}).BreakOnPath("root/[1]")
+
+	ruleContext := NewRuleContext(WithDebugger(debugger))
+	assert.NoError(t, BestFirstRuleRunner(ruleContext, NewBestFirstRule().WithName("root").AddChildren(
+		NewBestFirstRule().OnEval(func(Context) bool { return false }),
+		NewBestFirstRule(),
+	)))
+
+	assert.Equal(t, []string{"root/[1]"}, paused)
+}
@@ -0,0 +1,48 @@
+package rule
+
+// Decision is the final answer of a run: the decided value, the reason codes
+// backing it, its confidence and the rule that decided it.
+type Decision[T any] struct {
+	Value      T
+	Reasons    []string
+	Confidence float64
+	// Rule is the rule that set the decision, or nil outside of a run or when
+	// the decision policy aggregated several decisions into one.
+	Rule Context
+}
+
+// SetDecision records value as a decision of the run with a confidence between
+// 0 and 1, and attaches the reason codes to the run, both on behalf of the
+// current rule. It records them as RuleContext.SetDecision and AddReason do,
+// so they are rolled back, merged and aggregated in the same way by every
+// runner. It panics if the confidence is out of range.
+func SetDecision[T any](rc *RuleContext, value T, confidence float64, reasons ...string) {
+	rc.SetDecision(value, confidence)
+	for _, code := range reasons {
+		rc.AddReason(code)
+	}
+}
+
+// GetDecision returns the decision of the run, aggregated by the decision
+// policy as RuleContext.Decision does, with the reason codes attached by the
+// rule that decided it, or all the reason codes of the run when it has no
+// rule. It returns false when no decision was recorded or its value is not a
+// T.
+func GetDecision[T any](rc *RuleContext) (Decision[T], bool) {
+	var scored, ok = rc.Decision()
+	if !ok {
+		return Decision[T]{}, false
+	}
+	value, ok := scored.Value.(T)
+	if !ok {
+		return Decision[T]{}, false
+	}
+
+	var d = Decision[T]{Value: value, Confidence: scored.Confidence, Rule: scored.Rule}
+	for _, r := range rc.reasons {
+		if scored.Rule == nil || r.Rule == scored.Rule {
+			d.Reasons = append(d.Reasons, r.Code)
+		}
+	}
+	return d, true
+}
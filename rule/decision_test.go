@@ -0,0 +1,88 @@
+package rule
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type verdict string
+
+func verdictRules() []*BaseRule[BestFirstRule] {
+	return []*BaseRule[BestFirstRule]{
+		NewBestFirstRule().WithName("blocked").
+			OnEval(func(ctx Context) bool { return ctx.GetRuleContext().Get("blocked") == true }).
+			OnExecute(func(ctx Context) {
+				SetDecision(ctx.GetRuleContext(), verdict("deny"), 1, "BLOCKLIST")
+			}),
+		NewBestFirstRule().WithName("scored").OnExecute(func(ctx Context) {
+			ctx.GetRuleContext().AddReason("SCORED")
+			SetDecision(ctx.GetRuleContext(), verdict("review"), 0.7, "LOW_SCORE", "NEW_DEVICE")
+		}),
+	}
+}
+
+func TestGetDecision(t *testing.T) {
+	ruleContext := NewRuleContext()
+	ruleContext.AddReason("INPUT")
+	assert.NoError(t, BestFirstRuleRunner(ruleContext, verdictRules()...))
+
+	decision, ok := GetDecision[verdict](ruleContext)
+	assert.True(t, ok)
+	assert.Equal(t, verdict("review"), decision.Value)
+	assert.Equal(t, 0.7, decision.Confidence)
+	assert.Equal(t, []string{"SCORED", "LOW_SCORE", "NEW_DEVICE"}, decision.Reasons)
+	assert.Equal(t, "scored", decision.Rule.(Named).GetName())
+
+	ruleContext = NewRuleContext()
+	ruleContext.Set("blocked", true)
+	assert.NoError(t, BestFirstRuleRunner(ruleContext, verdictRules()...))
+	decision, _ = GetDecision[verdict](ruleContext)
+	assert.Equal(t, Decision[verdict]{Value: "deny", Reasons: []string{"BLOCKLIST"}, Confidence: 1, Rule: decision.Rule}, decision)
+}
+
+func TestGetDecision_None(t *testing.T) {
+	_, ok := GetDecision[verdict](NewRuleContext())
+	assert.False(t, ok)
+
+	ruleContext := NewRuleContext()
+	SetDecision(ruleContext, "deny", 1)
+	_, ok = GetDecision[verdict](ruleContext)
+	assert.False(t, ok)
+}
+
+func TestGetDecision_Aggregated(t *testing.T) {
+	ruleContext := NewRuleContext(WithDecisionPolicy(WeightedAverage))
+	assert.NoError(t, ParallelRuleRunner(ruleContext, NewParallelRule[ParallelRule]().AddChildren(
+		NewParallelRule[ParallelRule]().OnExecute(func(ctx Context) {
+			SetDecision(ctx.GetRuleContext(), 80.0, 0.9, "VELOCITY")
+		}),
+		NewParallelRule[ParallelRule]().OnExecute(func(ctx Context) {
+			SetDecision(ctx.GetRuleContext(), 20.0, 0.3, "DEVICE")
+		}),
+	)))
+
+	decision, ok := GetDecision[float64](ruleContext)
+	assert.True(t, ok)
+	assert.InDelta(t, 65.0, decision.Value, 1e-9)
+	assert.Equal(t, []string{"VELOCITY", "DEVICE"}, decision.Reasons)
+	assert.Nil(t, decision.Rule)
+}
+
+func TestGetDecision_Backtracking(t *testing.T) {
+	var rules = []*BaseRule[BestFirstRule]{
+		NewBestFirstRule().WithName("risky").
+			OnExecute(func(ctx Context) { SetDecision(ctx.GetRuleContext(), verdict("deny"), 1, "RISKY") }).
+			AddChildren(NewBestFirstRule().OnEval(func(Context) bool { return false })),
+		NewBestFirstRule().WithName("safe").
+			OnExecute(func(ctx Context) { SetDecision(ctx.GetRuleContext(), verdict("allow"), 0.8, "SAFE") }),
+	}
+
+	ruleContext := NewRuleContext(WithBacktracking())
+	assert.NoError(t, BestFirstRuleRunner(ruleContext, rules...))
+
+	decision, _ := GetDecision[verdict](ruleContext)
+	assert.Equal(t, verdict("allow"), decision.Value)
+	assert.Equal(t, []string{"SAFE"}, decision.Reasons)
+	assert.Len(t, ruleContext.Decisions(), 1)
+}
@@ -0,0 +1,56 @@
+package rule
+
+// WithDefaults layers default values under the RuleContext: Get returns the
+// default of a key that is not set, and Set overrides it. Later defaults take
+// precedence over earlier ones for the same key.
+func WithDefaults(defaults map[string]interface{}) ContextOption {
+	return func(rc *RuleContext) {
+		rc.addDefaults(defaults)
+	}
+}
+
+// WithDefaults returns a program layering the defaults under the context it
+// runs in, so callers don't need to set common fallback values themselves.
+// Defaults already registered on the context take precedence.
+func (p Program) WithDefaults(defaults map[string]interface{}) Program {
+	return func(rc *RuleContext) error {
+		var previous = rc.defaults
+		rc.defaults = nil
+		rc.addDefaults(defaults)
+		rc.addDefaults(previous)
+		defer func() { rc.defaults = previous }()
+		return p(rc)
+	}
+}
+
+// Defaults returns a copy of the default values of the context.
+func (rc *RuleContext) Defaults() map[string]interface{} {
+	var defaults = make(map[string]interface{}, len(rc.defaults))
+	for k, v := range rc.defaults {
+		defaults[k] = v
+	}
+	return defaults
+}
+
+func (rc *RuleContext) addDefaults(defaults map[string]interface{}) {
+	if len(defaults) == 0 {
+		return
+	}
+	if rc.defaults == nil {
+		rc.defaults = make(map[string]interface{}, len(defaults))
+	}
+	for k, v := range defaults {
+		rc.defaults[k] = v
+	}
+}
+
+func (rc *RuleContext) lookup(key string) (interface{}, bool) {
+	if value, ok := rc.context[key]; ok {
+		return value, true
+	}
+	if value, ok := rc.defaults[key]; ok {
+		return value, true
+	}
+	value, ok := rc.global[key]
+	return value, ok
+}
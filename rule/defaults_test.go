@@ -0,0 +1,41 @@
+package rule
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithDefaults(t *testing.T) {
+	rc := NewRuleContext(WithDefaults(map[string]interface{}{"currency": "EUR", "limit": 100}))
+	rc.Set("limit", 500)
+
+	assert.Equal(t, "EUR", rc.Get("currency"))
+	assert.Equal(t, 500, rc.Get("limit"))
+	assert.Equal(t, map[string]interface{}{"currency": "EUR", "limit": 500}, rc.Values())
+
+	rc.Delete("limit")
+	assert.Equal(t, 100, rc.Get("limit"))
+	assert.Equal(t, map[string]interface{}{"currency": "EUR", "limit": 100}, rc.Defaults())
+}
+
+func TestWithDefaults_StrictKeys(t *testing.T) {
+	var rule = NewChainRule().OnEval(func(ctx Context) bool { return ctx.GetRuleContext().Get("currency") == "EUR" })
+
+	assert.NoError(t, ChainRuleRunner(NewRuleContext(WithStrictKeys(), WithDefaults(map[string]interface{}{"currency": "EUR"})), rule))
+	assert.Error(t, ChainRuleRunner(NewRuleContext(WithStrictKeys()), rule))
+}
+
+func TestProgram_WithDefaults(t *testing.T) {
+	var seen []interface{}
+	var program = Program(func(rc *RuleContext) error {
+		seen = append(seen, rc.Get("currency"), rc.Get("country"))
+		return nil
+	}).WithDefaults(map[string]interface{}{"currency": "USD", "country": "US"})
+
+	rc := NewRuleContext(WithDefaults(map[string]interface{}{"currency": "EUR"}))
+	assert.NoError(t, program(rc))
+
+	assert.Equal(t, []interface{}{"EUR", "US"}, seen)
+	assert.Equal(t, map[string]interface{}{"currency": "EUR"}, rc.Defaults())
+}
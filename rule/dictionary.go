@@ -0,0 +1,104 @@
+package rule
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// KeyDoc documents a context key.
+type KeyDoc struct {
+	Name        string   `json:"name"`
+	Type        string   `json:"type,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Producers   []string `json:"producers,omitempty"`
+	Consumers   []string `json:"consumers,omitempty"`
+}
+
+// DataDictionary documents the context keys used by a rule set.
+type DataDictionary struct {
+	Keys []KeyDoc `json:"keys"`
+}
+
+// NewDataDictionary builds a data dictionary from the keys declared in a
+// KeySet, which may be nil, and the reads and writes traced by runs recorded
+// with WithHistory. Producers and consumers are listed by rule name.
+func NewDataDictionary(ks *KeySet, runs ...*RuleContext) *DataDictionary {
+	var docs = make(map[string]*KeyDoc)
+	var doc = func(key string) *KeyDoc {
+		if d, ok := docs[key]; ok {
+			return d
+		}
+		docs[key] = &KeyDoc{Name: key}
+		return docs[key]
+	}
+
+	if ks != nil {
+		for _, info := range ks.Keys() {
+			var d = doc(info.Name)
+			d.Description = info.Description
+			if info.Type != nil {
+				d.Type = info.Type.String()
+			}
+		}
+	}
+
+	for _, rc := range runs {
+		for _, entry := range rc.History() {
+			var d = doc(entry.Key)
+			if d.Type == "" && !entry.Deleted && entry.Value != nil {
+				d.Type = fmt.Sprintf("%T", entry.Value)
+			}
+			if entry.Rule != nil {
+				d.Producers = appendUnique(d.Producers, dictionaryName(entry.Rule))
+			}
+		}
+		for _, read := range rc.Reads() {
+			var d = doc(read.Key)
+			d.Consumers = appendUnique(d.Consumers, dictionaryName(read.Rule))
+		}
+	}
+
+	var dictionary = &DataDictionary{Keys: make([]KeyDoc, 0, len(docs))}
+	for _, d := range docs {
+		sort.Strings(d.Producers)
+		sort.Strings(d.Consumers)
+		dictionary.Keys = append(dictionary.Keys, *d)
+	}
+	sort.Slice(dictionary.Keys, func(i, j int) bool { return dictionary.Keys[i].Name < dictionary.Keys[j].Name })
+	return dictionary
+}
+
+// Markdown renders the dictionary as a Markdown table.
+func (d *DataDictionary) Markdown() string {
+	var b strings.Builder
+	b.WriteString("| Key | Type | Description | Producers | Consumers |\n")
+	b.WriteString("| --- | --- | --- | --- | --- |\n")
+	for _, k := range d.Keys {
+		fmt.Fprintf(&b, "| `%s` | %s | %s | %s | %s |\n",
+			k.Name, k.Type, k.Description, strings.Join(k.Producers, ", "), strings.Join(k.Consumers, ", "))
+	}
+	return b.String()
+}
+
+// JSON renders the dictionary as indented JSON.
+func (d *DataDictionary) JSON() ([]byte, error) {
+	return json.MarshalIndent(d, "", "  ")
+}
+
+func dictionaryName(r Context) string {
+	if name := nameOf(r); name != "" {
+		return name
+	}
+	return "(unnamed)"
+}
+
+func appendUnique(list []string, s string) []string {
+	for _, v := range list {
+		if v == s {
+			return list
+		}
+	}
+	return append(list, s)
+}
@@ -0,0 +1,54 @@
+package rule
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func dictionaryRun() *RuleContext {
+	rule := NewChainRule().WithName("score").
+		OnEval(func(ctx Context) bool { return ctx.GetRuleContext().Get("amount").(int) > 0 }).
+		OnExecute(func(ctx Context) { ctx.GetRuleContext().Set("score", 10) })
+	rule.AddChildren(NewChainRule().WithName("decide").
+		OnExecute(func(ctx Context) {
+			if ctx.GetRuleContext().Get("score").(int) > 5 {
+				ctx.GetRuleContext().Set("decision", "review")
+			}
+		}))
+
+	ruleContext := NewRuleContext(WithHistory())
+	ruleContext.Set("amount", 100)
+	ChainRuleRunner(ruleContext, rule)
+	return ruleContext
+}
+
+func TestNewDataDictionary(t *testing.T) {
+	ks := NewKeySet().Register("amount", 0, "Order amount in cents.")
+
+	dictionary := NewDataDictionary(ks, dictionaryRun())
+
+	assert.Equal(t, []KeyDoc{
+		{Name: "amount", Type: "int", Description: "Order amount in cents.", Consumers: []string{"score"}},
+		{Name: "decision", Type: "string", Producers: []string{"decide"}},
+		{Name: "score", Type: "int", Producers: []string{"score"}, Consumers: []string{"decide"}},
+	}, dictionary.Keys)
+}
+
+func TestDataDictionary_Markdown(t *testing.T) {
+	dictionary := NewDataDictionary(nil, dictionaryRun())
+
+	assert.Equal(t, "| Key | Type | Description | Producers | Consumers |\n"+
+		"| --- | --- | --- | --- | --- |\n"+
+		"| `amount` | int |  |  | score |\n"+
+		"| `decision` | string |  | decide |  |\n"+
+		"| `score` | int |  | score | decide |\n", dictionary.Markdown())
+}
+
+func TestDataDictionary_JSON(t *testing.T) {
+	dictionary := NewDataDictionary(NewKeySet().Register("amount", 0, "Order amount."))
+
+	data, err := dictionary.JSON()
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"keys": [{"name": "amount", "type": "int", "description": "Order amount."}]}`, string(data))
+}
@@ -0,0 +1,334 @@
+package rule
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// ChangeKind is the kind of a structural change between two rule trees.
+type ChangeKind int
+
+const (
+	Added ChangeKind = iota
+	Removed
+	Renamed
+	Modified
+)
+
+func (k ChangeKind) String() string {
+	switch k {
+	case Added:
+		return "added"
+	case Removed:
+		return "removed"
+	case Renamed:
+		return "renamed"
+	case Modified:
+		return "modified"
+	}
+	return fmt.Sprintf("ChangeKind(%d)", int(k))
+}
+
+// Change describes a single difference between two rule trees.
+// Path addresses the rule in the old tree, or in the new tree for added rules.
+type Change struct {
+	Kind    ChangeKind
+	Path    string
+	OldName string
+	NewName string
+	Detail  string
+}
+
+func (c Change) String() string {
+	var s = c.Kind.String() + " " + c.Path
+	if c.Kind == Renamed {
+		s += fmt.Sprintf(" %q -> %q", c.OldName, c.NewName)
+	}
+	if c.Detail != "" {
+		s += ": " + c.Detail
+	}
+	return s
+}
+
+// DiffRules returns the structural differences between two versions of a rule set.
+//
+// Siblings are matched by name, and unnamed or unmatched ones by position, so
+// naming rules with WithName gives the most precise diffs. Conditions set with
+// WhenKeyIn or WhenKeyBetween are compared by value, and hooks by the names
+// they were set with through Use; other conditions and hooks are closures and
+// cannot be compared.
+func DiffRules[T any](a, b []*BaseRule[T]) []Change {
+	return diffSiblings(a, b, "")
+}
+
+// DiffPrograms returns the structural differences between the rule sets of two
+// programs, as DiffRules does. The programs are run with a context in which
+// runners report their rules instead of running them, so a program must hand
+// its rules to a runner before doing anything else, as the programs of
+// NewProgram, NewWarmProgram and CompileCached do. Both programs must run
+// rules of the same kind.
+func DiffPrograms(a, b Program) ([]Change, error) {
+	rulesA, err := inspectProgram(a)
+	if err != nil {
+		return nil, err
+	}
+	rulesB, err := inspectProgram(b)
+	if err != nil {
+		return nil, err
+	}
+	changes, ok := rulesA.diff(rulesB)
+	if !ok {
+		return nil, fmt.Errorf("diff programs: the programs run rules of different kinds")
+	}
+	return changes, nil
+}
+
+// errInspected stops a program once its runner reported its rules.
+var errInspected = errors.New("rule: program inspected")
+
+// ruleSet is the rule set a runner reports to DiffPrograms.
+type ruleSet interface {
+	diff(other ruleSet) ([]Change, bool)
+}
+
+type rulesOf[T any] []*BaseRule[T]
+
+func (a rulesOf[T]) diff(other ruleSet) ([]Change, bool) {
+	b, ok := other.(rulesOf[T])
+	if !ok {
+		return nil, false
+	}
+	return DiffRules(a, b), true
+}
+
+// inspected reports the rules to DiffPrograms, when it runs the program, in
+// place of running them.
+func inspected[T any](rc *RuleContext, rules []*BaseRule[T]) bool {
+	if rc == nil || rc.inspect == nil {
+		return false
+	}
+	rc.inspect(rulesOf[T](rules))
+	return true
+}
+
+func inspectProgram(p Program) (ruleSet, error) {
+	var rules ruleSet
+	var rc = NewRuleContext()
+	rc.inspect = func(r ruleSet) {
+		if rules == nil {
+			rules = r
+		}
+	}
+	if err := p(rc); rules == nil {
+		if err != nil {
+			return nil, fmt.Errorf("diff programs: %w", err)
+		}
+		return nil, fmt.Errorf("diff programs: program does not run its rules with a runner")
+	}
+	return rules, nil
+}
+
+func diffSiblings[T any](a, b []*BaseRule[T], parent string) []Change {
+	var changes []Change
+	var matchedA = make([]int, len(a))
+	var matchedB = make([]bool, len(b))
+	for i := range matchedA {
+		matchedA[i] = -1
+	}
+
+	for i, ra := range a {
+		if ra.name == "" {
+			continue
+		}
+		for j, rb := range b {
+			if !matchedB[j] && rb.name == ra.name {
+				matchedA[i], matchedB[j] = j, true
+				break
+			}
+		}
+	}
+
+	var pendingB []int
+	for j := range b {
+		if !matchedB[j] {
+			pendingB = append(pendingB, j)
+		}
+	}
+
+	for i, ra := range a {
+		var j = matchedA[i]
+		if j < 0 {
+			if len(pendingB) == 0 {
+				changes = append(changes, Change{Kind: Removed, Path: diffPath(parent, ra, i), OldName: ra.name})
+				continue
+			}
+			j, pendingB = pendingB[0], pendingB[1:]
+		}
+
+		var rb = b[j]
+		var path = diffPath(parent, ra, i)
+		if ra.name != rb.name {
+			changes = append(changes, Change{Kind: Renamed, Path: path, OldName: ra.name, NewName: rb.name})
+		}
+		if detail := diffRule(ra, rb); detail != "" {
+			changes = append(changes, Change{Kind: Modified, Path: path, OldName: ra.name, NewName: rb.name, Detail: detail})
+		}
+		changes = append(changes, diffSiblings(ra.children, rb.children, path)...)
+		changes = append(changes, diffSiblings(ra.elseChildren, rb.elseChildren, path+"/else")...)
+	}
+
+	for _, j := range pendingB {
+		changes = append(changes, Change{Kind: Added, Path: diffPath(parent, b[j], j), NewName: b[j].name})
+	}
+	return changes
+}
+
+func diffRule[T any](a, b *BaseRule[T]) string {
+	var details []string
+	if a.ruleType != b.ruleType {
+		details = append(details, "rule type changed")
+	}
+	if a.annotation != b.annotation {
+		details = append(details, fmt.Sprintf("annotation changed from %t to %t", a.annotation, b.annotation))
+	}
+	if !reflect.DeepEqual(a.dispatch, b.dispatch) {
+		details = append(details,
+			fmt.Sprintf("condition changed from %s to %s", describeCond(a.dispatch), describeCond(b.dispatch)))
+	}
+	if a.switchKey != b.switchKey {
+		details = append(details, fmt.Sprintf("switch key changed from %q to %q", a.switchKey, b.switchKey))
+	}
+	if describeScore(a) != describeScore(b) {
+		details = append(details, fmt.Sprintf("score changed from %s to %s", describeScore(a), describeScore(b)))
+	}
+	if (a.assert == nil) != (b.assert == nil) {
+		details = append(details, fmt.Sprintf("assertion changed from %t to %t", a.assert != nil, b.assert != nil))
+	}
+	if a.priority != b.priority {
+		details = append(details, fmt.Sprintf("priority changed from %d to %d", a.priority, b.priority))
+	}
+	if describeCanary(a.canary) != describeCanary(b.canary) {
+		details = append(details, fmt.Sprintf("canary changed from %s to %s", describeCanary(a.canary), describeCanary(b.canary)))
+	}
+	if a.cost != b.cost {
+		details = append(details, fmt.Sprintf("cost changed from %v to %v", a.cost, b.cost))
+	}
+	if a.optional != b.optional {
+		details = append(details, fmt.Sprintf("optional changed from %t to %t", a.optional, b.optional))
+	}
+	details = append(details, diffHooks(a, b)...)
+	if (a.onElse == nil) != (b.onElse == nil) {
+		details = append(details, fmt.Sprintf("else action changed from %t to %t", a.onElse != nil, b.onElse != nil))
+	}
+	if a.ruleType == VotingRuleType && b.ruleType == VotingRuleType && a.quorum != b.quorum {
+		details = append(details, fmt.Sprintf("quorum changed from %s to %s", a.quorum, b.quorum))
+	}
+	if a.maxIterations != b.maxIterations {
+		details = append(details, fmt.Sprintf("max iterations changed from %d to %d", a.maxIterations, b.maxIterations))
+	}
+	if a.weightOf() != b.weightOf() {
+		details = append(details, fmt.Sprintf("weight changed from %d to %d", a.weightOf(), b.weightOf()))
+	}
+	if !reflect.DeepEqual(a.produces, b.produces) {
+		details = append(details, fmt.Sprintf("produced keys changed from %q to %q", a.produces, b.produces))
+	}
+	if !reflect.DeepEqual(a.states, b.states) {
+		details = append(details, fmt.Sprintf("handled states changed from %q to %q", a.states, b.states))
+	}
+	if a.indexChildren != b.indexChildren {
+		details = append(details, fmt.Sprintf("dispatch index changed from %t to %t", a.indexChildren, b.indexChildren))
+	}
+	return strings.Join(details, "; ")
+}
+
+func describeScore[T any](r *BaseRule[T]) string {
+	if r.onEvalScore == nil {
+		return "none"
+	}
+	return fmt.Sprintf("above %v", r.minScore)
+}
+
+func describeCanary(percent *float64) string {
+	if percent == nil {
+		return "none"
+	}
+	return fmt.Sprintf("%v%%", *percent)
+}
+
+// diffHooks compares the names of the hooks set with Use. Hooks set as Go
+// functions, or not set, on both sides can't be told apart and are reported as
+// unchanged; declared conditions are compared by diffRule instead.
+func diffHooks[T any](a, b *BaseRule[T]) []string {
+	var phases = make(map[Phase]bool)
+	for phase := range a.hookNames {
+		phases[phase] = true
+	}
+	for phase := range b.hookNames {
+		phases[phase] = true
+	}
+	var sorted = make([]Phase, 0, len(phases))
+	for phase := range phases {
+		if phase != PhaseEval || a.dispatch == nil && b.dispatch == nil {
+			sorted = append(sorted, phase)
+		}
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	var details []string
+	for _, phase := range sorted {
+		var nameA, setA = a.hookNames[phase]
+		var nameB, setB = b.hookNames[phase]
+		if nameA == nameB {
+			continue
+		}
+		details = append(details, fmt.Sprintf("%s hook changed from %s to %s", phase, describeHook(nameA, setA), describeHook(nameB, setB)))
+	}
+	return details
+}
+
+func describeHook(name string, set bool) string {
+	switch {
+	case !set:
+		return "none"
+	case name == "":
+		return "unnamed"
+	}
+	return fmt.Sprintf("%q", name)
+}
+
+func describeCond(c *dispatchCond) string {
+	switch {
+	case c == nil:
+		return "custom"
+	case c.otherwise:
+		return fmt.Sprintf("%s otherwise", c.key)
+	case c.isRange:
+		return fmt.Sprintf("%s in [%v, %v)", c.key, c.min, c.max)
+	}
+
+	var values = make([]string, 0, len(c.set))
+	for v := range c.set {
+		values = append(values, describeValue(v))
+	}
+	sort.Strings(values)
+	return fmt.Sprintf("%s in {%s}", c.key, strings.Join(values, ", "))
+}
+
+// describeValue formats a condition value with its type, unless it is a
+// string, so that 1 and 1.0 read differently.
+func describeValue(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return fmt.Sprintf("%q", s)
+	}
+	return fmt.Sprintf("%T(%v)", v, v)
+}
+
+func diffPath[T any](parent string, r *BaseRule[T], i int) string {
+	if parent == "" {
+		return pathSegment(r, i)
+	}
+	return pathJoin(parent, r, i)
+}
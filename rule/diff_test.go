@@ -0,0 +1,145 @@
+package rule
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffRules_Identical(t *testing.T) {
+	build := func() *BaseRule[BestFirstRule] {
+		return NewBestFirstRule().WithName("root").AddChildren(
+			NewBestFirstRule().WithName("eu").WhenKeyIn("region", "eu"),
+			NewBestFirstRule().WithName("us").WhenKeyIn("region", "us"),
+		)
+	}
+
+	assert.Empty(t, DiffRules([]*BaseRule[BestFirstRule]{build()}, []*BaseRule[BestFirstRule]{build()}))
+}
+
+func TestDiffRules_Changes(t *testing.T) {
+	a := NewBestFirstRule().WithName("root").AddChildren(
+		NewBestFirstRule().WithName("eu").WhenKeyIn("region", "eu"),
+		NewBestFirstRule().WithName("us").WhenKeyIn("region", "us"),
+		NewBestFirstRule().WithName("small").WhenKeyBetween("amount", 0, 100),
+	)
+	b := NewBestFirstRule().WithName("root").AddChildren(
+		NewBestFirstRule().WithName("eu").WhenKeyIn("region", "eu", "uk"),
+		NewBestFirstRule().WithName("tiny").WhenKeyBetween("amount", 0, 100),
+		NewBestFirstRule().WithName("latam").WhenKeyIn("region", "br"),
+		NewBestFirstRule().WithName("apac").WhenKeyIn("region", "jp"),
+	).WithDispatchIndex()
+
+	changes := DiffRules([]*BaseRule[BestFirstRule]{a}, []*BaseRule[BestFirstRule]{b})
+
+	var got []string
+	for _, c := range changes {
+		got = append(got, c.String())
+	}
+	assert.Equal(t, []string{
+		"modified root: dispatch index changed from false to true",
+		`modified root/eu: condition changed from region in {"eu"} to region in {"eu", "uk"}`,
+		`renamed root/us "us" -> "tiny"`,
+		`modified root/us: condition changed from region in {"us"} to amount in [0, 100)`,
+		`renamed root/small "small" -> "latam"`,
+		`modified root/small: condition changed from amount in [0, 100) to region in {"br"}`,
+		"added root/apac",
+	}, got)
+}
+
+func TestDiffRules_UnnamedByPosition(t *testing.T) {
+	a := NewChainRule().AddChildren(NewChainRule())
+	b := NewChainRule()
+
+	changes := DiffRules([]*BaseRule[ChainRule]{a}, []*BaseRule[ChainRule]{b})
+
+	assert.Equal(t, []Change{{Kind: Removed, Path: "[0]/[0]"}}, changes)
+}
+
+func TestDiffRules_Settings(t *testing.T) {
+	var funcs = NewFuncs().
+		Register("notify", func(Context) {}).
+		Register("page", func(Context) {}).
+		Register("vip", func(Context) bool { return true })
+	a := []*BaseRule[BestFirstRule]{
+		NewBestFirstRule().WithName("alert").Use(funcs, PhaseExecute, "notify").Use(funcs, PhaseEval, "vip"),
+		NewBestFirstRule().WithName("enrich").OnExecute(func(Context) {}),
+	}
+	b := []*BaseRule[BestFirstRule]{
+		NewBestFirstRule().WithName("alert").Use(funcs, PhaseExecute, "page").OnEval(func(Context) bool { return true }).Canary(10),
+		NewBestFirstRule().WithName("enrich").OnExecute(func(Context) {}).Cost(2).Optional(),
+	}
+
+	var got []string
+	for _, c := range DiffRules(a, b) {
+		got = append(got, c.String())
+	}
+	assert.Equal(t, []string{
+		`modified alert: canary changed from none to 10%; eval hook changed from "vip" to unnamed; execute hook changed from "notify" to "page"`,
+		"modified enrich: cost changed from 0 to 2; optional changed from false to true",
+	}, got)
+}
+
+func TestDiffRules_Priority(t *testing.T) {
+	a := []*BaseRule[BestFirstRule]{NewBestFirstRule().WithName("vip")}
+	b := []*BaseRule[BestFirstRule]{NewBestFirstRule().WithName("vip").WithPriority(10)}
+
+	assert.Equal(t, []Change{{Kind: Modified, Path: "vip", OldName: "vip", NewName: "vip", Detail: "priority changed from 0 to 10"}},
+		DiffRules(a, b))
+}
+
+func TestDiffRules_TypedValues(t *testing.T) {
+	a := []*BaseRule[BestFirstRule]{NewBestFirstRule().WithName("n").WhenKeyIn("n", 1)}
+	b := []*BaseRule[BestFirstRule]{NewBestFirstRule().WithName("n").WhenKeyIn("n", 1.0)}
+
+	assert.Equal(t, "condition changed from n in {int(1)} to n in {float64(1)}", DiffRules(a, b)[0].Detail)
+}
+
+func TestDiffRules_Kinds(t *testing.T) {
+	var score = func(Context) float64 { return 1 }
+	a := []*BaseRule[ChainRule]{
+		NewLoopRule[ChainRule](3).WithName("retry"),
+		NewSwitchRule[ChainRule]("region").WithName("route"),
+		NewChainRule().WithName("rank").OnEvalScore(score),
+		NewChainRule().WithName("guard"),
+	}
+	b := []*BaseRule[ChainRule]{
+		NewLoopRule[ChainRule](5).WithName("retry"),
+		NewSwitchRule[ChainRule]("country").WithName("route"),
+		NewChainRule().WithName("rank").OnEvalScore(score).WithMinScore(0.5),
+		NewAssertRule[ChainRule]("positive").WithName("guard"),
+	}
+
+	var got []string
+	for _, c := range DiffRules(a, b) {
+		got = append(got, c.String())
+	}
+	assert.Equal(t, []string{
+		"modified retry: max iterations changed from 3 to 5",
+		`modified route: switch key changed from "region" to "country"`,
+		"modified rank: score changed from above 0 to above 0.5",
+		"modified guard: assertion changed from false to true",
+	}, got)
+}
+
+func TestDiffPrograms(t *testing.T) {
+	a := NewProgram(NewBestFirstRule().WithName("vip"), NewBestFirstRule().WithName("default"))
+	b := NewProgram(NewBestFirstRule().WithName("vip").WithPriority(10))
+
+	changes, err := DiffPrograms(a, b)
+	assert.NoError(t, err)
+	assert.Equal(t, []Change{
+		{Kind: Modified, Path: "vip", OldName: "vip", NewName: "vip", Detail: "priority changed from 0 to 10"},
+		{Kind: Removed, Path: "default", OldName: "default"},
+	}, changes)
+
+	changes, err = DiffPrograms(NewProgram[BestFirstRule](), b)
+	assert.NoError(t, err)
+	assert.Equal(t, []Change{{Kind: Added, Path: "vip", NewName: "vip"}}, changes)
+
+	_, err = DiffPrograms(a, NewProgram(NewChainRule()))
+	assert.EqualError(t, err, "diff programs: the programs run rules of different kinds")
+
+	_, err = DiffPrograms(a, func(*RuleContext) error { return nil })
+	assert.EqualError(t, err, "diff programs: program does not run its rules with a runner")
+}
@@ -0,0 +1,189 @@
+package rule
+
+import (
+	"reflect"
+	"sort"
+)
+
+// dispatchCond is the declarative form of an OnEval condition set through
+// WhenKeyIn or WhenKeyBetween, or of the default case of a switch rule, which
+// lets sibling rules be indexed.
+type dispatchCond struct {
+	key       string
+	set       map[interface{}]struct{}
+	isRange   bool
+	min, max  float64
+	otherwise bool
+}
+
+func (c *dispatchCond) eval(ctx Context) bool {
+	if c.otherwise {
+		return true
+	}
+	var value = ctx.GetRuleContext().Get(c.key)
+	if c.isRange {
+		f, ok := toFloat(value)
+		return ok && f >= c.min && f < c.max
+	}
+	if !isHashable(value) {
+		return false
+	}
+	_, ok := c.set[value]
+	return ok
+}
+
+// WhenKeyIn sets the evaluation function of the rule to report whether the
+// value stored under key equals one of values.
+// Sibling rules using WhenKeyIn on the same key can be dispatched through an
+// index, see WithDispatchIndex.
+func (r *BaseRule[T]) WhenKeyIn(key string, values ...interface{}) *BaseRule[T] {
+	var cond = &dispatchCond{key: key, set: make(map[interface{}]struct{}, len(values))}
+	for _, v := range values {
+		cond.set[v] = struct{}{}
+	}
+	r.OnEval(cond.eval)
+	r.dispatch = cond
+	return r
+}
+
+// WhenKeyBetween sets the evaluation function of the rule to report whether
+// the numeric value stored under key is within [min, max).
+// Sibling rules using WhenKeyBetween on the same key can be dispatched through
+// an index, see WithDispatchIndex.
+func (r *BaseRule[T]) WhenKeyBetween(key string, min, max float64) *BaseRule[T] {
+	var cond = &dispatchCond{key: key, isRange: true, min: min, max: max}
+	r.OnEval(cond.eval)
+	r.dispatch = cond
+	return r
+}
+
+// WithDispatchIndex makes a BestFirstRule jump directly to the child matching
+// the context instead of evaluating its children one by one.
+// The index is built on first use when every child was set up with WhenKeyIn
+// or WhenKeyBetween on the same key; otherwise, or when a child is a canary,
+// optional, handles states or has an else branch, children are evaluated in
+// order. Such children may be skipped at run time, where the next sibling
+// must be tried, and the index jumps over the children it doesn't select,
+// while evaluating in order stops at the first child taking its else branch.
+func (r *BaseRule[T]) WithDispatchIndex() *BaseRule[T] {
+	r.indexChildren = true
+	r.index = nil
+	return r
+}
+
+// dispatchChild returns the first child, in priority order, whose condition
+// matches the context. The boolean result is false when the children cannot
+// be indexed.
+func (r *BaseRule[T]) dispatchChild() (*BaseRule[T], bool) {
+	var children = byPriority(r.children)
+	if r.index == nil {
+		r.index = buildDispatchIndex(children)
+	}
+	if !r.index.ok {
+		return nil, false
+	}
+
+	var i = r.index.lookup(r.GetRuleContext().Get(r.index.key))
+	if i < 0 {
+		return nil, true
+	}
+	return children[i], true
+}
+
+// dispatchIndex maps a key value to the position of the first sibling whose
+// condition matches it. Set conditions are kept in a hash map and range
+// conditions are split into sorted, non-overlapping segments; values matching
+// neither go to the first default case, if any.
+type dispatchIndex struct {
+	ok        bool
+	key       string
+	values    map[interface{}]int
+	bounds    []float64
+	segments  []int
+	otherwise int
+}
+
+func buildDispatchIndex[T any](rules []*BaseRule[T]) *dispatchIndex {
+	if len(rules) == 0 || rules[0].dispatch == nil {
+		return &dispatchIndex{}
+	}
+
+	var idx = &dispatchIndex{ok: true, key: rules[0].dispatch.key, values: make(map[interface{}]int), otherwise: -1}
+	var ranges []int
+	for i, r := range rules {
+		var cond = r.dispatch
+		if cond == nil || cond.key != idx.key || len(r.states) > 0 || r.canary != nil || r.optional || r.hasElse() {
+			return &dispatchIndex{}
+		}
+
+		if cond.otherwise {
+			if idx.otherwise < 0 {
+				idx.otherwise = i
+			}
+			continue
+		}
+		if cond.isRange {
+			idx.bounds = append(idx.bounds, cond.min, cond.max)
+			ranges = append(ranges, i)
+			continue
+		}
+		for v := range cond.set {
+			if _, ok := idx.values[v]; !ok {
+				idx.values[v] = i
+			}
+		}
+	}
+
+	sort.Float64s(idx.bounds)
+	idx.segments = make([]int, len(idx.bounds))
+	for s := range idx.segments {
+		idx.segments[s] = -1
+		for _, i := range ranges {
+			var cond = rules[i].dispatch
+			if cond.min <= idx.bounds[s] && idx.bounds[s] < cond.max {
+				idx.segments[s] = i
+				break
+			}
+		}
+	}
+	return idx
+}
+
+// lookup returns the position of the first matching sibling, or -1.
+func (idx *dispatchIndex) lookup(value interface{}) int {
+	var found = -1
+	if isHashable(value) {
+		if i, ok := idx.values[value]; ok {
+			found = i
+		}
+	}
+
+	if f, ok := toFloat(value); ok && len(idx.bounds) > 0 {
+		var s = sort.Search(len(idx.bounds), func(s int) bool { return idx.bounds[s] > f }) - 1
+		if s >= 0 && idx.segments[s] >= 0 && (found < 0 || idx.segments[s] < found) {
+			found = idx.segments[s]
+		}
+	}
+	if found < 0 {
+		return idx.otherwise
+	}
+	return found
+}
+
+func isHashable(value interface{}) bool {
+	return value == nil || reflect.TypeOf(value).Comparable()
+}
+
+// toFloat converts any Go numeric value to float64.
+func toFloat(value interface{}) (float64, bool) {
+	var v = reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	}
+	return 0, false
+}
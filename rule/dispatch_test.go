@@ -0,0 +1,211 @@
+package rule
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWhenKeyIn(t *testing.T) {
+	rule := NewBestFirstRule().WhenKeyIn("country", "BR", "PT")
+
+	rule.GetRuleContext().Set("country", "PT")
+	assert.True(t, rule.eval())
+
+	rule.GetRuleContext().Set("country", "US")
+	assert.False(t, rule.eval())
+
+	rule.GetRuleContext().Set("country", []string{"BR"})
+	assert.False(t, rule.eval())
+}
+
+func TestWhenKeyBetween(t *testing.T) {
+	rule := NewBestFirstRule().WhenKeyBetween("age", 18, 65)
+
+	rule.GetRuleContext().Set("age", 18)
+	assert.True(t, rule.eval())
+
+	rule.GetRuleContext().Set("age", 65.0)
+	assert.False(t, rule.eval())
+
+	rule.GetRuleContext().Set("age", "30")
+	assert.False(t, rule.eval())
+}
+
+func TestOnEval_ClearsDispatchCondition(t *testing.T) {
+	rule := NewBestFirstRule().WhenKeyIn("country", "BR")
+	rule.OnEval(func(ctx Context) bool { return true })
+
+	assert.Nil(t, rule.dispatch)
+}
+
+func dispatchRules(ruleContext *RuleContext) *BaseRule[BestFirstRule] {
+	root := NewBestFirstRule().WithDispatchIndex()
+	for _, c := range []string{"BR", "PT", "US", "BR"} {
+		var name = c
+		root.AddChildren(NewBestFirstRule().WhenKeyIn("country", name).OnExecute(func(ctx Context) {
+			ctx.GetRuleContext().Set("executed", append(ctx.GetRuleContext().Get("executed").([]string), name))
+		}))
+	}
+	ruleContext.Set("executed", []string{})
+	return root
+}
+
+func TestWithDispatchIndex_RunsFirstMatchingChild(t *testing.T) {
+	ruleContext := NewRuleContext()
+	root := dispatchRules(ruleContext)
+	ruleContext.Set("country", "BR")
+
+	BestFirstRuleRunner(ruleContext, root)
+
+	assert.Equal(t, []string{"BR"}, ruleContext.Get("executed"))
+	assert.True(t, root.index.ok)
+}
+
+func TestWithDispatchIndex_NoMatch(t *testing.T) {
+	ruleContext := NewRuleContext()
+	root := dispatchRules(ruleContext)
+	ruleContext.Set("country", "AR")
+
+	BestFirstRuleRunner(ruleContext, root)
+
+	assert.Equal(t, []string{}, ruleContext.Get("executed"))
+}
+
+func TestWithDispatchIndex_Canary(t *testing.T) {
+	for _, index := range []bool{false, true} {
+		root := NewBestFirstRule().AddChildren(
+			NewBestFirstRule().WhenKeyIn("country", "BR").Canary(0).OnExecute(func(ctx Context) {
+				ctx.GetRuleContext().Set("executed", "a")
+			}),
+			NewBestFirstRule().WhenKeyIn("country", "BR").OnExecute(func(ctx Context) {
+				ctx.GetRuleContext().Set("executed", "b")
+			}),
+		)
+		if index {
+			root.WithDispatchIndex()
+		}
+		ruleContext := NewRuleContext()
+		ruleContext.Set("country", "BR")
+
+		assert.NoError(t, BestFirstRuleRunner(ruleContext, root))
+		assert.Equal(t, "b", ruleContext.Get("executed"), "index %t", index)
+	}
+}
+
+func TestWithDispatchIndex_Optional(t *testing.T) {
+	for _, index := range []bool{false, true} {
+		root := NewBestFirstRule().AddChildren(
+			NewBestFirstRule().WhenKeyIn("country", "BR").Cost(5).Optional().OnExecute(func(ctx Context) {
+				ctx.GetRuleContext().Set("hit", "a")
+			}),
+			NewBestFirstRule().WhenKeyIn("country", "BR").OnExecute(func(ctx Context) {
+				ctx.GetRuleContext().Set("hit", "b")
+			}),
+		)
+		if index {
+			root.WithDispatchIndex()
+		}
+		ruleContext := NewRuleContext(WithBudget(1))
+		ruleContext.Set("country", "BR")
+
+		assert.NoError(t, BestFirstRuleRunner(ruleContext, root))
+		assert.Equal(t, "b", ruleContext.Get("hit"), "index %t", index)
+	}
+}
+
+func TestWithDispatchIndex_Else(t *testing.T) {
+	for _, index := range []bool{false, true} {
+		root := NewBestFirstRule().AddChildren(
+			NewBestFirstRule().WhenKeyIn("country", "AR").OnElse(func(ctx Context) {
+				ctx.GetRuleContext().Set("executed", "else")
+			}),
+			NewBestFirstRule().WhenKeyIn("country", "BR").OnExecute(func(ctx Context) {
+				ctx.GetRuleContext().Set("executed", "b")
+			}),
+		)
+		if index {
+			root.WithDispatchIndex()
+		}
+		ruleContext := NewRuleContext()
+		ruleContext.Set("country", "BR")
+
+		assert.NoError(t, BestFirstRuleRunner(ruleContext, root))
+		assert.Equal(t, "else", ruleContext.Get("executed"), "index %t", index)
+	}
+}
+
+func TestWithDispatchIndex_Ranges(t *testing.T) {
+	root := NewBestFirstRule().WithDispatchIndex()
+	root.AddChildren(
+		NewBestFirstRule().WhenKeyBetween("score", 0, 50).OnExecute(func(ctx Context) {
+			ctx.GetRuleContext().Set("band", "low")
+		}),
+		NewBestFirstRule().WhenKeyBetween("score", 40, 100).OnExecute(func(ctx Context) {
+			ctx.GetRuleContext().Set("band", "high")
+		}),
+		NewBestFirstRule().WhenKeyIn("score", 45).OnExecute(func(ctx Context) {
+			ctx.GetRuleContext().Set("band", "exact")
+		}),
+	)
+
+	for score, band := range map[interface{}]interface{}{10: "low", 45: "low", 50: "high", 99.5: "high", 100: nil, -1: nil} {
+		ruleContext := NewRuleContext()
+		ruleContext.Set("score", score)
+		BestFirstRuleRunner(ruleContext, root)
+		assert.Equal(t, band, ruleContext.Get("band"), fmt.Sprint(score))
+	}
+}
+
+func TestWithDispatchIndex_FallsBackWhenNotIndexable(t *testing.T) {
+	root := NewBestFirstRule().WithDispatchIndex()
+	root.AddChildren(
+		NewBestFirstRule().WhenKeyIn("country", "BR"),
+		NewBestFirstRule().OnExecute(func(ctx Context) {
+			ctx.GetRuleContext().Set("fallback", true)
+		}),
+	)
+
+	ruleContext := NewRuleContext()
+	BestFirstRuleRunner(ruleContext, root)
+
+	assert.False(t, root.index.ok)
+	assert.True(t, ruleContext.Get("fallback").(bool))
+}
+
+func TestWithDispatchIndex_RebuiltOnAddChildren(t *testing.T) {
+	ruleContext := NewRuleContext()
+	root := dispatchRules(ruleContext)
+	BestFirstRuleRunner(ruleContext, root)
+	assert.NotNil(t, root.index)
+
+	root.AddChildren(NewBestFirstRule().WhenKeyIn("country", "AR"))
+	assert.Nil(t, root.index)
+}
+
+func benchmarkDispatch(b *testing.B, indexed bool) {
+	root := NewBestFirstRule()
+	if indexed {
+		root.WithDispatchIndex()
+	}
+	for i := 0; i < 500; i++ {
+		root.AddChildren(NewBestFirstRule().WhenKeyIn("code", i))
+	}
+
+	ruleContext := NewRuleContext()
+	ruleContext.Set("code", 499)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		BestFirstRuleRunner(ruleContext, root)
+	}
+}
+
+func BenchmarkDispatch_Linear(b *testing.B) {
+	benchmarkDispatch(b, false)
+}
+
+func BenchmarkDispatch_Indexed(b *testing.B) {
+	benchmarkDispatch(b, true)
+}
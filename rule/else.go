@@ -0,0 +1,58 @@
+package rule
+
+// OnElse sets a function run when the OnEval of the rule returns false, in
+// place of the execution hooks.
+//
+// A rule taking its else branch, through OnElse or WithElse, counts as a
+// matched rule for its siblings: a best-first runner stops there.
+func (r *BaseRule[T]) OnElse(f func(Context)) *BaseRule[T] {
+	r.onElse = f
+	r.nameHook(PhaseElse, "")
+	return r
+}
+
+// WithElse adds rules run, after the OnElse function if any, when the OnEval
+// of the rule returns false. They run as the children of the rule would. Like
+// AddChildren, it panics when a chain rule would get more than one; WithElseE
+// returns the error instead.
+func (r *BaseRule[T]) WithElse(rules ...*BaseRule[T]) *BaseRule[T] {
+	if err := r.WithElseE(rules...); err != nil {
+		panic(err.Error())
+	}
+	return r
+}
+
+// WithElseE adds else rules to the rule, as WithElse does, or returns
+// ErrTooManyChildren without adding any when a chain rule would get more than
+// one.
+func (r *BaseRule[T]) WithElseE(rules ...*BaseRule[T]) error {
+	if r.childRuleType() == ChainRuleType && len(r.elseChildren)+len(rules) > 1 {
+		return ErrTooManyChildren
+	}
+	for _, child := range rules {
+		child.parent = r
+	}
+	r.elseChildren = append(r.elseChildren, rules...)
+	return nil
+}
+
+// GetElse returns the rules run when the rule doesn't match.
+func (r *BaseRule[T]) GetElse() []*BaseRule[T] {
+	return r.elseChildren
+}
+
+func (r *BaseRule[T]) hasElse() bool {
+	return r.onElse != nil || len(r.elseChildren) > 0
+}
+
+func (r *BaseRule[T]) runElse() error {
+	if r.onElse != nil {
+		var done = r.timeHook(PhaseElse)
+		r.onElse(r)
+		done()
+		if err := r.failed(PhaseElse); err != nil {
+			return err
+		}
+	}
+	return RuleRunner(r.childRuleType(), r.GetRuleContext(), r.elseChildren...)
+}
@@ -0,0 +1,94 @@
+package rule
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func approval() *BaseRule[BestFirstRule] {
+	return NewBestFirstRule().WithName("approve").
+		OnEval(func(ctx Context) bool { return ctx.GetRuleContext().Get("score").(int) > 50 }).
+		OnExecute(func(ctx Context) { ctx.GetRuleContext().Set("decision", "approved") }).
+		OnElse(func(ctx Context) { ctx.GetRuleContext().Set("decision", "declined") })
+}
+
+func TestOnElse(t *testing.T) {
+	var sibling = NewBestFirstRule().OnExecute(func(ctx Context) { ctx.GetRuleContext().Set("sibling", true) })
+
+	ruleContext := NewRuleContext()
+	ruleContext.Set("score", 10)
+	assert.NoError(t, BestFirstRuleRunner(ruleContext, approval(), sibling))
+	assert.Equal(t, "declined", ruleContext.Get("decision"))
+	assert.Nil(t, ruleContext.Get("sibling"))
+
+	ruleContext.Set("score", 90)
+	assert.NoError(t, BestFirstRuleRunner(ruleContext, approval(), sibling))
+	assert.Equal(t, "approved", ruleContext.Get("decision"))
+}
+
+func TestWithElse(t *testing.T) {
+	var review = NewBestFirstRule().WithName("review").
+		OnExecute(func(ctx Context) { ctx.GetRuleContext().Set("decision", "review") })
+	var root = approval().OnElse(nil).WithElse(
+		NewBestFirstRule().OnEval(func(Context) bool { return false }),
+		review,
+	)
+
+	ruleContext := NewRuleContext()
+	ruleContext.Set("score", 10)
+	assert.NoError(t, BestFirstRuleRunner(ruleContext, root))
+	assert.Equal(t, "review", ruleContext.Get("decision"))
+
+	assert.Equal(t, "approve/else/review", review.Path())
+	assert.Same(t, review, FindPath("approve/else/review", root))
+	assert.Same(t, root.GetElse()[0], FindPath("approve/else/[0]", root))
+	assert.Nil(t, FindPath("approve/else/missing", root))
+
+	var out strings.Builder
+	assert.NoError(t, PrintTree(&out, root))
+	assert.Equal(t, "approve\n  else\n    (unnamed)\n    review\n", out.String())
+
+	var clone = root.Clone()
+	assert.Equal(t, "approve/else/review", clone.GetElse()[1].Path())
+	assert.NotSame(t, review, clone.GetElse()[1])
+
+	review.Detach()
+	assert.Equal(t, 1, len(root.GetElse()))
+}
+
+func TestWithElse_Chain(t *testing.T) {
+	var root = NewChainRule().OnEval(func(Context) bool { return false }).
+		WithElse(NewChainRule().OnExecute(func(ctx Context) { ctx.GetRuleContext().Set("else", true) }))
+
+	ruleContext := NewRuleContext()
+	assert.NoError(t, ChainRuleRunner(ruleContext, root))
+	assert.Equal(t, true, ruleContext.Get("else"))
+
+	assert.PanicsWithValue(t, "ChainRule can only have one child", func() { root.WithElse(NewChainRule()) })
+	assert.ErrorIs(t, root.WithElseE(NewChainRule()), ErrTooManyChildren)
+	assert.Len(t, root.GetElse(), 1)
+}
+
+func TestOnElse_StrictKeys(t *testing.T) {
+	ruleContext := NewRuleContext(WithStrictKeys())
+	var err = ChainRuleRunner(ruleContext, NewChainRule().WithName("r").
+		OnEval(func(Context) bool { return false }).
+		OnElse(func(ctx Context) { ctx.GetRuleContext().Get("missing") }))
+	assert.EqualError(t, err, `rule "r" in else: key "missing" is missing`)
+}
+
+func TestWithElse_DiffAndSpecialize(t *testing.T) {
+	var a = NewBestFirstRule().WithName("r").WithElse(NewBestFirstRule().WithName("x"))
+	var b = NewBestFirstRule().WithName("r").OnElse(func(Context) {}).WithElse(NewBestFirstRule().WithName("y"))
+
+	var changes = DiffRules([]*BaseRule[BestFirstRule]{a}, []*BaseRule[BestFirstRule]{b})
+	assert.Equal(t, 2, len(changes))
+	assert.Equal(t, "else action changed from false to true", changes[0].Detail)
+	assert.Equal(t, "r/else/x", changes[1].Path)
+
+	var kept = Specialize(map[string]interface{}{"region": "us"},
+		NewBestFirstRule().WhenKeyIn("region", "eu").OnElse(func(Context) {}))
+	assert.Equal(t, 1, len(kept))
+}
@@ -0,0 +1,254 @@
+package rule
+
+import (
+	"encoding/gob"
+	"fmt"
+	"io"
+	"sort"
+	"sync/atomic"
+)
+
+// encodingVersion is the version of the format written by EncodeRules.
+const encodingVersion = 1
+
+type programDoc struct {
+	Version int
+	Rules   []ruleDoc
+}
+
+type ruleDoc struct {
+	Type          RuleType
+	Name          string
+	Annotation    bool
+	Index         bool
+	Optional      bool
+	Priority      int
+	Cost          float64
+	Canary        *float64
+	Weight        *int
+	MaxIterations int
+	MinScore      float64
+	Quorum        int
+	QuorumShare   float64
+	States        []string
+	Produces      []string
+	Switch        string
+	When          *condDoc
+	Hooks         map[Phase]string
+	Children      []ruleDoc
+	Else          []ruleDoc
+}
+
+type condDoc struct {
+	Key       string
+	Values    []interface{}
+	Range     bool
+	Min, Max  float64
+	Otherwise bool
+}
+
+// EncodeRules writes the structure of a rule set to w in a compact binary
+// form, encoding/gob, which DecodeRules loads back. Hooks are written as the
+// names they were set with through Use, and WhenKeyIn and WhenKeyBetween
+// conditions as they were declared. Condition values must be of basic types,
+// or of types registered with gob.Register.
+//
+// It fails when a rule has a hook set directly, such as with OnExecute, or is
+// built from Go values, such as assert and call rules.
+func EncodeRules[T any](w io.Writer, rules ...*BaseRule[T]) error {
+	var doc = programDoc{Version: encodingVersion}
+	for _, r := range rules {
+		d, err := encodeRule(r)
+		if err != nil {
+			return fmt.Errorf("encode rules: %w", err)
+		}
+		doc.Rules = append(doc.Rules, d)
+	}
+	if err := gob.NewEncoder(w).Encode(doc); err != nil {
+		return fmt.Errorf("encode rules: %w", err)
+	}
+	return nil
+}
+
+// namedHooks returns an error unless the hooks of the rule were all set with
+// Use, or are declared conditions.
+func (r *BaseRule[T]) namedHooks() error {
+	if r.assert != nil {
+		return fmt.Errorf("rule %q: assert rules can't be encoded", r.Path())
+	}
+	var phases = make([]Phase, 0, len(r.hookNames))
+	for phase, name := range r.hookNames {
+		if name == "" && (phase != PhaseEval || r.dispatch == nil) {
+			phases = append(phases, phase)
+		}
+	}
+	if len(phases) == 0 {
+		return nil
+	}
+	sort.Slice(phases, func(i, j int) bool { return phases[i] < phases[j] })
+	return fmt.Errorf("rule %q: %s hook was not set with Use", r.Path(), phases[0])
+}
+
+func encodeRule[T any](r *BaseRule[T]) (ruleDoc, error) {
+	if err := r.namedHooks(); err != nil {
+		return ruleDoc{}, err
+	}
+
+	var d = ruleDoc{
+		Type:          r.ruleType,
+		Name:          r.name,
+		Annotation:    r.annotation,
+		Index:         r.indexChildren,
+		Optional:      r.optional,
+		Priority:      r.priority,
+		Cost:          r.cost,
+		Canary:        r.canary,
+		Weight:        r.weight,
+		MaxIterations: r.maxIterations,
+		MinScore:      r.minScore,
+		Quorum:        r.quorum.count,
+		QuorumShare:   r.quorum.fraction,
+		States:        r.states,
+		Produces:      r.produces,
+		Switch:        r.switchKey,
+	}
+	if c := r.dispatch; c != nil {
+		d.When = &condDoc{Key: c.key, Range: c.isRange, Min: c.min, Max: c.max, Otherwise: c.otherwise}
+		for v := range c.set {
+			d.When.Values = append(d.When.Values, v)
+		}
+		sort.Slice(d.When.Values, func(i, j int) bool {
+			return fmt.Sprintf("%#v", d.When.Values[i]) < fmt.Sprintf("%#v", d.When.Values[j])
+		})
+	}
+	for phase, name := range r.hookNames {
+		if phase == PhaseEval && r.dispatch != nil {
+			continue
+		}
+		if d.Hooks == nil {
+			d.Hooks = make(map[Phase]string)
+		}
+		d.Hooks[phase] = name
+	}
+
+	for _, child := range r.children {
+		c, err := encodeRule(child)
+		if err != nil {
+			return ruleDoc{}, err
+		}
+		d.Children = append(d.Children, c)
+	}
+	for _, child := range r.elseChildren {
+		c, err := encodeRule(child)
+		if err != nil {
+			return ruleDoc{}, err
+		}
+		d.Else = append(d.Else, c)
+	}
+	return d, nil
+}
+
+// DecodeRules reads a rule set written by EncodeRules, resolving the names of
+// its hooks in funcs. T must be the kind of the encoded rules.
+func DecodeRules[T any](r io.Reader, funcs *Funcs) ([]*BaseRule[T], error) {
+	var doc programDoc
+	if err := gob.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("decode rules: %w", err)
+	}
+	if doc.Version != encodingVersion {
+		return nil, fmt.Errorf("decode rules: unsupported version %d", doc.Version)
+	}
+
+	var rules = make([]*BaseRule[T], 0, len(doc.Rules))
+	for _, d := range doc.Rules {
+		rule, err := decodeRule[T](d, funcs)
+		if err != nil {
+			return nil, fmt.Errorf("decode rules: %w", err)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+func decodeRule[T any](d ruleDoc, funcs *Funcs) (*BaseRule[T], error) {
+	var r = &BaseRule[T]{
+		name:          d.Name,
+		ruleType:      d.Type,
+		context:       NewRuleContext(),
+		children:      make([]*BaseRule[T], 0),
+		annotation:    d.Annotation,
+		indexChildren: d.Index,
+		optional:      d.Optional,
+		priority:      d.Priority,
+		cost:          d.Cost,
+		canary:        d.Canary,
+		weight:        d.Weight,
+		maxIterations: d.MaxIterations,
+		minScore:      d.MinScore,
+		quorum:        quorum{count: d.Quorum, fraction: d.QuorumShare},
+		states:        d.States,
+		produces:      d.Produces,
+		switchKey:     d.Switch,
+		onEval:        func(r Context) bool { return true },
+		onPreExecute:  func(r Context) {},
+		onExecute:     func(r Context) {},
+		onPostExecute: func(r Context) {},
+	}
+
+	var phases = make([]Phase, 0, len(d.Hooks))
+	for phase := range d.Hooks {
+		phases = append(phases, phase)
+	}
+	sort.Slice(phases, func(i, j int) bool { return phases[i] < phases[j] })
+	for _, phase := range phases {
+		if err := r.use(funcs, phase, d.Hooks[phase]); err != nil {
+			return nil, fmt.Errorf("rule %q: %w", d.Name, err)
+		}
+	}
+	if c := d.When; c != nil {
+		switch {
+		case c.Otherwise:
+			r.whenOtherwise(c.Key)
+		case c.Range:
+			r.WhenKeyBetween(c.Key, c.Min, c.Max)
+		default:
+			for _, v := range c.Values {
+				if !isHashable(v) {
+					return nil, fmt.Errorf("rule %q: condition value %#v can't be compared", d.Name, v)
+				}
+			}
+			r.WhenKeyIn(c.Key, c.Values...)
+		}
+		delete(r.hookNames, PhaseEval)
+	}
+
+	for _, cd := range d.Children {
+		child, err := decodeRule[T](cd, funcs)
+		if err != nil {
+			return nil, err
+		}
+		if err := r.AddChildrenE(child); err != nil {
+			return nil, fmt.Errorf("rule %q: %w", d.Name, err)
+		}
+	}
+	for _, cd := range d.Else {
+		child, err := decodeRule[T](cd, funcs)
+		if err != nil {
+			return nil, err
+		}
+		if r.childRuleType() == ChainRuleType && len(r.elseChildren) > 0 {
+			return nil, fmt.Errorf("rule %q: %w", d.Name, ErrTooManyChildren)
+		}
+		r.WithElse(child)
+	}
+	if r.ruleType == RoundRobinRuleType {
+		r.turn = new(atomic.Uint64)
+	}
+	if r.weight != nil && *r.weight < 0 {
+		return nil, fmt.Errorf("rule %q: weight %d is negative", d.Name, *r.weight)
+	}
+	if r.ruleType == LoopRuleType && r.maxIterations < 1 {
+		return nil, fmt.Errorf("rule %q: loop rule max iterations must be positive", d.Name)
+	}
+	return r, nil
+}
@@ -0,0 +1,150 @@
+package rule
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func encodingFuncs() *Funcs {
+	var set = func(key string, value interface{}) func(Context) {
+		return func(ctx Context) { ctx.GetRuleContext().Set(key, value) }
+	}
+	return NewFuncs().
+		Register("high-risk", func(ctx Context) bool { return ctx.GetRuleContext().Get("risk").(float64) > 0.8 }).
+		Register("risk-score", func(ctx Context) float64 { return ctx.GetRuleContext().Get("risk").(float64) }).
+		Register("decline", set("decision", "decline")).
+		Register("approve", set("decision", "approve")).
+		Register("review", set("decision", "review")).
+		Register("count", func(ctx Context) {
+			var rc = ctx.GetRuleContext()
+			var n, _ = rc.Get("count").(int)
+			rc.Set("count", n+1)
+		}).
+		Register("notify", func(Context) error { return errors.New("unreachable") }).
+		Register("load", func() error { return nil })
+}
+
+func encodingRules(funcs *Funcs) []*BaseRule[BestFirstRule] {
+	return []*BaseRule[BestFirstRule]{
+		NewBestFirstRule().WithName("eu").WhenKeyIn("region", "de", "fr", 49).WithPriority(1).AddChildren(
+			NewBestFirstRule().WithName("declined").
+				Use(funcs, PhaseEval, "high-risk").
+				Use(funcs, PhaseExecute, "decline").
+				Use(funcs, PhaseElse, "approve").
+				Use(funcs, PhaseInit, "load").
+				Cost(2).Optional(),
+		),
+		NewBestFirstRule().WithName("scored").Use(funcs, PhaseEval, "risk-score").WithMinScore(0.5).
+			Use(funcs, PhaseExecute, "review").Canary(100),
+		NewLoopRule[BestFirstRule](3).WithName("loop").Use(funcs, PhaseExecute, "count").
+			WithElse(NewLabel[BestFirstRule]("never")),
+		NewBestFirstRule().WithName("range").WhenKeyBetween("amount", 0, 10).WithDispatchIndex(),
+	}
+}
+
+func TestEncodeRules(t *testing.T) {
+	var funcs = encodingFuncs()
+	var rules = encodingRules(funcs)
+
+	var buf bytes.Buffer
+	assert.NoError(t, EncodeRules(&buf, rules...))
+
+	decoded, err := DecodeRules[BestFirstRule](&buf, funcs)
+	assert.NoError(t, err)
+	assert.Equal(t, Hash(rules...), Hash(decoded...))
+	assert.Equal(t, rules[1].hookNames, decoded[1].hookNames)
+	assert.Equal(t, "loop/else/never", decoded[2].GetElse()[0].Path())
+
+	for _, input := range []map[string]interface{}{
+		{"region": "de", "risk": 0.9},
+		{"region": 49, "risk": 0.1},
+		{"region": "us", "risk": 0.7},
+		{"region": "us", "risk": 0.1},
+	} {
+		var want, got = NewRuleContext(), NewRuleContext()
+		for k, v := range input {
+			want.Set(k, v)
+			got.Set(k, v)
+		}
+		assert.NoError(t, BestFirstRuleRunner(want, rules...))
+		assert.NoError(t, BestFirstRuleRunner(got, decoded...))
+		assert.Equal(t, want.Values(), got.Values(), input)
+	}
+}
+
+func TestEncodeRules_Errors(t *testing.T) {
+	var buf bytes.Buffer
+	var err = EncodeRules(&buf, NewChainRule().WithName("root").AddChildren(
+		NewChainRule().WithName("inline").OnExecute(func(Context) {})))
+	assert.EqualError(t, err, `encode rules: rule "root/inline": execute hook was not set with Use`)
+
+	err = EncodeRules(&buf, NewAssertRule[ChainRule]("positive"))
+	assert.EqualError(t, err, `encode rules: rule "": assert rules can't be encoded`)
+
+	buf.Reset()
+	assert.NoError(t, EncodeRules(&buf, encodingRules(encodingFuncs())...))
+	_, err = DecodeRules[BestFirstRule](&buf, NewFuncs())
+	assert.EqualError(t, err, `decode rules: rule "declined": function "approve" is not registered`)
+
+	_, err = DecodeRules[BestFirstRule](bytes.NewReader([]byte("garbage")), NewFuncs())
+	assert.Error(t, err)
+
+	buf.Reset()
+	assert.NoError(t, gob.NewEncoder(&buf).Encode(programDoc{Version: 99}))
+	_, err = DecodeRules[BestFirstRule](&buf, NewFuncs())
+	assert.EqualError(t, err, "decode rules: unsupported version 99")
+
+	buf.Reset()
+	assert.NoError(t, gob.NewEncoder(&buf).Encode(programDoc{Version: encodingVersion, Rules: []ruleDoc{
+		{Type: ChainRuleType, Name: "chain", Children: []ruleDoc{{Type: ChainRuleType}, {Type: ChainRuleType}}},
+	}}))
+	_, err = DecodeRules[ChainRule](&buf, NewFuncs())
+	assert.EqualError(t, err, `decode rules: rule "chain": ChainRule can only have one child`)
+
+	buf.Reset()
+	assert.NoError(t, gob.NewEncoder(&buf).Encode(programDoc{Version: encodingVersion, Rules: []ruleDoc{
+		{Type: BestFirstRuleType, Name: "when", When: &condDoc{Key: "region", Values: []interface{}{[]int{1}}}},
+	}}))
+	_, err = DecodeRules[BestFirstRule](&buf, NewFuncs())
+	assert.EqualError(t, err, `decode rules: rule "when": condition value []int{1} can't be compared`)
+
+	buf.Reset()
+	var weight = -1
+	assert.NoError(t, gob.NewEncoder(&buf).Encode(programDoc{Version: encodingVersion, Rules: []ruleDoc{
+		{Type: ChainRuleType, Name: "split", Weight: &weight},
+	}}))
+	_, err = DecodeRules[ChainRule](&buf, NewFuncs())
+	assert.EqualError(t, err, `decode rules: rule "split": weight -1 is negative`)
+}
+
+func FuzzDecodeRules(f *testing.F) {
+	var funcs = encodingFuncs()
+	var buf bytes.Buffer
+	if err := EncodeRules(&buf, encodingRules(funcs)...); err != nil {
+		f.Fatal(err)
+	}
+	f.Add(buf.Bytes())
+	f.Add([]byte("garbage"))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		rules, err := DecodeRules[BestFirstRule](bytes.NewReader(data), funcs)
+		if err != nil {
+			return
+		}
+		var out bytes.Buffer
+		if err := EncodeRules(&out, rules...); err != nil {
+			t.Fatalf("decoded rules don't encode: %v", err)
+		}
+		decoded, err := DecodeRules[BestFirstRule](&out, funcs)
+		if err != nil {
+			t.Fatalf("encoded rules don't decode: %v", err)
+		}
+		if Hash(rules...) != Hash(decoded...) {
+			t.Fatal("hash changed across encoding")
+		}
+	})
+}
@@ -0,0 +1,114 @@
+package rule
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+)
+
+// Phase is a step of the lifecycle of a rule.
+type Phase string
+
+// The phases of a rule, in the order they run. PhaseElse runs instead of the
+// execution phases when the rule doesn't match.
+const (
+	PhaseInit        Phase = "init"
+	PhaseAssert      Phase = "assert"
+	PhaseEval        Phase = "eval"
+	PhasePreExecute  Phase = "pre-execute"
+	PhaseExecute     Phase = "execute"
+	PhasePostExecute Phase = "post-execute"
+	PhaseElse        Phase = "else"
+)
+
+// RuleError is an error stopping a run, with the rule and the phase it
+// happened in.
+type RuleError struct {
+	Rule  Context
+	Phase Phase
+	Err   error
+	// Stack holds the program counters of the calls leading to the error,
+	// captured only in contexts created with WithErrorStacks.
+	Stack []uintptr
+}
+
+func (e *RuleError) Error() string {
+	if e.Phase == "" {
+		return fmt.Sprintf("%s: %v", ruleLabel(e.Rule), e.Err)
+	}
+	return fmt.Sprintf("%s in %s: %v", ruleLabel(e.Rule), e.Phase, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *RuleError) Unwrap() error {
+	return e.Err
+}
+
+// StackTrace formats the captured stack, one function and position per line,
+// or returns an empty string when no stack was captured.
+func (e *RuleError) StackTrace() string {
+	if len(e.Stack) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	var frames = runtime.CallersFrames(e.Stack)
+	for {
+		var frame, more = frames.Next()
+		fmt.Fprintf(&b, "%s\n\t%s:%d\n", frame.Function, frame.File, frame.Line)
+		if !more {
+			break
+		}
+	}
+	return b.String()
+}
+
+// WithErrorStacks captures the stack where rule errors are raised into
+// RuleError.Stack. It is disabled by default, as capturing costs time and
+// memory on every error.
+func WithErrorStacks() ContextOption {
+	return func(rc *RuleContext) {
+		rc.stacks = true
+	}
+}
+
+func (r *BaseRule[T]) newError(phase Phase, err error) *RuleError {
+	var ruleErr = &RuleError{Rule: r, Phase: phase, Err: err}
+	if rc := r.GetRuleContext(); rc != nil && rc.stacks {
+		var pcs [32]uintptr
+		var n = runtime.Callers(3, pcs[:])
+		ruleErr.Stack = append([]uintptr(nil), pcs[:n]...)
+	}
+	return ruleErr
+}
+
+// MissingKeyError reports a read of a missing key by a rule in a context
+// created with WithStrictKeys.
+type MissingKeyError struct {
+	Key string
+}
+
+func (e *MissingKeyError) Error() string {
+	return fmt.Sprintf("key %q is missing", e.Key)
+}
+
+// WithStrictKeys makes reading a missing key from within a rule an error: Get
+// still returns nil, but the run stops once the hook returns, with a
+// *RuleError naming the rule and wrapping a *MissingKeyError for every missing
+// key the hook read, joined with errors.Join.
+func WithStrictKeys() ContextOption {
+	return func(rc *RuleContext) {
+		rc.strict = true
+	}
+}
+
+// failed returns the error recorded in the context while running a hook of
+// the rule in the given phase, if any, and clears it.
+func (r *BaseRule[T]) failed(phase Phase) error {
+	var rc = r.GetRuleContext()
+	if rc == nil || rc.failure == nil {
+		return nil
+	}
+	var err = rc.failure
+	rc.failure = nil
+	return r.newError(phase, err)
+}
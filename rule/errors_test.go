@@ -0,0 +1,85 @@
+package rule
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRuleError(t *testing.T) {
+	var cause = errors.New("boom")
+	var err error = &RuleError{Rule: NewChainRule().WithName("validate"), Err: cause}
+
+	assert.Equal(t, `rule "validate": boom`, err.Error())
+	assert.Equal(t, `rule "validate" in post-execute: boom`, (&RuleError{Rule: NewChainRule().WithName("validate"), Phase: PhasePostExecute, Err: cause}).Error())
+	assert.ErrorIs(t, err, cause)
+}
+
+func TestWithStrictKeys(t *testing.T) {
+	var executed = false
+	var check = NewChainRule().WithName("check").
+		OnEval(func(ctx Context) bool { return ctx.GetRuleContext().Get("amount") != nil }).
+		OnExecute(func(Context) { executed = true })
+
+	rc := NewRuleContext(WithStrictKeys())
+	err := ChainRuleRunner(rc, check)
+
+	assert.EqualError(t, err, `rule "check" in eval: key "amount" is missing`)
+	var missing *MissingKeyError
+	assert.ErrorAs(t, err, &missing)
+	assert.Equal(t, "amount", missing.Key)
+	var ruleErr *RuleError
+	assert.ErrorAs(t, err, &ruleErr)
+	assert.Equal(t, check, ruleErr.Rule)
+	assert.False(t, executed)
+
+	assert.Nil(t, rc.Get("outside"))
+	rc.Set("amount", 10)
+	assert.NoError(t, ChainRuleRunner(rc, check))
+	assert.True(t, executed)
+}
+
+func TestWithStrictKeys_InExecute(t *testing.T) {
+	var after = false
+	var rule = NewBestFirstRule().
+		OnExecute(func(ctx Context) { ctx.GetRuleContext().Get("missing") }).
+		OnPostExecute(func(Context) { after = true })
+
+	assert.Error(t, BestFirstRuleRunner(NewRuleContext(WithStrictKeys()), rule))
+	assert.False(t, after)
+	assert.NoError(t, BestFirstRuleRunner(NewRuleContext(), rule))
+}
+
+func TestWithErrorStacks(t *testing.T) {
+	var failing = func() *BaseRule[ChainRule] {
+		return NewChainRule().OnInit(func() error { return errors.New("boom") })
+	}
+
+	var ruleErr *RuleError
+	assert.ErrorAs(t, ChainRuleRunner(NewRuleContext(), failing()), &ruleErr)
+	assert.Empty(t, ruleErr.Stack)
+	assert.Equal(t, "", ruleErr.StackTrace())
+
+	assert.ErrorAs(t, ChainRuleRunner(NewRuleContext(WithErrorStacks()), NewChainRule().AddChildren(failing())), &ruleErr)
+	assert.NotEmpty(t, ruleErr.Stack)
+	assert.Contains(t, ruleErr.StackTrace(), "rule.(*BaseRule[...]).fire")
+	assert.Contains(t, ruleErr.StackTrace(), "rule.TestWithErrorStacks")
+}
+
+func TestWithStrictKeys_JoinsMissingKeys(t *testing.T) {
+	var rule = NewChainRule().WithName("score").OnExecute(func(ctx Context) {
+		ctx.GetRuleContext().Get("amount")
+		ctx.GetRuleContext().Get("country")
+	})
+
+	err := ChainRuleRunner(NewRuleContext(WithStrictKeys()), rule)
+
+	assert.EqualError(t, err, "rule \"score\" in execute: key \"amount\" is missing\nkey \"country\" is missing")
+	var ruleErr *RuleError
+	assert.ErrorAs(t, err, &ruleErr)
+	assert.Equal(t, PhaseExecute, ruleErr.Phase)
+	var missing *MissingKeyError
+	assert.ErrorAs(t, err, &missing)
+	assert.Equal(t, "amount", missing.Key)
+}
@@ -0,0 +1,98 @@
+package rule
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// Example is an input context with the outcome a run is expected to produce.
+type Example struct {
+	Name string
+	// Input holds the values set on the context before the run.
+	Input map[string]interface{}
+	// Expected holds values the context must have after the run.
+	Expected map[string]interface{}
+	// Decision, when not nil, is the expected value of the run decision.
+	Decision interface{}
+}
+
+// SelfTest runs every example through run, each in a new RuleContext, and
+// returns an error for the first one whose outcome doesn't match. It is meant
+// to be called at startup, so a broken rule set fails before serving traffic.
+func SelfTest(run func(*RuleContext) error, examples ...Example) error {
+	for i, example := range examples {
+		if err := example.check(run); err != nil {
+			var name = example.Name
+			if name == "" {
+				name = fmt.Sprint("#", i)
+			}
+			return fmt.Errorf("example %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func (e Example) check(run func(*RuleContext) error) error {
+	var rc = NewRuleContext()
+	for k, v := range e.Input {
+		rc.Set(k, v)
+	}
+	if err := run(rc); err != nil {
+		return err
+	}
+
+	var keys = make([]string, 0, len(e.Expected))
+	for k := range e.Expected {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		var got, ok = rc.lookup(k)
+		if !ok {
+			return fmt.Errorf("key %q is missing, want %v", k, e.Expected[k])
+		}
+		if !reflect.DeepEqual(got, e.Expected[k]) {
+			return fmt.Errorf("key %q is %v, want %v", k, got, e.Expected[k])
+		}
+	}
+
+	if e.Decision != nil {
+		var decision, ok = rc.Decision()
+		if !ok {
+			return fmt.Errorf("no decision, want %v", e.Decision)
+		}
+		if !reflect.DeepEqual(decision.Value, e.Decision) {
+			return fmt.Errorf("decision is %v, want %v", decision.Value, e.Decision)
+		}
+	}
+	return nil
+}
+
+// WithExample attaches examples to the rule, documenting the rule set it is a
+// root of and checked by RunExamples.
+func (r *BaseRule[T]) WithExample(examples ...Example) *BaseRule[T] {
+	r.examples = append(r.examples, examples...)
+	return r
+}
+
+// Examples returns the examples attached to the rule.
+func (r *BaseRule[T]) Examples() []Example {
+	return r.examples
+}
+
+// RunExamples runs the examples attached to the given root rules through them,
+// with the runner of their rule type, as SelfTest does.
+func RunExamples[T any](rules ...*BaseRule[T]) error {
+	if len(rules) == 0 {
+		return nil
+	}
+
+	var examples []Example
+	for _, r := range rules {
+		examples = append(examples, r.examples...)
+	}
+	return SelfTest(func(rc *RuleContext) error {
+		return RuleRunner(rules[0].ruleType, rc, rules...)
+	}, examples...)
+}
@@ -0,0 +1,66 @@
+package rule
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func approveSmall(rc *RuleContext) error {
+	return BestFirstRuleRunner(rc,
+		NewBestFirstRule().
+			OnEval(func(ctx Context) bool { return ctx.GetRuleContext().Get("amount").(int) < 100 }).
+			OnExecute(func(ctx Context) {
+				ctx.GetRuleContext().Set("status", "approved")
+				ctx.GetRuleContext().SetDecision("approve", 1)
+			}),
+		NewBestFirstRule().OnExecute(func(ctx Context) {
+			ctx.GetRuleContext().Set("status", "review")
+		}),
+	)
+}
+
+func TestSelfTest(t *testing.T) {
+	assert.NoError(t, SelfTest(approveSmall,
+		Example{Name: "small", Input: map[string]interface{}{"amount": 10}, Expected: map[string]interface{}{"status": "approved"}, Decision: "approve"},
+		Example{Name: "large", Input: map[string]interface{}{"amount": 500}, Expected: map[string]interface{}{"status": "review"}},
+	))
+
+	assert.EqualError(t, SelfTest(approveSmall,
+		Example{Input: map[string]interface{}{"amount": 10}, Expected: map[string]interface{}{"status": "approved"}},
+		Example{Name: "large", Input: map[string]interface{}{"amount": 500}, Expected: map[string]interface{}{"status": "approved"}},
+	), `example large: key "status" is review, want approved`)
+
+	assert.EqualError(t, SelfTest(approveSmall,
+		Example{Input: map[string]interface{}{"amount": 500}, Expected: map[string]interface{}{"reason": "limit"}},
+	), `example #0: key "reason" is missing, want limit`)
+
+	assert.EqualError(t, SelfTest(approveSmall,
+		Example{Name: "large", Input: map[string]interface{}{"amount": 500}, Decision: "decline"},
+	), `example large: no decision, want decline`)
+}
+
+func TestSelfTest_RunError(t *testing.T) {
+	var cause = errors.New("init failed")
+	err := SelfTest(func(*RuleContext) error { return cause }, Example{Name: "any"})
+
+	assert.ErrorIs(t, err, cause)
+}
+
+func TestRunExamples(t *testing.T) {
+	var small = NewBestFirstRule().
+		OnEval(func(ctx Context) bool { return ctx.GetRuleContext().Get("amount").(int) < 100 }).
+		OnExecute(func(ctx Context) { ctx.GetRuleContext().Set("status", "approved") }).
+		WithExample(Example{Name: "small", Input: map[string]interface{}{"amount": 10}, Expected: map[string]interface{}{"status": "approved"}})
+	var review = NewBestFirstRule().
+		OnExecute(func(ctx Context) { ctx.GetRuleContext().Set("status", "review") }).
+		WithExample(Example{Name: "large", Input: map[string]interface{}{"amount": 500}, Expected: map[string]interface{}{"status": "review"}})
+
+	assert.Equal(t, 1, len(small.Examples()))
+	assert.NoError(t, RunExamples(small, review))
+
+	review.WithExample(Example{Name: "limit", Input: map[string]interface{}{"amount": 100}, Expected: map[string]interface{}{"status": "approved"}})
+	assert.EqualError(t, RunExamples(small, review), `example limit: key "status" is review, want approved`)
+	assert.NoError(t, RunExamples[BestFirstRule]())
+}
@@ -0,0 +1,56 @@
+package rule
+
+import "errors"
+
+type FallbackRule struct {
+	*BaseRule[FallbackRule]
+}
+
+func NewFallbackRule() *BaseRule[FallbackRule] {
+	return &BaseRule[FallbackRule]{
+		ruleType:      FallbackRuleType,
+		context:       NewRuleContext(),
+		children:      make([]*BaseRule[FallbackRule], 0),
+		onEval:        func(r Context) bool { return true },
+		onPreExecute:  func(r Context) {},
+		onExecute:     func(r Context) {},
+		onPostExecute: func(r Context) {},
+	}
+}
+
+// FallbackRuleRunner executes a list of FallbackRule rules within a given RuleContext.
+// Like BestFirstRuleRunner, it stops at the first rule whose OnEval returns true,
+// unless the OnExecuteWithError function of that rule fails: the runner then moves
+// on to the next sibling, as in "try provider A, then B, then C". The values,
+// provenance, decisions and reasons written by a failed rule are rolled back, as
+// with WithBacktracking, so a half-done attempt doesn't leak into the next one.
+//
+// Parameters:
+//   - ruleContext: A pointer to the RuleContext in which the rules will be executed.
+//   - rules: A slice of pointers to FallbackRule objects to be executed.
+//
+// Returns:
+//   - The *RuleError stopping the run, if any. When every matching rule failed,
+//     their errors are returned joined.
+func FallbackRuleRunner[T any](ruleContext *RuleContext, rules ...*BaseRule[T]) error {
+	return RuleRunner(FallbackRuleType, ruleContext, rules...)
+}
+
+func runFallback[T any](rc *RuleContext, rules []*BaseRule[T]) (bool, error) {
+	var failures []error
+	for _, r := range rules {
+		var saved = rc.save()
+		r.SetRuleContext(rc)
+		var next, err = r.fire()
+		var ruleErr *RuleError
+		if errors.As(err, &ruleErr) && ruleErr.Rule == Context(r) && ruleErr.Phase == PhaseExecute {
+			rc.rollback(saved)
+			failures = append(failures, err)
+			continue
+		}
+		if err != nil || !next {
+			return next, err
+		}
+	}
+	return true, errors.Join(failures...)
+}
@@ -0,0 +1,93 @@
+package rule
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func provider(name string, err error) *BaseRule[FallbackRule] {
+	return NewFallbackRule().WithName(name).OnExecuteWithError(func(ctx Context) error {
+		var rc = ctx.GetRuleContext()
+		var tried, _ = rc.Get("tried").([]string)
+		rc.Set("tried", append(tried, name))
+		if err != nil {
+			return err
+		}
+		rc.Set("provider", name)
+		return nil
+	})
+}
+
+func TestNewFallbackRule(t *testing.T) {
+	rule := NewFallbackRule()
+	assert.Equal(t, FallbackRuleType, rule.RuleType())
+	assert.Equal(t, "fallback", rule.RuleType().String())
+}
+
+func TestFallbackRuleRunner(t *testing.T) {
+	ruleContext := NewRuleContext()
+	assert.NoError(t, FallbackRuleRunner(ruleContext,
+		provider("a", errors.New("timeout")),
+		NewFallbackRule().WithName("skipped").OnEval(func(Context) bool { return false }),
+		provider("b", nil),
+		provider("c", nil),
+	))
+
+	assert.Equal(t, "b", ruleContext.Get("provider"))
+	assert.Equal(t, []string{"b"}, ruleContext.Get("tried"))
+}
+
+func TestFallbackRuleRunner_RollsBackFailedAttempts(t *testing.T) {
+	var partial = NewFallbackRule().WithName("a").OnExecuteWithError(func(ctx Context) error {
+		var rc = ctx.GetRuleContext()
+		rc.Set("charge_id", "a-123")
+		SetDecision(rc, "charged", 1, "PROVIDER_A")
+		return errors.New("timeout")
+	})
+
+	ruleContext := NewRuleContext()
+	ruleContext.Set("amount", 10)
+	assert.NoError(t, FallbackRuleRunner(ruleContext, partial, provider("b", nil)))
+	assert.Nil(t, ruleContext.Get("charge_id"))
+	assert.Equal(t, 10, ruleContext.Get("amount"))
+	assert.Empty(t, ruleContext.Decisions())
+	assert.Empty(t, ruleContext.Reasons())
+	assert.Equal(t, "b", ruleContext.Get("provider"))
+}
+
+func TestFallbackRuleRunner_AllFail(t *testing.T) {
+	ruleContext := NewRuleContext()
+	var err = FallbackRuleRunner(ruleContext, provider("a", errors.New("timeout")), provider("b", errors.New("refused")))
+
+	assert.EqualError(t, err, "rule \"a\" in execute: timeout\nrule \"b\" in execute: refused")
+	assert.Nil(t, ruleContext.Get("provider"))
+}
+
+func TestFallbackRuleRunner_OtherErrorsStop(t *testing.T) {
+	ruleContext := NewRuleContext()
+	var err = FallbackRuleRunner(ruleContext,
+		NewFallbackRule().WithName("broken").OnInit(func() error { return errors.New("bad config") }),
+		provider("b", nil),
+	)
+	assert.EqualError(t, err, `rule "broken" in init: bad config`)
+	assert.Nil(t, ruleContext.Get("provider"))
+
+	ruleContext = NewRuleContext()
+	err = FallbackRuleRunner(ruleContext,
+		provider("a", nil).AddChildren(provider("child", errors.New("timeout"))),
+		provider("b", nil),
+	)
+	assert.EqualError(t, err, `rule "child" in execute: timeout`)
+	assert.Equal(t, []string{"a"}, ruleContext.Get("tried"))
+}
+
+func TestOnExecuteWithError_OtherRunners(t *testing.T) {
+	var failing = NewBestFirstRule().WithName("a").OnExecuteWithError(func(Context) error { return errors.New("timeout") })
+	var err = BestFirstRuleRunner(NewRuleContext(), failing, NewBestFirstRule())
+	assert.EqualError(t, err, `rule "a" in execute: timeout`)
+
+	failing.OnExecute(func(Context) {})
+	assert.NoError(t, BestFirstRuleRunner(NewRuleContext(), failing))
+}
@@ -0,0 +1,130 @@
+package rule
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Funcs holds named hook functions, so rules can reference their hooks by
+// name with Use and be serialized with EncodeRules. It is safe for concurrent
+// use.
+type Funcs struct {
+	mu    sync.RWMutex
+	funcs map[string]interface{}
+}
+
+// NewFuncs creates an empty Funcs.
+func NewFuncs() *Funcs {
+	return &Funcs{funcs: make(map[string]interface{})}
+}
+
+// Register adds a function under the given name. The function must have the
+// type of a hook: func() error for OnInit, func(Context) bool for OnEval,
+// func(Context) float64 for OnEvalScore, func(Context) for the execution and
+// else hooks, or func(Context) error for OnExecuteWithError. It panics if the
+// name is already registered or the function isn't a hook.
+func (f *Funcs) Register(name string, fn interface{}) *Funcs {
+	switch fn.(type) {
+	case func() error, func(Context) bool, func(Context) float64, func(Context), func(Context) error:
+	default:
+		panic(fmt.Sprintf("function %q has type %T, which is not a hook", name, fn))
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.funcs[name]; ok {
+		panic(fmt.Sprintf("function %q is already registered", name))
+	}
+	f.funcs[name] = fn
+	return f
+}
+
+// Lookup returns the function registered under the given name.
+func (f *Funcs) Lookup(name string) (interface{}, bool) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	fn, ok := f.funcs[name]
+	return fn, ok
+}
+
+// Names returns the sorted names of the registered functions.
+func (f *Funcs) Names() []string {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	var names = make([]string, 0, len(f.funcs))
+	for name := range f.funcs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Use sets the hook of the rule for the phase to the function registered
+// under name, and records the name so the rule can be serialized. The type of
+// the function picks the hook where a phase has two, such as OnEval and
+// OnEvalScore. It panics if the name isn't registered or its function doesn't
+// fit the phase.
+func (r *BaseRule[T]) Use(funcs *Funcs, phase Phase, name string) *BaseRule[T] {
+	if err := r.use(funcs, phase, name); err != nil {
+		panic(err.Error())
+	}
+	return r
+}
+
+func (r *BaseRule[T]) use(funcs *Funcs, phase Phase, name string) error {
+	var fn, ok = funcs.Lookup(name)
+	if !ok {
+		return fmt.Errorf("function %q is not registered", name)
+	}
+
+	var mismatch = fmt.Errorf("function %q has type %T, which can't be used in %s", name, fn, phase)
+	switch f := fn.(type) {
+	case func() error:
+		if phase != PhaseInit {
+			return mismatch
+		}
+		r.OnInit(f)
+	case func(Context) bool:
+		if phase != PhaseEval {
+			return mismatch
+		}
+		r.OnEval(f)
+	case func(Context) float64:
+		if phase != PhaseEval {
+			return mismatch
+		}
+		r.OnEvalScore(f)
+	case func(Context) error:
+		if phase != PhaseExecute {
+			return mismatch
+		}
+		r.OnExecuteWithError(f)
+	case func(Context):
+		switch phase {
+		case PhasePreExecute:
+			r.OnPreExecute(f)
+		case PhaseExecute:
+			r.OnExecute(f)
+		case PhasePostExecute:
+			r.OnPostExecute(f)
+		case PhaseElse:
+			r.OnElse(f)
+		default:
+			return mismatch
+		}
+	default:
+		return mismatch
+	}
+	r.nameHook(phase, name)
+	return nil
+}
+
+// nameHook records the name of the function set as the hook of the phase, or
+// an empty name for functions set directly, which can't be serialized.
+func (r *BaseRule[T]) nameHook(phase Phase, name string) {
+	if r.hookNames == nil {
+		r.hookNames = make(map[Phase]string)
+	}
+	r.hookNames[phase] = name
+}
@@ -0,0 +1,47 @@
+package rule
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFuncs(t *testing.T) {
+	var funcs = NewFuncs().
+		Register("always", func(Context) bool { return true }).
+		Register("approve", func(ctx Context) { ctx.GetRuleContext().Set("status", "approved") })
+
+	assert.Equal(t, []string{"always", "approve"}, funcs.Names())
+	_, ok := funcs.Lookup("approve")
+	assert.True(t, ok)
+
+	assert.PanicsWithValue(t, `function "always" is already registered`, func() {
+		funcs.Register("always", func(Context) bool { return false })
+	})
+	assert.PanicsWithValue(t, `function "bad" has type func(int), which is not a hook`, func() {
+		funcs.Register("bad", func(int) {})
+	})
+}
+
+func TestUse(t *testing.T) {
+	var funcs = NewFuncs().
+		Register("always", func(Context) bool { return true }).
+		Register("approve", func(ctx Context) { ctx.GetRuleContext().Set("status", "approved") })
+
+	var rule = NewChainRule().Use(funcs, PhaseEval, "always").Use(funcs, PhaseExecute, "approve")
+	assert.Equal(t, map[Phase]string{PhaseEval: "always", PhaseExecute: "approve"}, rule.hookNames)
+
+	ruleContext := NewRuleContext()
+	assert.NoError(t, ChainRuleRunner(ruleContext, rule))
+	assert.Equal(t, "approved", ruleContext.Get("status"))
+
+	rule.OnExecute(func(Context) {})
+	assert.Equal(t, "", rule.hookNames[PhaseExecute])
+
+	assert.PanicsWithValue(t, `function "missing" is not registered`, func() {
+		rule.Use(funcs, PhaseEval, "missing")
+	})
+	assert.PanicsWithValue(t, `function "approve" has type func(rule.Context), which can't be used in eval`, func() {
+		rule.Use(funcs, PhaseEval, "approve")
+	})
+}
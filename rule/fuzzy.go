@@ -0,0 +1,198 @@
+package rule
+
+import (
+	"sort"
+	"strings"
+)
+
+// Levenshtein returns the edit distance between a and b: the least number of
+// single character insertions, deletions and substitutions turning a into b.
+func Levenshtein(a, b string) int {
+	var ra, rb = []rune(a), []rune(b)
+	var row = make([]int, len(rb)+1)
+	for j := range row {
+		row[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		var diag = row[0]
+		row[0] = i
+		for j := 1; j <= len(rb); j++ {
+			var cost = 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			var next = min(row[j]+1, row[j-1]+1, diag+cost)
+			diag, row[j] = row[j], next
+		}
+	}
+	return row[len(rb)]
+}
+
+// similarity returns 1 minus the edit distance relative to the longest string.
+func similarity(a, b string) float64 {
+	var longest = max(len([]rune(a)), len([]rune(b)))
+	if longest == 0 {
+		return 1
+	}
+	return 1 - float64(Levenshtein(a, b))/float64(longest)
+}
+
+// TokenSetRatio returns the similarity of a and b, between 0 and 1, ignoring
+// the order and repetition of their words, so "Smith, John" and "john smith
+// john" are identical. Both strings are normalized with Normalize first. It
+// returns 0 when either string has no words.
+func TokenSetRatio(a, b string) float64 {
+	return tokenSetRatio(tokenSet(a), tokenSet(b))
+}
+
+func tokenSetRatio(ta, tb map[string]bool) float64 {
+	if len(ta) == 0 || len(tb) == 0 {
+		return 0
+	}
+	var common, onlyA, onlyB []string
+	for t := range ta {
+		if tb[t] {
+			common = append(common, t)
+		} else {
+			onlyA = append(onlyA, t)
+		}
+	}
+	for t := range tb {
+		if !ta[t] {
+			onlyB = append(onlyB, t)
+		}
+	}
+	sort.Strings(common)
+	sort.Strings(onlyA)
+	sort.Strings(onlyB)
+
+	var base = strings.Join(common, " ")
+	var withA = strings.TrimSpace(base + " " + strings.Join(onlyA, " "))
+	var withB = strings.TrimSpace(base + " " + strings.Join(onlyB, " "))
+	return max(similarity(base, withA), similarity(base, withB), similarity(withA, withB))
+}
+
+func tokenSet(s string) map[string]bool {
+	var set = make(map[string]bool)
+	for _, t := range strings.FieldsFunc(Normalize(s), func(r rune) bool {
+		return r == ' ' || r == ',' || r == '.' || r == '-' || r == '_'
+	}) {
+		set[t] = true
+	}
+	return set
+}
+
+// FuzzyIndex indexes candidate strings in a BK-tree, so finding those within
+// an edit distance of a string doesn't compare it with every candidate.
+// Candidates are normalized with Normalize.
+type FuzzyIndex struct {
+	root *bkNode
+	size int
+}
+
+type bkNode struct {
+	word     string
+	children map[int]*bkNode
+}
+
+// NewFuzzyIndex creates a FuzzyIndex of the candidates.
+func NewFuzzyIndex(candidates ...string) *FuzzyIndex {
+	var index = &FuzzyIndex{}
+	for _, c := range candidates {
+		index.Add(c)
+	}
+	return index
+}
+
+// Add indexes a candidate.
+func (x *FuzzyIndex) Add(candidate string) {
+	var word = Normalize(candidate)
+	if x.root == nil {
+		x.root = &bkNode{word: word}
+		x.size++
+		return
+	}
+	for node := x.root; ; {
+		var d = Levenshtein(word, node.word)
+		if d == 0 {
+			return
+		}
+		var child, ok = node.children[d]
+		if !ok {
+			if node.children == nil {
+				node.children = make(map[int]*bkNode)
+			}
+			node.children[d] = &bkNode{word: word}
+			x.size++
+			return
+		}
+		node = child
+	}
+}
+
+// Len returns the number of distinct normalized candidates.
+func (x *FuzzyIndex) Len() int {
+	return x.size
+}
+
+// Search returns the sorted candidates within maxDistance edits of s.
+func (x *FuzzyIndex) Search(s string, maxDistance int) []string {
+	var word = Normalize(s)
+	var found []string
+	var stack []*bkNode
+	if x.root != nil {
+		stack = append(stack, x.root)
+	}
+	for len(stack) > 0 {
+		var node = stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		var d = Levenshtein(word, node.word)
+		if d <= maxDistance {
+			found = append(found, node.word)
+		}
+		for cd, child := range node.children {
+			if cd >= d-maxDistance && cd <= d+maxDistance {
+				stack = append(stack, child)
+			}
+		}
+	}
+	sort.Strings(found)
+	return found
+}
+
+// WithinDistance returns an OnEval predicate reporting whether the string
+// stored under key is within maxDistance edits of one of the candidates, after
+// normalization. The candidates are indexed once, when the predicate is
+// created.
+func WithinDistance(key string, maxDistance int, candidates ...string) func(Context) bool {
+	var index = NewFuzzyIndex(candidates...)
+	return func(ctx Context) bool {
+		var s, ok = ctx.GetRuleContext().Get(key).(string)
+		return ok && len(index.Search(s, maxDistance)) > 0
+	}
+}
+
+// TokenSetMatches returns an OnEval predicate reporting whether the string
+// stored under key has a TokenSetRatio of at least threshold with one of the
+// candidates. The candidates are tokenized once, when the predicate is
+// created.
+func TokenSetMatches(key string, threshold float64, candidates ...string) func(Context) bool {
+	var sets = make([]map[string]bool, len(candidates))
+	for i, c := range candidates {
+		sets[i] = tokenSet(c)
+	}
+	return func(ctx Context) bool {
+		var s, ok = ctx.GetRuleContext().Get(key).(string)
+		if !ok {
+			return false
+		}
+		var set = tokenSet(s)
+		for _, c := range sets {
+			if tokenSetRatio(set, c) >= threshold {
+				return true
+			}
+		}
+		return false
+	}
+}
@@ -0,0 +1,82 @@
+package rule
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLevenshtein(t *testing.T) {
+	assert.Equal(t, 3, Levenshtein("kitten", "sitting"))
+	assert.Equal(t, 0, Levenshtein("", ""))
+	assert.Equal(t, 4, Levenshtein("", "josé"))
+	assert.Equal(t, 1, Levenshtein("josé", "jose"))
+}
+
+func TestTokenSetRatio(t *testing.T) {
+	assert.Equal(t, 1.0, TokenSetRatio("Smith, John", "john smith john"))
+	assert.Equal(t, 1.0, TokenSetRatio("ACME Corp", "acme corp ltd"))
+	assert.Less(t, TokenSetRatio("ACME Corp", "Globex"), 0.5)
+	assert.Equal(t, 0.0, TokenSetRatio("", "John Smith"))
+	assert.Equal(t, 0.0, TokenSetRatio("John Smith", " , "))
+	assert.Equal(t, 0.0, TokenSetRatio("", ""))
+}
+
+func TestFuzzyIndex(t *testing.T) {
+	var index = NewFuzzyIndex("Jonathan Smith", "john smith", "Jane Doe", "JOHN SMITH")
+
+	assert.Equal(t, 3, index.Len())
+	assert.Equal(t, []string{"john smith"}, index.Search("jon smith", 1))
+	assert.Equal(t, []string{"jane doe", "john smith"}, index.Search("john doe", 5))
+	assert.Empty(t, index.Search("alice", 2))
+	assert.Empty(t, NewFuzzyIndex().Search("alice", 2))
+}
+
+func TestFuzzyIndex_MatchesLinearScan(t *testing.T) {
+	var candidates []string
+	for i := 0; i < 500; i++ {
+		candidates = append(candidates, fmt.Sprintf("user%d", i*7))
+	}
+	var index = NewFuzzyIndex(candidates...)
+
+	for _, s := range []string{"user7", "usr140", "user3", "xyz"} {
+		var want []string
+		for _, c := range candidates {
+			if Levenshtein(s, c) <= 2 {
+				want = append(want, c)
+			}
+		}
+		var got = index.Search(s, 2)
+		assert.ElementsMatch(t, want, got, s)
+	}
+}
+
+func TestFuzzyPredicates(t *testing.T) {
+	rc := NewRuleContext()
+	rc.Set("name", "Jon Smyth")
+	var ctx = &BaseRule[ChainRule]{context: rc}
+
+	assert.True(t, WithinDistance("name", 2, "John Smith", "Jane Doe")(ctx))
+	assert.False(t, WithinDistance("name", 1, "John Smith")(ctx))
+	assert.True(t, TokenSetMatches("name", 0.7, "Smith, John")(ctx))
+	assert.False(t, TokenSetMatches("name", 0.95, "Smith, John")(ctx))
+	assert.False(t, WithinDistance("missing", 2, "John")(ctx))
+	assert.False(t, TokenSetMatches("missing", 0, "John")(ctx))
+
+	rc.Set("name", "")
+	assert.False(t, TokenSetMatches("name", 0.5, "John Smith")(ctx))
+}
+
+func BenchmarkFuzzyIndex(b *testing.B) {
+	var candidates []string
+	for i := 0; i < 10000; i++ {
+		candidates = append(candidates, fmt.Sprintf("customer-%05d", i*13))
+	}
+	var index = NewFuzzyIndex(candidates...)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		index.Search("customer-01300", 1)
+	}
+}
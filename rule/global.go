@@ -0,0 +1,60 @@
+package rule
+
+import "sync/atomic"
+
+// GlobalContext holds read-only values shared by every run, such as
+// configuration or reference tables, so they don't need to be copied into
+// each RuleContext. Its values can be replaced atomically while runs are in
+// progress: each RuleContext keeps the snapshot current when it was created.
+type GlobalContext struct {
+	values atomic.Pointer[map[string]interface{}]
+}
+
+// NewGlobalContext creates a GlobalContext holding the values. The map must
+// not be modified afterwards.
+func NewGlobalContext(values map[string]interface{}) *GlobalContext {
+	var g = &GlobalContext{}
+	g.Replace(values)
+	return g
+}
+
+// Replace atomically swaps the values for new ones. The map must not be
+// modified afterwards.
+func (g *GlobalContext) Replace(values map[string]interface{}) {
+	g.values.Store(&values)
+}
+
+// Set atomically replaces the value of a single key, copying the other
+// values, and is safe to call concurrently with Replace and Set.
+func (g *GlobalContext) Set(key string, value interface{}) {
+	for {
+		var current = g.values.Load()
+		var values = make(map[string]interface{}, len(*current)+1)
+		for k, v := range *current {
+			values[k] = v
+		}
+		values[key] = value
+		if g.values.CompareAndSwap(current, &values) {
+			return
+		}
+	}
+}
+
+// Get returns the current value of a key.
+func (g *GlobalContext) Get(key string) interface{} {
+	return g.snapshot()[key]
+}
+
+func (g *GlobalContext) snapshot() map[string]interface{} {
+	return *g.values.Load()
+}
+
+// WithGlobal layers the current values of the GlobalContext beneath the
+// RuleContext and its defaults: Get returns them for keys that are neither set
+// nor defaulted. They are not included in Values, Clone copies only the
+// reference to them, and later calls to Replace don't affect the context.
+func WithGlobal(g *GlobalContext) ContextOption {
+	return func(rc *RuleContext) {
+		rc.global = g.snapshot()
+	}
+}
@@ -0,0 +1,62 @@
+package rule
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithGlobal(t *testing.T) {
+	var global = NewGlobalContext(map[string]interface{}{"countries": []string{"PT", "ES"}, "currency": "USD"})
+	rc := NewRuleContext(WithGlobal(global), WithDefaults(map[string]interface{}{"currency": "EUR"}))
+	rc.Set("amount", 10)
+
+	assert.Equal(t, []string{"PT", "ES"}, rc.Get("countries"))
+	assert.Equal(t, "EUR", rc.Get("currency"))
+	assert.Equal(t, map[string]interface{}{"amount": 10, "currency": "EUR"}, rc.Values())
+	assert.NoError(t, rc.RequireKeys("countries"))
+
+	global.Replace(map[string]interface{}{"countries": []string{"FR"}})
+	assert.Equal(t, []string{"PT", "ES"}, rc.Get("countries"))
+	assert.Equal(t, []string{"FR"}, NewRuleContext(WithGlobal(global)).Get("countries"))
+	assert.Equal(t, []string{"FR"}, global.Get("countries"))
+}
+
+func TestGlobalContext_ConcurrentReplace(t *testing.T) {
+	var global = NewGlobalContext(map[string]interface{}{"version": 0})
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 1; i <= 100; i++ {
+			global.Replace(map[string]interface{}{"version": i})
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			assert.NotNil(t, NewRuleContext(WithGlobal(global)).Get("version"))
+		}
+	}()
+	wg.Wait()
+
+	assert.Equal(t, 100, global.Get("version"))
+}
+
+func TestGlobalContext_Set(t *testing.T) {
+	var global = NewGlobalContext(nil)
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			global.Set(fmt.Sprint("table", i), i)
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Equal(t, 20, len(global.snapshot()))
+	assert.Equal(t, 7, global.Get("table7"))
+}
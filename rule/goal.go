@@ -0,0 +1,142 @@
+package rule
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// ErrGoalNotReached is returned by GoalRuleRunner when no rule could produce
+// the goal.
+var ErrGoalNotReached = errors.New("goal not reached")
+
+// Produces declares the keys the rule sets when it runs, so GoalRuleRunner
+// can fire it to reach one of them.
+func (r *BaseRule[T]) Produces(keys ...string) *BaseRule[T] {
+	r.produces = keys
+	return r
+}
+
+// GoalRuleRunner works backwards from the goal key, firing only the rules
+// needed to set it, as the expert systems of diagnostics do.
+//
+// The rules producing the goal, as declared with Produces, are tried by
+// priority, then by position. Before a rule is fired, its OnEval is run, and
+// each missing key it read with Get that other rules produce becomes a goal
+// in turn, after which the OnEval is run again. The first rule whose OnEval
+// returns true and which sets the goal ends the run; its children run as in
+// a tree of their type, and a rule skipped by the budget or the soft deadline
+// leaves the goal to the next producer. A goal is only pursued once per run,
+// so circular dependencies end. Under WithStrictKeys, reading a missing key that other
+// rules produce is only an error when it is still missing once pursued.
+//
+// Returns:
+//   - The *RuleError stopping the run, if any, or an error wrapping
+//     ErrGoalNotReached when the goal is still missing.
+func GoalRuleRunner[T any](ruleContext *RuleContext, goal string, rules ...*BaseRule[T]) error {
+	if inspected(ruleContext, rules) {
+		return errInspected
+	}
+	var s = &goalSolver[T]{
+		rc:        ruleContext,
+		producers: make(map[string][]*BaseRule[T]),
+		pursued:   make(map[string]bool),
+	}
+	for _, r := range byPriority(rules) {
+		for _, key := range r.produces {
+			s.producers[key] = append(s.producers[key], r)
+		}
+	}
+
+	var reached, err = s.solve(goal)
+	if err != nil {
+		return err
+	}
+	if !reached {
+		return fmt.Errorf("goal %q: %w", goal, ErrGoalNotReached)
+	}
+	return nil
+}
+
+type goalSolver[T any] struct {
+	rc        *RuleContext
+	producers map[string][]*BaseRule[T]
+	pursued   map[string]bool
+}
+
+// solve fires the producers of the goal until it is set.
+func (s *goalSolver[T]) solve(goal string) (bool, error) {
+	if _, ok := s.rc.lookup(goal); ok {
+		return true, nil
+	}
+	if s.pursued[goal] {
+		return false, nil
+	}
+	s.pursued[goal] = true
+
+	for _, r := range s.producers[goal] {
+		var matched, err = s.eval(r)
+		if err != nil {
+			return false, err
+		}
+		if !matched {
+			continue
+		}
+		if _, err := inferFire(s.rc, r); err != nil {
+			return false, err
+		}
+		if _, ok := s.rc.lookup(goal); ok {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// eval evaluates the rule, solving the missing keys it reads first.
+func (s *goalSolver[T]) eval(r *BaseRule[T]) (bool, error) {
+	for {
+		var excused = make(map[string]bool)
+		var matched, reads, err = inferEval(s.rc, r, func(key string) bool {
+			excused[key] = s.pending(key)
+			return excused[key]
+		})
+		if err != nil {
+			return false, err
+		}
+
+		var keys = make([]string, 0, len(reads))
+		for k := range reads {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		var solved = false
+		var unsolved []error
+		for _, k := range keys {
+			if !s.pending(k) {
+				continue
+			}
+			reached, err := s.solve(k)
+			if err != nil {
+				return false, err
+			}
+			solved = solved || reached
+			if !reached && excused[k] {
+				unsolved = append(unsolved, &MissingKeyError{Key: k})
+			}
+		}
+		if solved {
+			continue
+		}
+		if len(unsolved) > 0 {
+			return false, r.newError(PhaseEval, errors.Join(unsolved...))
+		}
+		return matched, nil
+	}
+}
+
+// pending reports whether the key is missing and can still be pursued.
+func (s *goalSolver[T]) pending(key string) bool {
+	var _, ok = s.rc.lookup(key)
+	return !ok && !s.pursued[key] && len(s.producers[key]) > 0
+}
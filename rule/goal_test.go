@@ -0,0 +1,121 @@
+package rule
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func diagnosis(fired *[]string) []*BaseRule[ChainRule] {
+	var conclude = func(name string, key string, value interface{}, when func(rc *RuleContext) bool) *BaseRule[ChainRule] {
+		return NewChainRule().WithName(name).Produces(key).
+			OnEval(func(ctx Context) bool { return when(ctx.GetRuleContext()) }).
+			OnExecute(func(ctx Context) {
+				*fired = append(*fired, name)
+				ctx.GetRuleContext().Set(key, value)
+			})
+	}
+	return []*BaseRule[ChainRule]{
+		conclude("dead-battery", "fault", "battery", func(rc *RuleContext) bool {
+			return rc.Get("cranks") == false && rc.Get("lights") == "dim"
+		}),
+		conclude("no-fuel", "fault", "fuel", func(rc *RuleContext) bool {
+			return rc.Get("cranks") == true && rc.Get("fuel_gauge") == "empty"
+		}),
+		conclude("cranks", "cranks", true, func(rc *RuleContext) bool { return rc.Get("engine_sound") == "cranking" }),
+		conclude("silent", "cranks", false, func(rc *RuleContext) bool { return rc.Get("engine_sound") == "silent" }),
+		conclude("horn", "lights", "dim", func(rc *RuleContext) bool { return rc.Get("horn") == "weak" }),
+		conclude("unrelated", "tires", "flat", func(*RuleContext) bool { return true }),
+	}
+}
+
+func TestGoalRuleRunner(t *testing.T) {
+	var fired []string
+	ruleContext := NewRuleContext()
+	ruleContext.Set("engine_sound", "cranking")
+	ruleContext.Set("fuel_gauge", "empty")
+	assert.NoError(t, GoalRuleRunner(ruleContext, "fault", diagnosis(&fired)...))
+	assert.Equal(t, "fuel", ruleContext.Get("fault"))
+	assert.Equal(t, []string{"cranks", "no-fuel"}, fired)
+
+	fired = nil
+	ruleContext = NewRuleContext()
+	ruleContext.Set("engine_sound", "silent")
+	ruleContext.Set("horn", "weak")
+	assert.NoError(t, GoalRuleRunner(ruleContext, "fault", diagnosis(&fired)...))
+	assert.Equal(t, "battery", ruleContext.Get("fault"))
+	assert.Equal(t, []string{"silent", "horn", "dead-battery"}, fired)
+	assert.Nil(t, ruleContext.Get("tires"))
+}
+
+func TestGoalRuleRunner_NotReached(t *testing.T) {
+	var fired []string
+	ruleContext := NewRuleContext()
+	ruleContext.Set("engine_sound", "silent")
+	var err = GoalRuleRunner(ruleContext, "fault", diagnosis(&fired)...)
+	assert.ErrorIs(t, err, ErrGoalNotReached)
+	assert.EqualError(t, err, `goal "fault": goal not reached`)
+	assert.Equal(t, []string{"silent"}, fired)
+
+	ruleContext = NewRuleContext()
+	ruleContext.Set("fault", "known")
+	assert.NoError(t, GoalRuleRunner(ruleContext, "fault", diagnosis(&fired)...))
+	assert.Equal(t, []string{"silent"}, fired)
+}
+
+func TestGoalRuleRunner_Cycle(t *testing.T) {
+	var a = NewChainRule().Produces("a").
+		OnEval(func(ctx Context) bool { return ctx.GetRuleContext().Get("b") != nil }).
+		OnExecute(func(ctx Context) { ctx.GetRuleContext().Set("a", true) })
+	var b = NewChainRule().Produces("b").
+		OnEval(func(ctx Context) bool { return ctx.GetRuleContext().Get("a") != nil }).
+		OnExecute(func(ctx Context) { ctx.GetRuleContext().Set("b", true) })
+
+	assert.ErrorIs(t, GoalRuleRunner(NewRuleContext(), "a", a, b), ErrGoalNotReached)
+
+	ruleContext := NewRuleContext()
+	ruleContext.Set("b", false)
+	assert.NoError(t, GoalRuleRunner(ruleContext, "a", a, b))
+}
+
+func TestGoalRuleRunner_Structure(t *testing.T) {
+	var rule = func(keys ...string) *BaseRule[ChainRule] {
+		return NewChainRule().WithName("rule").Produces(keys...)
+	}
+	assert.NotEqual(t, Hash(rule("a")), Hash(rule("b")))
+	assert.Equal(t, []Change{{Kind: Modified, Path: "rule", OldName: "rule", NewName: "rule",
+		Detail: `produced keys changed from ["a"] to ["b"]`}}, DiffRules([]*BaseRule[ChainRule]{rule("a")}, []*BaseRule[ChainRule]{rule("b")}))
+}
+
+func TestGoalRuleRunner_StrictKeys(t *testing.T) {
+	var fired []string
+	ruleContext := NewRuleContext(WithStrictKeys())
+	ruleContext.Set("engine_sound", "cranking")
+	ruleContext.Set("fuel_gauge", "empty")
+	assert.NoError(t, GoalRuleRunner(ruleContext, "fault", diagnosis(&fired)...))
+	assert.Equal(t, "fuel", ruleContext.Get("fault"))
+
+	fired = nil
+	ruleContext = NewRuleContext(WithStrictKeys())
+	ruleContext.Set("engine_sound", "silent")
+	var err = GoalRuleRunner(ruleContext, "fault", diagnosis(&fired)...)
+	var missing *MissingKeyError
+	assert.ErrorAs(t, err, &missing)
+	assert.Equal(t, "horn", missing.Key)
+	assert.Contains(t, err.Error(), `"horn"`)
+	assert.Equal(t, []string{"silent"}, fired)
+}
+
+func TestGoalRuleRunner_Budget(t *testing.T) {
+	var rules = []*BaseRule[ChainRule]{
+		NewChainRule().WithName("model").Produces("score").Cost(5).Optional().
+			OnExecute(func(ctx Context) { ctx.GetRuleContext().Set("score", 0.9) }),
+		NewChainRule().WithName("heuristic").Produces("score").Cost(1).
+			OnExecute(func(ctx Context) { ctx.GetRuleContext().Set("score", 0.5) }),
+	}
+
+	ruleContext := NewRuleContext(WithBudget(1))
+	assert.NoError(t, GoalRuleRunner(ruleContext, "score", rules...))
+	assert.Equal(t, 0.5, ruleContext.Get("score"))
+	assert.Equal(t, 1.0, ruleContext.Spent())
+}
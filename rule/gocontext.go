@@ -0,0 +1,20 @@
+package rule
+
+import "context"
+
+// WithGoContext sets the context.Context of the runs using the RuleContext,
+// carrying their cancellation, deadline and request-scoped values.
+func WithGoContext(ctx context.Context) ContextOption {
+	return func(rc *RuleContext) {
+		rc.goContext = ctx
+	}
+}
+
+// GoContext returns the context.Context of the run, or context.Background
+// when none was set.
+func (rc *RuleContext) GoContext() context.Context {
+	if rc.goContext == nil {
+		return context.Background()
+	}
+	return rc.goContext
+}
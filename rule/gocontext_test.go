@@ -0,0 +1,19 @@
+package rule
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type goContextKey struct{}
+
+func TestGoContext(t *testing.T) {
+	assert.Equal(t, context.Background(), NewRuleContext().GoContext())
+
+	var ctx = context.WithValue(context.Background(), goContextKey{}, "request")
+	rc := NewRuleContext(WithGoContext(ctx))
+	assert.Equal(t, "request", rc.GoContext().Value(goContextKey{}))
+	assert.Equal(t, "request", rc.Clone().GoContext().Value(goContextKey{}))
+}
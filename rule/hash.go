@@ -0,0 +1,162 @@
+package rule
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Hash returns a structural hash of the rule and its descendants, as Hash does
+// for a rule set.
+func (r *BaseRule[T]) Hash() string {
+	return Hash(r)
+}
+
+// Hash returns a structural hash of a rule set: a hex SHA-256 digest of the
+// shape of the trees and of the declarative configuration of each rule, such
+// as its name, type, WhenKeyIn and WhenKeyBetween conditions, priority, cost,
+// canary, weight, loop and voting settings. Deployments can compare it with
+// the hash of the expected definition.
+//
+// Hooks set with Use are hashed by the names of their functions. Hooks set as
+// Go functions can't be hashed: only which ones are set is part of the hash,
+// so rule sets differing in the code of their hooks only have the same hash.
+func Hash[T any](rules ...*BaseRule[T]) string {
+	var h = sha256.New()
+	for _, r := range rules {
+		hashRule(h, r, 0)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func hashRule[T any](h hash.Hash, r *BaseRule[T], depth int) {
+	fmt.Fprintf(h, "%d %s %q annotation=%t index=%t priority=%d cost=%v optional=%t",
+		depth, r.ruleType, r.name, r.annotation, r.indexChildren, r.priority, r.cost, r.optional)
+	if r.canary != nil {
+		fmt.Fprintf(h, " canary=%v", *r.canary)
+	}
+	if r.weight != nil {
+		fmt.Fprintf(h, " weight=%d", *r.weight)
+	}
+	if r.maxIterations > 0 {
+		fmt.Fprintf(h, " iterations=%d", r.maxIterations)
+	}
+	if r.ruleType == VotingRuleType {
+		fmt.Fprintf(h, " quorum=%s", r.quorum)
+	}
+	if len(r.produces) > 0 {
+		fmt.Fprintf(h, " produces=%q", r.produces)
+	}
+	if len(r.states) > 0 {
+		fmt.Fprintf(h, " states=%q", r.states)
+	}
+	if r.switchKey != "" {
+		fmt.Fprintf(h, " switch=%q", r.switchKey)
+	}
+	if r.onEvalScore != nil {
+		fmt.Fprintf(h, " score>%v", r.minScore)
+	}
+	if c := r.dispatch; c != nil {
+		switch {
+		case c.otherwise:
+			fmt.Fprintf(h, " when %q otherwise", c.key)
+		case c.isRange:
+			fmt.Fprintf(h, " when %q in [%v, %v)", c.key, c.min, c.max)
+		default:
+			var values = make([]string, 0, len(c.set))
+			for v := range c.set {
+				values = append(values, fmt.Sprintf("%T(%#v)", v, v))
+			}
+			sort.Strings(values)
+			fmt.Fprintf(h, " when %q in {%s}", c.key, strings.Join(values, ", "))
+		}
+	}
+	fmt.Fprintf(h, " hooks=%t,%t,%t,%t", r.init != nil, r.assert != nil, r.onExecuteErr != nil, r.onElse != nil)
+	var phases = make([]Phase, 0, len(r.hookNames))
+	for phase := range r.hookNames {
+		if phase != PhaseEval || r.dispatch == nil {
+			phases = append(phases, phase)
+		}
+	}
+	sort.Slice(phases, func(i, j int) bool { return phases[i] < phases[j] })
+	for _, phase := range phases {
+		if name := r.hookNames[phase]; name != "" {
+			fmt.Fprintf(h, " %s=%q", phase, name)
+		} else {
+			fmt.Fprintf(h, " %s=unnamed", phase)
+		}
+	}
+	fmt.Fprintln(h)
+
+	for _, child := range r.children {
+		hashRule(h, child, depth+1)
+	}
+	if len(r.elseChildren) > 0 {
+		fmt.Fprintf(h, "%d else\n", depth+1)
+		for _, child := range r.elseChildren {
+			hashRule(h, child, depth+2)
+		}
+	}
+}
+
+// ProgramCache compiles rule sets into Programs once per structural hash. It
+// only accepts rule sets whose hooks were all set with Use, or are declared
+// conditions such as WhenKeyIn, since the hash can't tell Go functions apart:
+// two rule sets with the same hash then run the same functions, and share the
+// Program of the first one. It is safe for concurrent use.
+type ProgramCache struct {
+	mu       sync.Mutex
+	programs map[string]Program
+}
+
+// NewProgramCache creates an empty ProgramCache.
+func NewProgramCache() *ProgramCache {
+	return &ProgramCache{programs: make(map[string]Program)}
+}
+
+// Len returns the number of cached programs.
+func (c *ProgramCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.programs)
+}
+
+// CompileCached returns the Program of the rule set from the cache, compiling
+// it with NewProgram when no rule set with the same hash was compiled before.
+// It also returns the hash. It fails, caching nothing, when a rule has a hook
+// set as a Go function, such as with OnExecute, or is an assert or call rule.
+func CompileCached[T any](cache *ProgramCache, rules ...*BaseRule[T]) (Program, string, error) {
+	for _, r := range rules {
+		if err := allNamedHooks(r); err != nil {
+			return nil, "", fmt.Errorf("compile cached: %w", err)
+		}
+	}
+
+	var hash = Hash(rules...)
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	if program, ok := cache.programs[hash]; ok {
+		return program, hash, nil
+	}
+	var program = NewProgram(rules...)
+	cache.programs[hash] = program
+	return program, hash, nil
+}
+
+// allNamedHooks checks the hooks of the rule and its descendants with
+// namedHooks.
+func allNamedHooks[T any](r *BaseRule[T]) error {
+	if err := r.namedHooks(); err != nil {
+		return err
+	}
+	for _, child := range append(append([]*BaseRule[T]{}, r.children...), r.elseChildren...) {
+		if err := allNamedHooks(child); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package rule
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func hashedRules() []*BaseRule[BestFirstRule] {
+	return []*BaseRule[BestFirstRule]{
+		NewBestFirstRule().WithName("eu").WhenKeyIn("region", "de", "fr").AddChildren(
+			NewBestFirstRule().WithName("vat").WhenKeyBetween("amount", 0, 100),
+		),
+		NewBestFirstRule().WithName("default").WithPriority(-1),
+	}
+}
+
+func TestHash(t *testing.T) {
+	var hash = Hash(hashedRules()...)
+	assert.Len(t, hash, 64)
+	assert.Equal(t, hash, Hash(hashedRules()...))
+	assert.Equal(t, hash, Hash(hashedRules()[0].Clone(), hashedRules()[1]))
+	assert.NotEqual(t, hash, hashedRules()[0].Hash())
+
+	var changes = map[string]func(rules []*BaseRule[BestFirstRule]){
+		"name":      func(rules []*BaseRule[BestFirstRule]) { rules[1].WithName("other") },
+		"values":    func(rules []*BaseRule[BestFirstRule]) { rules[0].WhenKeyIn("region", "de", "it") },
+		"types":     func(rules []*BaseRule[BestFirstRule]) { rules[0].WhenKeyIn("region", "de", 1) },
+		"range":     func(rules []*BaseRule[BestFirstRule]) { rules[0].children[0].WhenKeyBetween("amount", 0, 50) },
+		"priority":  func(rules []*BaseRule[BestFirstRule]) { rules[1].WithPriority(0) },
+		"custom":    func(rules []*BaseRule[BestFirstRule]) { rules[0].OnEval(func(Context) bool { return true }) },
+		"structure": func(rules []*BaseRule[BestFirstRule]) { rules[1].AddChildren(NewBestFirstRule()) },
+		"else":      func(rules []*BaseRule[BestFirstRule]) { rules[1].WithElse(NewBestFirstRule()) },
+		"cost":      func(rules []*BaseRule[BestFirstRule]) { rules[1].Cost(2) },
+		"hook":      func(rules []*BaseRule[BestFirstRule]) { rules[1].OnExecute(func(Context) {}) },
+	}
+	for name, change := range changes {
+		var rules = hashedRules()
+		change(rules)
+		assert.NotEqual(t, hash, Hash(rules...), name)
+	}
+}
+
+func TestHash_ValueTypes(t *testing.T) {
+	var cache = NewProgramCache()
+	for _, v := range []any{1, 1.0, int64(1)} {
+		_, _, err := CompileCached(cache, NewBestFirstRule().WithName("n").WhenKeyIn("n", v))
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, 3, cache.Len())
+}
+
+func TestHash_NamedHooks(t *testing.T) {
+	var funcs = NewFuncs().
+		Register("isVip", func(ctx Context) bool { return ctx.GetRuleContext().Get("vip") == true }).
+		Register("isBlocked", func(ctx Context) bool { return ctx.GetRuleContext().Get("blocked") == true })
+	var named = func(eval string) string {
+		return NewBestFirstRule().Use(funcs, PhaseEval, eval).Hash()
+	}
+
+	assert.Equal(t, named("isVip"), named("isVip"))
+	assert.NotEqual(t, named("isVip"), named("isBlocked"))
+	assert.NotEqual(t, named("isVip"), NewBestFirstRule().OnEval(func(Context) bool { return true }).Hash())
+}
+
+func TestCompileCached(t *testing.T) {
+	var cache = NewProgramCache()
+	var p1, h1, err = CompileCached(cache, hashedRules()...)
+	assert.NoError(t, err)
+	var _, h2, _ = CompileCached(cache, hashedRules()...)
+	assert.Equal(t, h1, h2)
+	assert.Equal(t, 1, cache.Len())
+
+	var other = hashedRules()
+	other[1].WithName("fallback")
+	CompileCached(cache, other...)
+	assert.Equal(t, 2, cache.Len())
+
+	var closures = hashedRules()
+	closures[0].children[0].OnExecute(func(Context) {})
+	_, _, err = CompileCached(cache, closures...)
+	assert.EqualError(t, err, `compile cached: rule "eu/vat": execute hook was not set with Use`)
+	assert.Equal(t, 2, cache.Len())
+
+	var funcs = NewFuncs().Register("review", func(ctx Context) { ctx.GetRuleContext().Set("decision", "review") })
+	var named = hashedRules()
+	named[0].children[0].Use(funcs, PhaseExecute, "review")
+	_, _, err = CompileCached(cache, named...)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, cache.Len())
+
+	ruleContext := NewRuleContext()
+	ruleContext.Set("region", "de")
+	ruleContext.Set("amount", 10)
+	assert.NoError(t, p1(ruleContext))
+}
@@ -0,0 +1,100 @@
+package rule
+
+import "time"
+
+// HistoryEntry is a journaled write to a RuleContext.
+type HistoryEntry struct {
+	Key     string
+	Value   interface{}
+	Deleted bool
+	// Rule is the rule that performed the write, or nil outside of a run.
+	Rule Context
+	Time time.Time
+}
+
+// Evaluation is a recorded OnEval outcome.
+type Evaluation struct {
+	Rule   Context
+	Result bool
+	// Score is the score of rules evaluated with OnEvalScore.
+	Score float64
+	// Annotation is set for labels and groups, which are recorded when they
+	// are reached but never evaluated.
+	Annotation bool
+}
+
+// KeyRead is a recorded read of a context key by a rule.
+type KeyRead struct {
+	Key  string
+	Rule Context
+}
+
+// WithHistory enables recording mode: every Set and Delete on the RuleContext
+// is journaled with the writing rule and a timestamp, so past states can be
+// rebuilt with ContextAt. Rule evaluations and reads made by rules are
+// recorded as well.
+func WithHistory() ContextOption {
+	return func(rc *RuleContext) {
+		rc.recording = true
+	}
+}
+
+func (rc *RuleContext) record(key string, value interface{}, deleted bool) {
+	if !rc.recording {
+		return
+	}
+	rc.history = append(rc.history, HistoryEntry{
+		Key:     key,
+		Value:   value,
+		Deleted: deleted,
+		Rule:    rc.current,
+		Time:    rc.Now(),
+	})
+}
+
+// History returns the journaled writes in order. It is empty unless the
+// context was created with WithHistory.
+func (rc *RuleContext) History() []HistoryEntry {
+	if !rc.recording {
+		return nil
+	}
+	return append([]HistoryEntry{}, rc.history...)
+}
+
+// Evaluations returns the recorded rule evaluations in order. It is empty
+// unless the context was created with WithHistory.
+func (rc *RuleContext) Evaluations() []Evaluation {
+	if !rc.recording {
+		return nil
+	}
+	return append([]Evaluation{}, rc.evaluations...)
+}
+
+// Reads returns the recorded reads made by rules, in order. It is empty
+// unless the context was created with WithHistory.
+func (rc *RuleContext) Reads() []KeyRead {
+	if !rc.recording {
+		return nil
+	}
+	return append([]KeyRead{}, rc.reads...)
+}
+
+// ContextAt rebuilds the state of the context after the first step journaled
+// writes. ContextAt(0) is the empty context and ContextAt(len(History())) is
+// the current state. Steps out of range are clamped.
+func (rc *RuleContext) ContextAt(step int) *RuleContext {
+	var state = NewRuleContext()
+	var history = rc.History()
+	if step > len(history) {
+		step = len(history)
+	}
+
+	for _, entry := range history[:max(step, 0)] {
+		if entry.Deleted {
+			delete(state.context, entry.Key)
+		} else {
+			state.context[entry.Key] = entry.Value
+		}
+	}
+	return state
+}
@@ -0,0 +1,64 @@
+package rule
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHistory_DisabledByDefault(t *testing.T) {
+	rc := NewRuleContext()
+	rc.Set("key", "value")
+
+	assert.Nil(t, rc.History())
+}
+
+func TestHistory_JournalsWrites(t *testing.T) {
+	rule := NewChainRule().WithName("approve").OnExecute(func(ctx Context) {
+		ctx.GetRuleContext().Set("approved", true)
+		ctx.GetRuleContext().Delete("pending")
+	})
+
+	ruleContext := NewRuleContext(WithHistory())
+	ruleContext.Set("pending", true)
+
+	ChainRuleRunner(ruleContext, rule)
+
+	history := ruleContext.History()
+	assert.Equal(t, 3, len(history))
+
+	assert.Equal(t, "pending", history[0].Key)
+	assert.Nil(t, history[0].Rule)
+	assert.False(t, history[0].Time.IsZero())
+
+	assert.Equal(t, "approved", history[1].Key)
+	assert.Equal(t, true, history[1].Value)
+	assert.Equal(t, "approve", history[1].Rule.(Named).GetName())
+
+	assert.Equal(t, "pending", history[2].Key)
+	assert.True(t, history[2].Deleted)
+}
+
+func TestContextAt(t *testing.T) {
+	rc := NewRuleContext(WithHistory())
+	rc.Set("a", 1)
+	rc.Set("b", 2)
+	rc.Set("a", 3)
+	rc.Delete("b")
+
+	assert.Nil(t, rc.ContextAt(0).Get("a"))
+	assert.Equal(t, 1, rc.ContextAt(1).Get("a"))
+	assert.Equal(t, 2, rc.ContextAt(2).Get("b"))
+	assert.Equal(t, 3, rc.ContextAt(3).Get("a"))
+	assert.Nil(t, rc.ContextAt(4).Get("b"))
+	assert.Equal(t, 3, rc.ContextAt(100).Get("a"))
+	assert.Nil(t, rc.ContextAt(-1).Get("a"))
+}
+
+func TestRuleContext_Delete(t *testing.T) {
+	rc := NewRuleContext()
+	rc.Set("key", "value")
+	rc.Delete("key")
+
+	assert.Nil(t, rc.Get("key"))
+}
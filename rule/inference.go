@@ -0,0 +1,199 @@
+package rule
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ErrInferenceLimit is returned by InferenceRuleRunner when the rules keep
+// firing past the limit set with WithMaxFirings.
+var ErrInferenceLimit = errors.New("inference did not settle within the maximum number of firings")
+
+// defaultMaxFirings is the firing limit of inference runs without WithMaxFirings.
+const defaultMaxFirings = 1000
+
+// WithMaxFirings limits the number of rules fired by an inference run.
+func WithMaxFirings(n int) ContextOption {
+	return func(rc *RuleContext) {
+		rc.maxFirings = n
+	}
+}
+
+// keyWatch collects the keys read and written during part of a run.
+type keyWatch struct {
+	reads  map[string]bool
+	writes map[string]bool
+}
+
+func (rc *RuleContext) startWatch() (*keyWatch, func()) {
+	var w = &keyWatch{reads: make(map[string]bool), writes: make(map[string]bool)}
+	var previous = rc.watch
+	rc.watch = w
+	return w, func() {
+		rc.watch = previous
+		if previous != nil {
+			for k := range w.reads {
+				previous.reads[k] = true
+			}
+			for k := range w.writes {
+				previous.writes[k] = true
+			}
+		}
+	}
+}
+
+// activation is the state of a rule in an inference run.
+type activation struct {
+	dirty   bool
+	matched bool
+	reads   map[string]bool
+	fired   map[string]bool
+}
+
+// InferenceRuleRunner runs rules as production rules, forward chaining until
+// no rule can fire: the context is the working memory, where rules assert
+// facts with Set and retract them with Delete.
+//
+// At each step, the first rule by priority, then by position, whose OnEval
+// returns true fires: it executes and its children run as in a tree of their
+// type. A rule is evaluated again only when a key its OnEval read with Get
+// was written since, as in a Rete network, and it doesn't fire twice for the
+// same values of these keys, which is known as refraction. Conditions
+// depending on anything else than the context, such as the time, are
+// therefore not evaluated again. Else branches don't apply. A rule fires as in
+// other runners, so the budget, soft deadline, assertions and votes still
+// apply to it.
+//
+// Returns:
+//   - The *RuleError stopping the run, if any, or ErrInferenceLimit when more
+//     rules fire than allowed by WithMaxFirings, 1000 by default.
+func InferenceRuleRunner[T any](ruleContext *RuleContext, rules ...*BaseRule[T]) error {
+	if inspected(ruleContext, rules) {
+		return errInspected
+	}
+	var ordered = byPriority(rules)
+	var agenda = make([]activation, len(ordered))
+	for i := range agenda {
+		agenda[i] = activation{dirty: true, fired: make(map[string]bool)}
+	}
+	var limit = ruleContext.maxFirings
+	if limit <= 0 {
+		limit = defaultMaxFirings
+	}
+
+	for firings := 0; ; firings++ {
+		var next, signature = -1, ""
+		for i, r := range ordered {
+			var a = &agenda[i]
+			if a.dirty {
+				var err error
+				if a.matched, a.reads, err = inferEval(ruleContext, r, nil); err != nil {
+					return err
+				}
+				a.dirty = false
+			}
+			if !a.matched {
+				continue
+			}
+			if s := ruleContext.signature(a.reads); !a.fired[s] {
+				next, signature = i, s
+				break
+			}
+		}
+		if next < 0 {
+			return nil
+		}
+		if firings == limit {
+			return ErrInferenceLimit
+		}
+
+		agenda[next].fired[signature] = true
+		var writes, err = inferFire(ruleContext, ordered[next])
+		if err != nil {
+			return err
+		}
+		for i := range agenda {
+			for k := range writes {
+				if agenda[i].reads[k] {
+					agenda[i].dirty = true
+					break
+				}
+			}
+		}
+	}
+}
+
+// inferEval runs the OnEval of the rule, returning the keys it read. Under
+// WithStrictKeys, the missing keys for which pending returns true are not
+// errors.
+func inferEval[T any](rc *RuleContext, r *BaseRule[T], pending func(key string) bool) (bool, map[string]bool, error) {
+	r.SetRuleContext(rc)
+	var previous = rc.current
+	rc.current = r
+	defer func() { rc.current = previous }()
+
+	if err := r.initialize(); err != nil {
+		return false, nil, r.newError(PhaseInit, err)
+	}
+	var w, stop = rc.startWatch()
+	var matched = r.eval()
+	stop()
+	if pending != nil {
+		rc.failure = excuse(rc.failure, pending)
+	}
+	if err := r.failed(PhaseEval); err != nil {
+		return false, nil, err
+	}
+	r.trace(TraceEval, matched)
+	return matched, w.reads, nil
+}
+
+// excuse removes from err the *MissingKeyError of the keys for which pending
+// returns true.
+func excuse(err error, pending func(key string) bool) error {
+	var missing *MissingKeyError
+	if errors.As(err, &missing) && err == error(missing) && pending(missing.Key) {
+		return nil
+	}
+	var joined, ok = err.(interface{ Unwrap() []error })
+	if !ok {
+		return err
+	}
+	var errs []error
+	for _, e := range joined.Unwrap() {
+		errs = append(errs, excuse(e, pending))
+	}
+	return errors.Join(errs...)
+}
+
+// inferFire fires a rule whose OnEval returned true, returning the keys it
+// wrote. The rule goes through the same steps as in other runners, so it can
+// still be skipped by the budget or the soft deadline, or lose its vote.
+func inferFire[T any](rc *RuleContext, r *BaseRule[T]) (map[string]bool, error) {
+	var w, stop = rc.startWatch()
+	defer stop()
+	r.SetRuleContext(rc)
+	var matched = true
+	if _, err := r.fireEvaluated(&matched); err != nil {
+		return nil, err
+	}
+	return w.writes, nil
+}
+
+// signature identifies the values of the keys for refraction.
+func (rc *RuleContext) signature(keys map[string]bool) string {
+	var sorted = make([]string, 0, len(keys))
+	for k := range keys {
+		sorted = append(sorted, k)
+	}
+	sort.Strings(sorted)
+
+	var b strings.Builder
+	for _, k := range sorted {
+		var v, ok = rc.lookup(k)
+		fmt.Fprintf(&b, "%q=%t:%#v\x00", k, ok, v)
+	}
+	return b.String()
+}
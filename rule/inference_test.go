@@ -0,0 +1,122 @@
+package rule
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInferenceRuleRunner(t *testing.T) {
+	var fact = func(name string, when func(rc *RuleContext) bool, key string) *BaseRule[ChainRule] {
+		return NewChainRule().WithName(name).
+			OnEval(func(ctx Context) bool { return when(ctx.GetRuleContext()) }).
+			OnExecute(func(ctx Context) { ctx.GetRuleContext().Set(key, true) })
+	}
+	var is = func(key string) func(rc *RuleContext) bool {
+		return func(rc *RuleContext) bool { return rc.Get(key) == true }
+	}
+
+	var rules = []*BaseRule[ChainRule]{
+		fact("penguin", func(rc *RuleContext) bool { return is("bird")(rc) && rc.Get("flies") == false }, "penguin"),
+		fact("bird", is("feathers"), "bird"),
+		fact("feathers", func(rc *RuleContext) bool { return rc.Get("covering") == "feathers" }, "feathers"),
+	}
+
+	ruleContext := NewRuleContext()
+	ruleContext.Set("covering", "feathers")
+	ruleContext.Set("flies", false)
+	assert.NoError(t, InferenceRuleRunner(ruleContext, rules...))
+	assert.Equal(t, true, ruleContext.Get("penguin"))
+	assert.Equal(t, rules[1], ruleContext.Provenance()["bird"])
+
+	ruleContext = NewRuleContext()
+	ruleContext.Set("covering", "fur")
+	assert.NoError(t, InferenceRuleRunner(ruleContext, rules...))
+	assert.Equal(t, map[string]interface{}{"covering": "fur"}, ruleContext.Values())
+}
+
+func TestInferenceRuleRunner_Refraction(t *testing.T) {
+	var fired int
+	var always = NewChainRule().OnExecute(func(Context) { fired++ })
+	var toggle = NewChainRule().
+		OnEval(func(ctx Context) bool { return ctx.GetRuleContext().Get("on") != nil }).
+		OnExecute(func(ctx Context) {
+			var rc = ctx.GetRuleContext()
+			rc.Set("on", rc.Get("on") != true)
+		})
+
+	ruleContext := NewRuleContext()
+	ruleContext.Set("on", false)
+	assert.NoError(t, InferenceRuleRunner(ruleContext, always, toggle))
+	assert.Equal(t, 1, fired)
+	assert.Equal(t, false, ruleContext.Get("on"))
+}
+
+func TestInferenceRuleRunner_Dependencies(t *testing.T) {
+	var evals = map[string]int{}
+	var counted = func(name string, f func(rc *RuleContext) bool) func(Context) bool {
+		return func(ctx Context) bool {
+			evals[name]++
+			return f(ctx.GetRuleContext())
+		}
+	}
+
+	var count = NewChainRule().WithName("count").
+		OnEval(counted("count", func(rc *RuleContext) bool { return rc.Get("n").(int) < 3 })).
+		OnExecute(func(ctx Context) {
+			var rc = ctx.GetRuleContext()
+			rc.Set("n", rc.Get("n").(int)+1)
+		})
+	var unrelated = NewChainRule().WithName("unrelated").
+		OnEval(counted("unrelated", func(rc *RuleContext) bool { return rc.Get("other") == true }))
+	var done = NewChainRule().WithName("done").WithPriority(1).
+		OnEval(counted("done", func(rc *RuleContext) bool { return rc.Get("n") == 3 })).
+		OnExecute(func(ctx Context) { ctx.GetRuleContext().Set("done", true) })
+
+	ruleContext := NewRuleContext()
+	ruleContext.Set("n", 0)
+	assert.NoError(t, InferenceRuleRunner(ruleContext, count, unrelated, done))
+	assert.Equal(t, 3, ruleContext.Get("n"))
+	assert.Equal(t, true, ruleContext.Get("done"))
+	assert.Equal(t, map[string]int{"count": 4, "unrelated": 1, "done": 4}, evals)
+}
+
+func TestInferenceRuleRunner_Limit(t *testing.T) {
+	var grow = NewChainRule().OnExecute(func(ctx Context) {
+		var rc = ctx.GetRuleContext()
+		var n, _ = rc.Get("n").(int)
+		rc.Set("n", n+1)
+	}).OnEval(func(ctx Context) bool { return ctx.GetRuleContext().Get("n") != -1 })
+
+	ruleContext := NewRuleContext(WithMaxFirings(10))
+	assert.ErrorIs(t, InferenceRuleRunner(ruleContext, grow), ErrInferenceLimit)
+	assert.Equal(t, 10, ruleContext.Get("n"))
+}
+
+func TestInferenceRuleRunner_Errors(t *testing.T) {
+	var failing = NewChainRule().WithName("failing").OnExecuteWithError(func(Context) error { return assert.AnError })
+	var err = InferenceRuleRunner(NewRuleContext(), failing)
+	assert.ErrorIs(t, err, assert.AnError)
+	assert.EqualError(t, err, `rule "failing" in execute: `+assert.AnError.Error())
+}
+
+func TestInferenceRuleRunner_FiresLikeOtherRunners(t *testing.T) {
+	var set = func(key string) func(Context) {
+		return func(ctx Context) { ctx.GetRuleContext().Set(key, true) }
+	}
+	var rules = []*BaseRule[ChainRule]{
+		NewChainRule().WithName("lookup").Cost(5).Optional().OnExecute(set("enriched")),
+		NewChainRule().WithName("cheap").Cost(1).OnExecute(set("scored")),
+		NewVotingRule[ChainRule](Unanimous()).WithName("flag").OnExecute(set("flagged")).AddChildren(
+			NewChainRule().OnEval(func(Context) bool { return true }),
+			NewChainRule().OnEval(func(Context) bool { return false }),
+		),
+	}
+
+	ruleContext := NewRuleContext(WithBudget(1))
+	assert.NoError(t, InferenceRuleRunner(ruleContext, rules...))
+	assert.Nil(t, ruleContext.Get("enriched"))
+	assert.Equal(t, true, ruleContext.Get("scored"))
+	assert.Nil(t, ruleContext.Get("flagged"))
+	assert.Equal(t, 1.0, ruleContext.Spent())
+}
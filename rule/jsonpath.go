@@ -0,0 +1,257 @@
+package rule
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// JSONPath is a parsed JSONPath expression. The supported subset covers the
+// root "$", child access ".name" and ['name'], array indexes [0] and [-1],
+// and wildcards .* and [*].
+type JSONPath struct {
+	expr  string
+	steps []jsonStep
+	multi bool
+}
+
+type jsonStep struct {
+	name     string
+	index    int
+	isIndex  bool
+	wildcard bool
+}
+
+// ParseJSONPath parses a JSONPath expression such as "$.order.items[0].sku".
+func ParseJSONPath(expr string) (*JSONPath, error) {
+	if !strings.HasPrefix(expr, "$") {
+		return nil, fmt.Errorf("jsonpath %q: must start with $", expr)
+	}
+
+	var p = &JSONPath{expr: expr}
+	var rest = expr[1:]
+	for rest != "" {
+		var step jsonStep
+		switch rest[0] {
+		case '.':
+			var end = strings.IndexAny(rest[1:], ".[")
+			if end < 0 {
+				end = len(rest) - 1
+			}
+			step.name = rest[1 : end+1]
+			rest = rest[end+1:]
+			if step.name == "" {
+				return nil, fmt.Errorf("jsonpath %q: empty name", expr)
+			}
+			step.wildcard = step.name == "*"
+
+		case '[':
+			var end = strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("jsonpath %q: unclosed bracket", expr)
+			}
+			var inner = rest[1:end]
+			rest = rest[end+1:]
+			switch {
+			case inner == "*":
+				step.wildcard = true
+			case len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0]:
+				step.name = inner[1 : len(inner)-1]
+			default:
+				var err error
+				if step.index, err = strconv.Atoi(inner); err != nil {
+					return nil, fmt.Errorf("jsonpath %q: invalid index %q", expr, inner)
+				}
+				step.isIndex = true
+			}
+
+		default:
+			return nil, fmt.Errorf("jsonpath %q: unexpected %q", expr, rest[0])
+		}
+
+		p.multi = p.multi || step.wildcard
+		p.steps = append(p.steps, step)
+	}
+	return p, nil
+}
+
+// MustParseJSONPath is like ParseJSONPath but panics on an invalid expression.
+func MustParseJSONPath(expr string) *JSONPath {
+	p, err := ParseJSONPath(expr)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+// String returns the source expression.
+func (p *JSONPath) String() string {
+	return p.expr
+}
+
+// Find returns the values matched in a decoded JSON document.
+func (p *JSONPath) Find(doc interface{}) []interface{} {
+	var current = []interface{}{doc}
+	for _, step := range p.steps {
+		var next []interface{}
+		for _, node := range current {
+			next = append(next, step.apply(node)...)
+		}
+		current = next
+	}
+	return current
+}
+
+func (s jsonStep) apply(node interface{}) []interface{} {
+	switch n := node.(type) {
+	case map[string]interface{}:
+		if s.wildcard {
+			var keys = make([]string, 0, len(n))
+			for k := range n {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			var values = make([]interface{}, len(keys))
+			for i, k := range keys {
+				values[i] = n[k]
+			}
+			return values
+		}
+		if v, ok := n[s.name]; ok && !s.isIndex {
+			return []interface{}{v}
+		}
+	case []interface{}:
+		if s.wildcard {
+			return n
+		}
+		if s.isIndex {
+			var i = s.index
+			if i < 0 {
+				i += len(n)
+			}
+			if i >= 0 && i < len(n) {
+				return []interface{}{n[i]}
+			}
+		}
+	}
+	return nil
+}
+
+// jsonDocument returns the decoded JSON document stored under key, or false
+// when the key is missing. The value may be a JSON string, a []byte, or an
+// already decoded document.
+func jsonDocument(rc *RuleContext, key string) (interface{}, bool, error) {
+	var raw []byte
+	switch v := rc.Get(key).(type) {
+	case nil:
+		return nil, false, nil
+	case string:
+		raw = []byte(v)
+	case []byte:
+		raw = v
+	case json.RawMessage:
+		raw = v
+	default:
+		return v, true, nil
+	}
+
+	var doc interface{}
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return nil, false, fmt.Errorf("json %q: %w", key, err)
+	}
+	return doc, true, nil
+}
+
+// ExtractJSON returns an execute hook storing into output the value matched
+// by the JSONPath in the JSON document stored under source. Paths with
+// wildcards store a []interface{} with every match. Nothing is stored when
+// the document is invalid or nothing matches; use ExtractJSONE to stop the
+// run on invalid documents instead.
+func ExtractJSON(source string, path string, output string) func(Context) {
+	var extract = ExtractJSONE(source, path, output)
+	return func(ctx Context) {
+		_ = extract(ctx)
+	}
+}
+
+// ExtractJSONE is like ExtractJSON but returns an error when the document is
+// invalid, for OnExecuteWithError.
+func ExtractJSONE(source string, path string, output string) func(Context) error {
+	var p = MustParseJSONPath(path)
+	return func(ctx Context) error {
+		var rc = ctx.GetRuleContext()
+		doc, ok, err := jsonDocument(rc, source)
+		if !ok {
+			return err
+		}
+		var found = p.Find(doc)
+		switch {
+		case p.multi:
+			if len(found) > 0 {
+				rc.Set(output, found)
+			}
+		case len(found) == 1:
+			rc.Set(output, found[0])
+		}
+		return nil
+	}
+}
+
+// JSONPathExists returns an OnEval predicate reporting whether the JSONPath
+// matches anything in the JSON document stored under source. Invalid
+// documents evaluate to false; use JSONPathExistsE to stop the run instead.
+func JSONPathExists(source string, path string) func(Context) bool {
+	return lenient(JSONPathExistsE(source, path))
+}
+
+// JSONPathExistsE is like JSONPathExists but returns an error when the
+// document is invalid, for OnEvalWithError.
+func JSONPathExistsE(source string, path string) func(Context) (bool, error) {
+	var p = MustParseJSONPath(path)
+	return func(ctx Context) (bool, error) {
+		doc, ok, err := jsonDocument(ctx.GetRuleContext(), source)
+		return ok && len(p.Find(doc)) > 0, err
+	}
+}
+
+// JSONPathEquals returns an OnEval predicate reporting whether a value matched
+// by the JSONPath in the JSON document stored under source equals value.
+// JSON numbers are float64, so numeric values are compared as float64.
+// Invalid documents evaluate to false; use JSONPathEqualsE to stop the run
+// instead.
+func JSONPathEquals(source string, path string, value interface{}) func(Context) bool {
+	return lenient(JSONPathEqualsE(source, path, value))
+}
+
+// JSONPathEqualsE is like JSONPathEquals but returns an error when the
+// document is invalid, for OnEvalWithError.
+func JSONPathEqualsE(source string, path string, value interface{}) func(Context) (bool, error) {
+	var p = MustParseJSONPath(path)
+	if f, ok := toFloat(value); ok {
+		value = f
+	}
+	return func(ctx Context) (bool, error) {
+		doc, ok, err := jsonDocument(ctx.GetRuleContext(), source)
+		if !ok {
+			return false, err
+		}
+		for _, v := range p.Find(doc) {
+			if reflect.DeepEqual(v, value) {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+}
+
+// lenient turns a predicate that can fail into one evaluating to false on
+// errors.
+func lenient(f func(Context) (bool, error)) func(Context) bool {
+	return func(ctx Context) bool {
+		var matched, err = f(ctx)
+		return err == nil && matched
+	}
+}
@@ -0,0 +1,120 @@
+package rule
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testEvent = `{
+	"user": {"id": 42, "email": "ana@example.com"},
+	"items": [{"sku": "A1", "price": 10}, {"sku": "B2", "price": 25.5}],
+	"tags": {"channel": "web", "campaign": "spring"}
+}`
+
+func TestParseJSONPath_Errors(t *testing.T) {
+	for expr, msg := range map[string]string{
+		"user.id":    `jsonpath "user.id": must start with $`,
+		"$..id":      `jsonpath "$..id": empty name`,
+		"$.items[0":  `jsonpath "$.items[0": unclosed bracket`,
+		"$.items[x]": `jsonpath "$.items[x]": invalid index "x"`,
+		"$user":      `jsonpath "$user": unexpected 'u'`,
+	} {
+		_, err := ParseJSONPath(expr)
+		assert.EqualError(t, err, msg)
+	}
+}
+
+func TestJSONPath_Find(t *testing.T) {
+	var rc = NewRuleContext()
+	rc.Set("event", testEvent)
+	doc, ok, err := jsonDocument(rc, "event")
+	assert.True(t, ok)
+	assert.NoError(t, err)
+
+	assert.Equal(t, []interface{}{42.0}, MustParseJSONPath("$.user.id").Find(doc))
+	assert.Equal(t, []interface{}{"ana@example.com"}, MustParseJSONPath("$['user']['email']").Find(doc))
+	assert.Equal(t, []interface{}{"B2"}, MustParseJSONPath("$.items[-1].sku").Find(doc))
+	assert.Equal(t, []interface{}{10.0, 25.5}, MustParseJSONPath("$.items[*].price").Find(doc))
+	assert.Equal(t, []interface{}{"spring", "web"}, MustParseJSONPath("$.tags.*").Find(doc))
+	assert.Empty(t, MustParseJSONPath("$.items[5]").Find(doc))
+	assert.Empty(t, MustParseJSONPath("$.user[0]").Find(doc))
+	assert.Equal(t, "$.user.id", MustParseJSONPath("$.user.id").String())
+}
+
+func TestExtractJSON(t *testing.T) {
+	rule := NewChainRule().OnExecute(func(ctx Context) {
+		ExtractJSON("event", "$.user.email", "email")(ctx)
+		ExtractJSON("event", "$.items[*].sku", "skus")(ctx)
+		ExtractJSON("event", "$.missing", "missing")(ctx)
+	})
+
+	ruleContext := NewRuleContext()
+	ruleContext.Set("event", []byte(testEvent))
+	ChainRuleRunner(ruleContext, rule)
+
+	assert.Equal(t, "ana@example.com", ruleContext.Get("email"))
+	assert.Equal(t, []interface{}{"A1", "B2"}, ruleContext.Get("skus"))
+	assert.Nil(t, ruleContext.Get("missing"))
+}
+
+func eventContext(event interface{}) Context {
+	rule := NewChainRule()
+	rule.GetRuleContext().Set("event", event)
+	return rule
+}
+
+func TestJSONPathPredicates(t *testing.T) {
+	assert.True(t, JSONPathExists("event", "$.tags.channel")(eventContext(testEvent)))
+	assert.False(t, JSONPathExists("event", "$.tags.referrer")(eventContext(testEvent)))
+	assert.False(t, JSONPathExists("event", "$.tags")(eventContext("{invalid")))
+
+	assert.True(t, JSONPathEquals("event", "$.user.id", 42)(eventContext(testEvent)))
+	assert.True(t, JSONPathEquals("event", "$.items[*].sku", "B2")(eventContext(testEvent)))
+	assert.False(t, JSONPathEquals("event", "$.tags.channel", "app")(eventContext(testEvent)))
+
+	var decoded = map[string]interface{}{"status": "paid"}
+	assert.True(t, JSONPathEquals("event", "$.status", "paid")(eventContext(decoded)))
+}
+
+func TestJSONPath_Errors(t *testing.T) {
+	var rules = []*BaseRule[BestFirstRule]{
+		NewBestFirstRule().WithName("channel").OnEvalWithError(JSONPathExistsE("event", "$.tags.channel")),
+		NewBestFirstRule().WithName("paid").OnEvalWithError(JSONPathEqualsE("event", "$.status", "paid")),
+		NewBestFirstRule().WithName("email").OnExecuteWithError(ExtractJSONE("event", "$.user.email", "email")),
+	}
+	for _, r := range rules {
+		assert.NoError(t, BestFirstRuleRunner(NewRuleContext(), r))
+
+		ruleContext := NewRuleContext()
+		ruleContext.Set("event", testEvent)
+		assert.NoError(t, BestFirstRuleRunner(ruleContext, r))
+
+		ruleContext = NewRuleContext()
+		ruleContext.Set("event", "{invalid")
+		var err = BestFirstRuleRunner(ruleContext, r)
+		assert.ErrorContains(t, err, fmt.Sprintf(`rule %q in `, r.GetName()))
+		assert.ErrorContains(t, err, `json "event": invalid character`)
+	}
+}
+
+func FuzzParseJSONPath(f *testing.F) {
+	for _, expr := range []string{"$.user.id", "$['user']['email']", "$.items[-1].sku", "$.items[*].price", "$.tags.*", "$..id", "$.items[0"} {
+		f.Add(expr)
+	}
+	var rc = NewRuleContext()
+	rc.Set("event", testEvent)
+	doc, _, _ := jsonDocument(rc, "event")
+
+	f.Fuzz(func(t *testing.T, expr string) {
+		path, err := ParseJSONPath(expr)
+		if err != nil {
+			return
+		}
+		path.Find(doc)
+		if _, err := ParseJSONPath(path.String()); err != nil {
+			t.Fatalf("%q doesn't parse back: %v", path.String(), err)
+		}
+	})
+}
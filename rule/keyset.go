@@ -0,0 +1,175 @@
+package rule
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/leoslamas/dredd-go/internal/ident"
+)
+
+// KeyInfo describes a registered context key.
+type KeyInfo struct {
+	Name        string
+	Type        reflect.Type
+	Description string
+}
+
+// KeySet is a registry of known context keys with their value types and descriptions.
+type KeySet struct {
+	keys  map[string]KeyInfo
+	order []string
+}
+
+// NewKeySet creates an empty KeySet.
+func NewKeySet() *KeySet {
+	return &KeySet{keys: make(map[string]KeyInfo)}
+}
+
+// Register adds a key to the set. The type of sample is recorded as the key's
+// value type; a nil sample accepts values of any type.
+// It panics if the key is already registered.
+func (ks *KeySet) Register(name string, sample interface{}, description string) *KeySet {
+	if _, ok := ks.keys[name]; ok {
+		panic(fmt.Sprintf("KeySet key %q already registered", name))
+	}
+
+	ks.keys[name] = KeyInfo{Name: name, Type: reflect.TypeOf(sample), Description: description}
+	ks.order = append(ks.order, name)
+	return ks
+}
+
+// Lookup returns the registered information for a key.
+func (ks *KeySet) Lookup(name string) (KeyInfo, bool) {
+	info, ok := ks.keys[name]
+	return info, ok
+}
+
+// Keys returns the registered keys in registration order.
+func (ks *KeySet) Keys() []KeyInfo {
+	var keys = make([]KeyInfo, 0, len(ks.order))
+	for _, name := range ks.order {
+		keys = append(keys, ks.keys[name])
+	}
+	return keys
+}
+
+// WriteConstants writes a Go source file declaring a constant for every
+// registered key, so hooks can refer to keys by name instead of string literals.
+// It returns an error without writing anything when two keys, such as
+// "user_id" and "user.id", would get the same constant name.
+func (ks *KeySet) WriteConstants(w io.Writer, pkg string) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "// Code generated from a rule.KeySet. DO NOT EDIT.\n\npackage %s\n\n", pkg)
+	b.WriteString("const (\n")
+	var names = make(map[string]string)
+	for _, info := range ks.Keys() {
+		var name = "Key" + ident.Exported(info.Name)
+		if other, ok := names[name]; ok {
+			return fmt.Errorf("write constants: keys %q and %q have the same name %q", other, info.Name, name)
+		}
+		names[name] = info.Name
+		if info.Description != "" {
+			fmt.Fprintf(&b, "\t// %s: %s\n", name, info.Description)
+		}
+		fmt.Fprintf(&b, "\t%s = %q\n", name, info.Name)
+	}
+	b.WriteString(")\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WithKeySet makes the RuleContext strict: reading or writing a key that is not
+// registered in the set, or writing a value of the wrong type, is an error.
+// As with WithStrictKeys, the run stops once the hook returns, with a
+// *RuleError naming the rule and wrapping a *KeySetError for every violation;
+// rejected writes are not applied. Outside of a run, where there is no run to
+// stop, violations panic.
+func WithKeySet(ks *KeySet) ContextOption {
+	return func(rc *RuleContext) {
+		rc.keySet = ks
+	}
+}
+
+// KeySetError reports a read or write of a key breaking the KeySet of a
+// context created with WithKeySet.
+type KeySetError struct {
+	Key string
+	// Expected is the registered type of the key, or nil when the key is not
+	// registered.
+	Expected reflect.Type
+	// Got is the type of the written value, when the key is registered.
+	Got reflect.Type
+}
+
+func (e *KeySetError) Error() string {
+	if e.Expected == nil {
+		return fmt.Sprintf("KeySet key %q is not registered", e.Key)
+	}
+	return fmt.Sprintf("KeySet key %q expects %v, got %v", e.Key, e.Expected, e.Got)
+}
+
+func (ks *KeySet) check(key string, value interface{}, write bool) error {
+	info, ok := ks.keys[key]
+	if !ok {
+		return &KeySetError{Key: key}
+	}
+
+	if write && info.Type != nil && value != nil && !reflect.TypeOf(value).AssignableTo(info.Type) {
+		return &KeySetError{Key: key, Expected: info.Type, Got: reflect.TypeOf(value)}
+	}
+	return nil
+}
+
+// checkKey checks an access to a key against the KeySet of the context,
+// reporting whether it is allowed.
+func (rc *RuleContext) checkKey(key string, value interface{}, write bool) bool {
+	if rc.keySet == nil {
+		return true
+	}
+	var err = rc.keySet.check(key, value, write)
+	if err == nil {
+		return true
+	}
+	if rc.current == nil {
+		panic(err.Error())
+	}
+	rc.failure = errors.Join(rc.failure, err)
+	return false
+}
+
+// Key is a typed handle to a context key, avoiding string literals and
+// type assertions in hooks.
+type Key[T any] struct {
+	name string
+}
+
+// DefineKey registers a typed key in the set and returns a handle to it.
+func DefineKey[T any](ks *KeySet, name string, description string) Key[T] {
+	var zero T
+	ks.Register(name, zero, description)
+	if ks.keys[name].Type == nil {
+		// T is an interface type; record it so the type is still enforced.
+		ks.keys[name] = KeyInfo{Name: name, Type: reflect.TypeOf((*T)(nil)).Elem(), Description: description}
+	}
+	return Key[T]{name: name}
+}
+
+// Name returns the context key name.
+func (k Key[T]) Name() string {
+	return k.name
+}
+
+// Get returns the value stored under the key and whether it is present with type T.
+func (k Key[T]) Get(rc *RuleContext) (T, bool) {
+	value, ok := rc.Get(k.name).(T)
+	return value, ok
+}
+
+// Set stores the value under the key.
+func (k Key[T]) Set(rc *RuleContext, value T) {
+	rc.Set(k.name, value)
+}
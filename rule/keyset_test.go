@@ -0,0 +1,104 @@
+package rule
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeySet_Register(t *testing.T) {
+	ks := NewKeySet().
+		Register("order.total", 0.0, "Total amount of the order.").
+		Register("approved", false, "")
+
+	info, ok := ks.Lookup("order.total")
+	assert.True(t, ok)
+	assert.Equal(t, "float64", info.Type.String())
+	assert.Equal(t, "Total amount of the order.", info.Description)
+	assert.Equal(t, 2, len(ks.Keys()))
+	assert.Equal(t, "approved", ks.Keys()[1].Name)
+
+	assert.PanicsWithValue(t, `KeySet key "approved" already registered`, func() {
+		ks.Register("approved", true, "")
+	})
+}
+
+func TestKeySet_WriteConstants(t *testing.T) {
+	ks := NewKeySet().Register("order.total", 0.0, "Total amount of the order.")
+
+	var b strings.Builder
+	assert.NoError(t, ks.WriteConstants(&b, "checkout"))
+	assert.Contains(t, b.String(), "package checkout")
+	assert.Contains(t, b.String(), "// KeyOrderTotal: Total amount of the order.")
+	assert.Contains(t, b.String(), `KeyOrderTotal = "order.total"`)
+
+	ks.Register("order_total", 0.0, "")
+	b.Reset()
+	assert.EqualError(t, ks.WriteConstants(&b, "checkout"),
+		`write constants: keys "order.total" and "order_total" have the same name "KeyOrderTotal"`)
+	assert.Empty(t, b.String())
+}
+
+func TestKeySet_StrictContext(t *testing.T) {
+	ks := NewKeySet().Register("value", false, "").Register("any", nil, "")
+	rc := NewRuleContext(WithKeySet(ks))
+
+	rc.Set("value", true)
+	rc.Set("any", "text")
+	assert.True(t, rc.Get("value").(bool))
+
+	assert.PanicsWithValue(t, `KeySet key "unknown" is not registered`, func() {
+		rc.Get("unknown")
+	})
+	assert.PanicsWithValue(t, `KeySet key "unknown" is not registered`, func() {
+		rc.Set("unknown", 1)
+	})
+	assert.PanicsWithValue(t, `KeySet key "value" expects bool, got string`, func() {
+		rc.Set("value", "true")
+	})
+}
+
+func TestKeySet_StrictRun(t *testing.T) {
+	ks := NewKeySet().Register("value", false, "")
+	var rule = NewChainRule().WithName("writer").OnExecute(func(ctx Context) {
+		ctx.GetRuleContext().Set("value", "true")
+		ctx.GetRuleContext().Set("unknown", 1)
+	})
+
+	rc := NewRuleContext(WithKeySet(ks))
+	var err = ChainRuleRunner(rc, rule)
+	assert.EqualError(t, err, "rule \"writer\" in execute: KeySet key \"value\" expects bool, got string\nKeySet key \"unknown\" is not registered")
+	var keyErr *KeySetError
+	assert.ErrorAs(t, err, &keyErr)
+	assert.Equal(t, "value", keyErr.Key)
+	assert.Nil(t, rc.Get("value"))
+
+	rule.OnEval(func(ctx Context) bool { return ctx.GetRuleContext().Get("missing") == nil })
+	assert.EqualError(t, ChainRuleRunner(NewRuleContext(WithKeySet(ks)), rule), `rule "writer" in eval: KeySet key "missing" is not registered`)
+}
+
+func TestKey_GetAndSet(t *testing.T) {
+	ks := NewKeySet()
+	total := DefineKey[float64](ks, "order.total", "")
+	rc := NewRuleContext(WithKeySet(ks))
+
+	_, ok := total.Get(rc)
+	assert.False(t, ok)
+
+	total.Set(rc, 42.5)
+	value, ok := total.Get(rc)
+	assert.True(t, ok)
+	assert.Equal(t, 42.5, value)
+	assert.Equal(t, "order.total", total.Name())
+}
+
+func TestKey_InterfaceType(t *testing.T) {
+	ks := NewKeySet()
+	err := DefineKey[error](ks, "err", "")
+	rc := NewRuleContext(WithKeySet(ks))
+
+	assert.PanicsWithValue(t, `KeySet key "err" expects error, got int`, func() {
+		rc.Set(err.Name(), 1)
+	})
+}
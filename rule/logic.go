@@ -0,0 +1,36 @@
+package rule
+
+// And returns an OnEval predicate reporting whether all the predicates are
+// true. They are evaluated in order, stopping at the first false one, so
+// cheap conditions should come first. And with no predicates is true.
+func And(preds ...func(Context) bool) func(Context) bool {
+	return func(ctx Context) bool {
+		for _, pred := range preds {
+			if !pred(ctx) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// Or returns an OnEval predicate reporting whether any of the predicates is
+// true. They are evaluated in order, stopping at the first true one. Or with
+// no predicates is false.
+func Or(preds ...func(Context) bool) func(Context) bool {
+	return func(ctx Context) bool {
+		for _, pred := range preds {
+			if pred(ctx) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Not returns an OnEval predicate negating pred.
+func Not(pred func(Context) bool) func(Context) bool {
+	return func(ctx Context) bool {
+		return !pred(ctx)
+	}
+}
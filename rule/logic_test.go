@@ -0,0 +1,56 @@
+package rule
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogic(t *testing.T) {
+	var calls []string
+	var pred = func(name string, result bool) func(Context) bool {
+		return func(Context) bool {
+			calls = append(calls, name)
+			return result
+		}
+	}
+
+	var cases = []struct {
+		name   string
+		pred   func(Context) bool
+		result bool
+		calls  []string
+	}{
+		{"and", And(pred("a", true), pred("b", true)), true, []string{"a", "b"}},
+		{"and short-circuits", And(pred("a", false), pred("b", true)), false, []string{"a"}},
+		{"empty and", And(), true, nil},
+		{"or", Or(pred("a", false), pred("b", false)), false, []string{"a", "b"}},
+		{"or short-circuits", Or(pred("a", true), pred("b", false)), true, []string{"a"}},
+		{"empty or", Or(), false, nil},
+		{"not", Not(pred("a", true)), false, []string{"a"}},
+		{"nested", Or(And(pred("a", true), Not(pred("b", true))), pred("c", true)), true, []string{"a", "b", "c"}},
+	}
+	for _, c := range cases {
+		calls = nil
+		assert.Equal(t, c.result, c.pred(NewChainRule()), c.name)
+		assert.Equal(t, c.calls, calls, c.name)
+	}
+}
+
+func TestLogic_Rule(t *testing.T) {
+	var highRisk = Or(KeyMatches("email", `@example\.com$`), InCIDR("ip", "10.0.0.0/8"))
+	var rule = NewChainRule().
+		OnEval(And(highRisk, Not(ConfigEnabled(ConfigMap{"allow": "false"}, "allow")))).
+		OnExecute(func(ctx Context) { ctx.GetRuleContext().Set("blocked", true) })
+
+	ruleContext := NewRuleContext()
+	ruleContext.Set("email", "joe@example.com")
+	assert.NoError(t, ChainRuleRunner(ruleContext, rule))
+	assert.Equal(t, true, ruleContext.Get("blocked"))
+
+	ruleContext = NewRuleContext()
+	ruleContext.Set("email", "joe@mail.com")
+	ruleContext.Set("ip", "192.168.0.1")
+	assert.NoError(t, ChainRuleRunner(ruleContext, rule))
+	assert.Nil(t, ruleContext.Get("blocked"))
+}
@@ -0,0 +1,92 @@
+package rule
+
+import "fmt"
+
+// Table is a lookup table InTable can check membership in.
+type Table interface {
+	Contains(key interface{}) bool
+}
+
+// LookupTable is a hash table from typed keys to values. Used with empty
+// struct values, as created by NewSet, it is a set. It must not be modified
+// while runs read it.
+type LookupTable[K comparable, V any] struct {
+	entries map[K]V
+}
+
+// NewLookupTable creates a LookupTable holding a copy of the entries.
+func NewLookupTable[K comparable, V any](entries map[K]V) *LookupTable[K, V] {
+	var t = &LookupTable[K, V]{entries: make(map[K]V, len(entries))}
+	for k, v := range entries {
+		t.entries[k] = v
+	}
+	return t
+}
+
+// NewSet creates a LookupTable holding the keys.
+func NewSet[K comparable](keys ...K) *LookupTable[K, struct{}] {
+	var t = &LookupTable[K, struct{}]{entries: make(map[K]struct{}, len(keys))}
+	for _, k := range keys {
+		t.entries[k] = struct{}{}
+	}
+	return t
+}
+
+// Lookup returns the value of the key and whether it is in the table.
+func (t *LookupTable[K, V]) Lookup(key K) (V, bool) {
+	v, ok := t.entries[key]
+	return v, ok
+}
+
+// Contains reports whether the key is in the table. Keys of another type than
+// K are never in it.
+func (t *LookupTable[K, V]) Contains(key interface{}) bool {
+	var k, ok = key.(K)
+	if !ok {
+		return false
+	}
+	_, ok = t.entries[k]
+	return ok
+}
+
+// Len returns the number of entries.
+func (t *LookupTable[K, V]) Len() int {
+	return len(t.entries)
+}
+
+// InTable returns an OnEval predicate reporting whether the value of key is in
+// the lookup table stored under the table key, typically in the global
+// context. The table is a Table, such as a LookupTable, or a map with string
+// keys as loaded by the refdata package, in which case values of other types
+// are looked up by their fmt.Sprint form. A missing table or key evaluates to
+// false.
+func InTable(table string, key string) func(Context) bool {
+	return func(ctx Context) bool {
+		var rc = ctx.GetRuleContext()
+		var value = rc.Get(key)
+		if value == nil {
+			return false
+		}
+
+		switch t := rc.Get(table).(type) {
+		case Table:
+			return t.Contains(value)
+		case map[string]bool:
+			return t[tableKey(value)]
+		case map[string]struct{}:
+			var _, ok = t[tableKey(value)]
+			return ok
+		case map[string]interface{}:
+			var _, ok = t[tableKey(value)]
+			return ok
+		}
+		return false
+	}
+}
+
+func tableKey(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	return fmt.Sprint(value)
+}
@@ -0,0 +1,72 @@
+package rule
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func lookupContext(table interface{}, user interface{}) Context {
+	var global = NewGlobalContext(map[string]interface{}{"blocked_users": table})
+	var rc = NewRuleContext(WithGlobal(global))
+	if user != nil {
+		rc.Set("user", user)
+	}
+	return &BaseRule[ChainRule]{context: rc}
+}
+
+func TestLookupTable(t *testing.T) {
+	var prices = NewLookupTable(map[string]int{"basic": 10, "pro": 25})
+	price, ok := prices.Lookup("pro")
+	assert.True(t, ok)
+	assert.Equal(t, 25, price)
+	assert.Equal(t, 2, prices.Len())
+	assert.True(t, prices.Contains("basic"))
+	assert.False(t, prices.Contains(10))
+}
+
+func TestInTable(t *testing.T) {
+	var blocked = InTable("blocked_users", "user")
+
+	assert.True(t, blocked(lookupContext(NewSet(42, 7), 42)))
+	assert.False(t, blocked(lookupContext(NewSet(42, 7), 8)))
+	assert.False(t, blocked(lookupContext(NewSet(42, 7), "42")))
+	assert.True(t, blocked(lookupContext(map[string]bool{"42": true}, 42)))
+	assert.True(t, blocked(lookupContext(map[string]interface{}{"alice": 1}, "alice")))
+	assert.True(t, blocked(lookupContext(map[string]struct{}{"alice": {}}, "alice")))
+	assert.False(t, blocked(lookupContext(NewSet(42), nil)))
+	assert.False(t, blocked(lookupContext([]int{42}, 42)))
+}
+
+func BenchmarkInTable(b *testing.B) {
+	var users = make([]int, 100000)
+	for i := range users {
+		users[i] = i * 3
+	}
+	var blocked = InTable("blocked_users", "user")
+	var ctx = lookupContext(NewSet(users...), 99999)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		blocked(ctx)
+	}
+}
+
+func BenchmarkLinearScan(b *testing.B) {
+	var users = make([]string, 100000)
+	for i := range users {
+		users[i] = fmt.Sprint(i * 3)
+	}
+	var ctx = lookupContext(users, "99999")
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var user = ctx.GetRuleContext().Get("user")
+		for _, u := range ctx.GetRuleContext().Get("blocked_users").([]string) {
+			if u == user {
+				break
+			}
+		}
+	}
+}
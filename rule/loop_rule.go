@@ -0,0 +1,76 @@
+package rule
+
+// NewLoopRule creates a rule that runs repeatedly: as long as its OnEval
+// returns true, it executes, runs its children, and is evaluated again, up to
+// maxIterations times. Hooks read the current iteration with Iteration. The
+// loop also stops with an error when the GoContext of the run is canceled.
+//
+// T is the kind of the tree the rule is part of, such as ChainRule or
+// BestFirstRule, and the children of the rule run as in such a tree.
+// It panics if maxIterations is not positive.
+func NewLoopRule[T any](maxIterations int) *BaseRule[T] {
+	if maxIterations < 1 {
+		panic("loop rule max iterations must be positive")
+	}
+	return &BaseRule[T]{
+		ruleType:      LoopRuleType,
+		context:       NewRuleContext(),
+		children:      make([]*BaseRule[T], 0),
+		maxIterations: maxIterations,
+		onEval:        func(r Context) bool { return true },
+		onPreExecute:  func(r Context) {},
+		onExecute:     func(r Context) {},
+		onPostExecute: func(r Context) {},
+	}
+}
+
+// Iteration returns the current iteration, starting at 1, of the innermost
+// loop rule running the rule or one of its ancestors, or 0 outside of a loop.
+func (r *BaseRule[T]) Iteration() int {
+	for l := r; l != nil; l = l.parent {
+		if l.ruleType == LoopRuleType && l.iteration > 0 {
+			return l.iteration
+		}
+	}
+	return 0
+}
+
+// Iteration returns the current loop iteration of the rule, as
+// BaseRule.Iteration does, from the Context passed to its hooks.
+func Iteration(ctx Context) int {
+	if l, ok := ctx.(interface{ Iteration() int }); ok {
+		return l.Iteration()
+	}
+	return 0
+}
+
+// loop runs the matched loop rule and re-evaluates it until it no longer
+// matches or reaches its maximum iterations.
+func (r *BaseRule[T]) loop() error {
+	defer func() { r.iteration = 0 }()
+	for r.iteration = 1; ; r.iteration++ {
+		if err := r.run(); err != nil {
+			return err
+		}
+		if err := r.runChildren(); err != nil {
+			return err
+		}
+		if r.iteration == r.maxIterations {
+			return nil
+		}
+		if rc := r.GetRuleContext(); rc != nil {
+			if err := rc.GoContext().Err(); err != nil {
+				return r.newError("", err)
+			}
+		}
+
+		var matched = r.eval()
+		if err := r.failed(PhaseEval); err != nil {
+			return err
+		}
+		r.trace(TraceEval, matched)
+		if !matched {
+			return nil
+		}
+	}
+}
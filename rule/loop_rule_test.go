@@ -0,0 +1,92 @@
+package rule
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func countdown() *BaseRule[ChainRule] {
+	return NewLoopRule[ChainRule](10).WithName("countdown").
+		OnEval(func(ctx Context) bool { return ctx.GetRuleContext().Get("n").(int) > 0 }).
+		OnExecute(func(ctx Context) {
+			var rc = ctx.GetRuleContext()
+			rc.Set("n", rc.Get("n").(int)-1)
+		})
+}
+
+func TestLoopRule(t *testing.T) {
+	var iterations []int
+	var loop = countdown().AddChildren(NewChainRule().OnExecute(func(ctx Context) {
+		iterations = append(iterations, Iteration(ctx))
+	}))
+
+	ruleContext := NewRuleContext()
+	ruleContext.Set("n", 3)
+	assert.NoError(t, ChainRuleRunner(ruleContext, loop))
+
+	assert.Equal(t, 0, ruleContext.Get("n"))
+	assert.Equal(t, []int{1, 2, 3}, iterations)
+	assert.Equal(t, 0, loop.Iteration())
+	assert.Equal(t, LoopRuleType, loop.RuleType())
+	assert.Equal(t, "loop", loop.RuleType().String())
+}
+
+func TestLoopRule_MaxIterations(t *testing.T) {
+	ruleContext := NewRuleContext()
+	ruleContext.Set("n", 100)
+	assert.NoError(t, ChainRuleRunner(ruleContext, countdown()))
+	assert.Equal(t, 90, ruleContext.Get("n"))
+
+	assert.PanicsWithValue(t, "loop rule max iterations must be positive", func() { NewLoopRule[ChainRule](0) })
+}
+
+func TestLoopRule_NotMatched(t *testing.T) {
+	ruleContext := NewRuleContext()
+	ruleContext.Set("n", 0)
+	assert.NoError(t, BestFirstRuleRunner(ruleContext,
+		NewLoopRule[BestFirstRule](5).OnEval(func(Context) bool { return false }),
+		NewBestFirstRule().OnExecute(func(ctx Context) { ctx.GetRuleContext().Set("next", true) }),
+	))
+	assert.Equal(t, true, ruleContext.Get("next"))
+}
+
+func TestLoopRule_BestFirstChildren(t *testing.T) {
+	var loop = NewLoopRule[BestFirstRule](3).AddChildren(
+		NewBestFirstRule().OnEval(func(ctx Context) bool { return Iteration(ctx) == 2 }).
+			OnExecute(func(ctx Context) { ctx.GetRuleContext().Set("second", Iteration(ctx)) }),
+		NewBestFirstRule().OnExecute(func(ctx Context) {
+			var rc = ctx.GetRuleContext()
+			var count, _ = rc.Get("other").(int)
+			rc.Set("other", count+1)
+		}),
+	)
+
+	ruleContext := NewRuleContext()
+	assert.NoError(t, BestFirstRuleRunner(ruleContext, loop))
+	assert.Equal(t, 2, ruleContext.Get("second"))
+	assert.Equal(t, 2, ruleContext.Get("other"))
+}
+
+func TestLoopRule_ChainChildren(t *testing.T) {
+	assert.Equal(t, ErrTooManyChildren, NewLoopRule[ChainRule](1).AddChildrenE(NewChainRule(), NewChainRule()))
+	assert.NoError(t, NewLoopRule[BestFirstRule](1).AddChildrenE(NewBestFirstRule(), NewBestFirstRule()))
+}
+
+func TestLoopRule_Canceled(t *testing.T) {
+	var ctx, cancel = context.WithCancel(context.Background())
+	var loop = NewLoopRule[ChainRule](10).WithName("poll").OnExecute(func(Context) { cancel() })
+
+	var err = ChainRuleRunner(NewRuleContext(WithGoContext(ctx)), loop)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.EqualError(t, err, `rule "poll": context canceled`)
+}
+
+func TestLoopRule_Program(t *testing.T) {
+	var program = NewProgram(countdown())
+	ruleContext := NewRuleContext()
+	ruleContext.Set("n", 2)
+	assert.NoError(t, program(ruleContext))
+	assert.Equal(t, 0, ruleContext.Get("n"))
+}
@@ -0,0 +1,55 @@
+package rule
+
+import (
+	"regexp"
+	"strings"
+)
+
+// KeyMatches returns an OnEval predicate reporting whether the string stored
+// under key matches the regular expression pattern.
+// The pattern is compiled once, when the predicate is created; an invalid
+// pattern panics. Missing keys and non-string values never match.
+func KeyMatches(key string, pattern string) func(Context) bool {
+	return matchKey(key, regexp.MustCompile(pattern))
+}
+
+// KeyMatchesGlob returns an OnEval predicate reporting whether the string
+// stored under key matches the glob pattern. '*' matches any sequence of
+// characters, including newlines, '?' matches a single character, and '\'
+// escapes the next one.
+// The pattern is compiled once, when the predicate is created.
+func KeyMatchesGlob(key string, pattern string) func(Context) bool {
+	return matchKey(key, regexp.MustCompile(globToRegexp(pattern)))
+}
+
+func matchKey(key string, re *regexp.Regexp) func(Context) bool {
+	return func(ctx Context) bool {
+		s, ok := ctx.GetRuleContext().Get(key).(string)
+		return ok && re.MatchString(s)
+	}
+}
+
+func globToRegexp(pattern string) string {
+	var b strings.Builder
+	b.WriteString(`(?s)^`)
+	var runes = []rune(pattern)
+	for i := 0; i < len(runes); i++ {
+		switch c := runes[i]; c {
+		case '*':
+			b.WriteString(`.*`)
+		case '?':
+			b.WriteString(`.`)
+		case '\\':
+			if i+1 < len(runes) {
+				i++
+				b.WriteString(regexp.QuoteMeta(string(runes[i])))
+			} else {
+				b.WriteString(`\\`)
+			}
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString(`$`)
+	return b.String()
+}
@@ -0,0 +1,71 @@
+package rule
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func matchContext(key string, value interface{}) Context {
+	rule := NewChainRule()
+	rule.GetRuleContext().Set(key, value)
+	return rule
+}
+
+func TestKeyMatches(t *testing.T) {
+	var match = KeyMatches("email", `@example\.com$`)
+
+	assert.True(t, match(matchContext("email", "john@example.com")))
+	assert.False(t, match(matchContext("email", "john@example.org")))
+	assert.False(t, match(matchContext("email", 42)))
+	assert.False(t, match(matchContext("other", "john@example.com")))
+}
+
+func TestKeyMatches_PanicsOnInvalidPattern(t *testing.T) {
+	assert.Panics(t, func() { KeyMatches("email", `(`) })
+}
+
+func TestKeyMatchesGlob(t *testing.T) {
+	var match = KeyMatchesGlob("file", `*.tar.gz`)
+
+	assert.True(t, match(matchContext("file", "backup.tar.gz")))
+	assert.False(t, match(matchContext("file", "backup.tar.gz.sig")))
+	assert.False(t, match(matchContext("file", "backup.tgz")))
+
+	assert.True(t, KeyMatchesGlob("code", `A?-\*`)(matchContext("code", "AB-*")))
+	assert.False(t, KeyMatchesGlob("code", `A?-\*`)(matchContext("code", "AB-C")))
+	assert.True(t, KeyMatchesGlob("name", `café*`)(matchContext("name", "café crème")))
+	assert.True(t, KeyMatchesGlob("name", `caf?`)(matchContext("name", "café")))
+	assert.True(t, KeyMatchesGlob("name", `\é*`)(matchContext("name", "été")))
+	assert.True(t, KeyMatchesGlob("note", `a*b`)(matchContext("note", "a\nb")))
+}
+
+func BenchmarkKeyMatches(b *testing.B) {
+	var ctx = matchContext("email", "john.doe@example.com")
+	var match = KeyMatches("email", `^[a-z.]+@example\.com$`)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		match(ctx)
+	}
+}
+
+func BenchmarkKeyMatches_CompilePerEval(b *testing.B) {
+	var ctx = matchContext("email", "john.doe@example.com")
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		regexp.MustCompile(`^[a-z.]+@example\.com$`).MatchString(ctx.GetRuleContext().Get("email").(string))
+	}
+}
+
+func BenchmarkKeyMatchesGlob(b *testing.B) {
+	var ctx = matchContext("file", "reports/2024/summary.csv")
+	var match = KeyMatchesGlob("file", `reports/*.csv`)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		match(ctx)
+	}
+}
@@ -0,0 +1,123 @@
+package rule
+
+import (
+	"fmt"
+	"net"
+	"net/netip"
+)
+
+// InCIDR returns an OnEval predicate reporting whether the IP address stored
+// under key belongs to one of the CIDR prefixes. The value may be a string,
+// a netip.Addr or a net.IP. The prefixes are parsed once, when the predicate
+// is created; an invalid prefix panics.
+func InCIDR(key string, cidrs ...string) func(Context) bool {
+	var prefixes = make([]netip.Prefix, len(cidrs))
+	for i, cidr := range cidrs {
+		prefixes[i] = netip.MustParsePrefix(cidr).Masked()
+	}
+
+	return func(ctx Context) bool {
+		addr, ok := addrOf(ctx.GetRuleContext().Get(key))
+		if !ok {
+			return false
+		}
+		for _, p := range prefixes {
+			if p.Contains(addr) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// GeoIP resolves the ISO country code of an IP address, e.g. backed by a
+// MaxMind database.
+type GeoIP interface {
+	Country(addr netip.Addr) (string, error)
+}
+
+// GeoIPFunc adapts an ordinary function to the GeoIP interface.
+type GeoIPFunc func(addr netip.Addr) (string, error)
+
+// Country calls f(addr).
+func (f GeoIPFunc) Country(addr netip.Addr) (string, error) {
+	return f(addr)
+}
+
+// InCountry returns an OnEval predicate reporting whether the IP address
+// stored under key is located in one of the countries. Lookup errors and
+// invalid addresses evaluate to false; use InCountryE to stop the run on
+// lookup errors instead.
+func InCountry(geo GeoIP, key string, countries ...string) func(Context) bool {
+	var eval = InCountryE(geo, key, countries...)
+	return func(ctx Context) bool {
+		var matched, err = eval(ctx)
+		return err == nil && matched
+	}
+}
+
+// InCountryE is like InCountry but returns lookup errors, for
+// OnEvalWithError. Invalid addresses still evaluate to false.
+func InCountryE(geo GeoIP, key string, countries ...string) func(Context) (bool, error) {
+	var set = make(map[string]bool, len(countries))
+	for _, c := range countries {
+		set[c] = true
+	}
+
+	return func(ctx Context) (bool, error) {
+		addr, ok := addrOf(ctx.GetRuleContext().Get(key))
+		if !ok {
+			return false, nil
+		}
+		country, err := geo.Country(addr)
+		if err != nil {
+			return false, fmt.Errorf("geoip %s: %w", addr, err)
+		}
+		return set[country], nil
+	}
+}
+
+// LookupCountry returns an execute hook storing the country of the IP address
+// stored under key into the output key. Lookup errors and invalid addresses
+// leave the context untouched; use LookupCountryE to stop the run on lookup
+// errors instead.
+func LookupCountry(geo GeoIP, key string, output string) func(Context) {
+	var lookup = LookupCountryE(geo, key, output)
+	return func(ctx Context) {
+		_ = lookup(ctx)
+	}
+}
+
+// LookupCountryE is like LookupCountry but returns lookup errors, for
+// OnExecuteWithError. Invalid addresses still leave the context untouched.
+func LookupCountryE(geo GeoIP, key string, output string) func(Context) error {
+	return func(ctx Context) error {
+		var rc = ctx.GetRuleContext()
+		addr, ok := addrOf(rc.Get(key))
+		if !ok {
+			return nil
+		}
+		country, err := geo.Country(addr)
+		if err != nil {
+			return fmt.Errorf("geoip %s: %w", addr, err)
+		}
+		rc.Set(output, country)
+		return nil
+	}
+}
+
+func addrOf(value interface{}) (netip.Addr, bool) {
+	var addr netip.Addr
+	var ok bool
+	switch v := value.(type) {
+	case netip.Addr:
+		addr, ok = v, v.IsValid()
+	case net.IP:
+		addr, ok = netip.AddrFromSlice(v)
+	case string:
+		var err error
+		addr, err = netip.ParseAddr(v)
+		ok = err == nil
+	}
+	return addr.Unmap(), ok
+}
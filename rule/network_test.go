@@ -0,0 +1,76 @@
+package rule
+
+import (
+	"errors"
+	"net"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func ipContext(value interface{}) Context {
+	rule := NewChainRule()
+	rule.GetRuleContext().Set("ip", value)
+	return rule
+}
+
+func TestInCIDR(t *testing.T) {
+	var internal = InCIDR("ip", "10.0.0.0/8", "192.168.1.0/24", "fd00::/8")
+
+	assert.True(t, internal(ipContext("10.1.2.3")))
+	assert.True(t, internal(ipContext(net.ParseIP("192.168.1.20"))))
+	assert.True(t, internal(ipContext(netip.MustParseAddr("fd00::1"))))
+	assert.True(t, internal(ipContext("::ffff:10.0.0.1")))
+	assert.False(t, internal(ipContext("192.168.2.1")))
+	assert.False(t, internal(ipContext("not an ip")))
+	assert.False(t, internal(ipContext(42)))
+
+	assert.Panics(t, func() { InCIDR("ip", "10.0.0.0/33") })
+}
+
+var testGeo = GeoIPFunc(func(addr netip.Addr) (string, error) {
+	switch addr.String() {
+	case "200.1.1.1":
+		return "BR", nil
+	case "8.8.8.8":
+		return "US", nil
+	}
+	return "", errors.New("not found")
+})
+
+func TestInCountry(t *testing.T) {
+	var latam = InCountry(testGeo, "ip", "BR", "AR")
+
+	assert.True(t, latam(ipContext("200.1.1.1")))
+	assert.False(t, latam(ipContext("8.8.8.8")))
+	assert.False(t, latam(ipContext("1.1.1.1")))
+}
+
+func TestLookupCountry(t *testing.T) {
+	rule := NewChainRule().OnExecute(LookupCountry(testGeo, "ip", "country"))
+
+	ruleContext := NewRuleContext()
+	ruleContext.Set("ip", "8.8.8.8")
+	ChainRuleRunner(ruleContext, rule)
+
+	assert.Equal(t, "US", ruleContext.Get("country"))
+}
+
+func TestNetwork_Errors(t *testing.T) {
+	var latam = NewBestFirstRule().WithName("latam").OnEvalWithError(InCountryE(testGeo, "ip", "BR", "AR"))
+	var lookup = NewChainRule().WithName("lookup").OnExecuteWithError(LookupCountryE(testGeo, "ip", "country"))
+
+	for _, ip := range []string{"200.1.1.1", "8.8.8.8", "not an ip"} {
+		ruleContext := NewRuleContext()
+		ruleContext.Set("ip", ip)
+		assert.NoError(t, BestFirstRuleRunner(ruleContext, latam))
+		assert.NoError(t, ChainRuleRunner(ruleContext, lookup))
+	}
+
+	ruleContext := NewRuleContext()
+	ruleContext.Set("ip", "1.1.1.1")
+	assert.EqualError(t, BestFirstRuleRunner(ruleContext, latam), `rule "latam" in eval: geoip 1.1.1.1: not found`)
+	assert.EqualError(t, ChainRuleRunner(ruleContext, lookup), `rule "lookup" in execute: geoip 1.1.1.1: not found`)
+	assert.Nil(t, ruleContext.Get("country"))
+}
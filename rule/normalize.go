@@ -0,0 +1,84 @@
+package rule
+
+import (
+	"strings"
+	"unicode"
+)
+
+// foldTable maps lower case accented Latin letters to their unaccented form.
+var foldTable = func() map[rune]string {
+	var table = make(map[rune]string)
+	for base, accented := range map[string]string{
+		"a": "àáâãäåāăą", "c": "çćĉċč", "d": "ďđ", "e": "èéêëēĕėęě",
+		"g": "ĝğġģ", "h": "ĥħ", "i": "ìíîïĩīĭįı", "j": "ĵ", "k": "ķ",
+		"l": "ĺļľŀł", "n": "ñńņň", "o": "òóôõöøōŏő", "r": "ŕŗř",
+		"s": "śŝşš", "t": "ţťŧ", "u": "ùúûüũūŭůűų", "w": "ŵ", "y": "ýÿŷ",
+		"z": "źżž", "ss": "ß", "ae": "æ", "oe": "œ",
+	} {
+		for _, r := range accented {
+			table[r] = base
+		}
+	}
+	return table
+}()
+
+// Normalize prepares user-provided text for comparison: it trims and collapses
+// white space, lower cases, and folds accented Latin letters, so that
+// "  São   Paulo " becomes "sao paulo".
+func Normalize(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	var space = false
+	for _, r := range strings.TrimSpace(s) {
+		if unicode.IsSpace(r) {
+			space = true
+			continue
+		}
+		if space {
+			b.WriteByte(' ')
+			space = false
+		}
+
+		r = unicode.ToLower(r)
+		if folded, ok := foldTable[r]; ok {
+			b.WriteString(folded)
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// NormalizedIn returns an OnEval predicate reporting whether the normalized
+// string stored under key equals one of the normalized values. The values are
+// normalized once, when the predicate is created.
+func NormalizedIn(key string, values ...string) func(Context) bool {
+	var set = make(map[string]bool, len(values))
+	for _, v := range values {
+		set[Normalize(v)] = true
+	}
+	return func(ctx Context) bool {
+		s, ok := ctx.GetRuleContext().Get(key).(string)
+		return ok && set[Normalize(s)]
+	}
+}
+
+// NormalizedContains returns an OnEval predicate reporting whether the
+// normalized string stored under key contains the normalized substring.
+func NormalizedContains(key string, substr string) func(Context) bool {
+	substr = Normalize(substr)
+	return func(ctx Context) bool {
+		s, ok := ctx.GetRuleContext().Get(key).(string)
+		return ok && strings.Contains(Normalize(s), substr)
+	}
+}
+
+// NormalizedHasPrefix returns an OnEval predicate reporting whether the
+// normalized string stored under key starts with the normalized prefix.
+func NormalizedHasPrefix(key string, prefix string) func(Context) bool {
+	prefix = Normalize(prefix)
+	return func(ctx Context) bool {
+		s, ok := ctx.GetRuleContext().Get(key).(string)
+		return ok && strings.HasPrefix(Normalize(s), prefix)
+	}
+}
@@ -0,0 +1,41 @@
+package rule
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalize(t *testing.T) {
+	assert.Equal(t, "sao paulo", Normalize("  São \t  Paulo "))
+	assert.Equal(t, "strasse", Normalize("STRAßE"))
+	assert.Equal(t, "lodz", Normalize("Łódź"))
+	assert.Equal(t, "creme brulee", Normalize("Crème Brûlée"))
+	assert.Equal(t, "東京", Normalize("東京"))
+	assert.Equal(t, "", Normalize("   "))
+}
+
+func cityContext(city interface{}) Context {
+	rule := NewChainRule()
+	rule.GetRuleContext().Set("city", city)
+	return rule
+}
+
+func TestNormalizedIn(t *testing.T) {
+	var capital = NormalizedIn("city", "Brasília", "São Paulo")
+
+	assert.True(t, capital(cityContext("brasilia")))
+	assert.True(t, capital(cityContext(" SAO  PAULO")))
+	assert.False(t, capital(cityContext("Rio")))
+	assert.False(t, capital(cityContext(nil)))
+}
+
+func TestNormalizedContains(t *testing.T) {
+	assert.True(t, NormalizedContains("city", "JOSÉ")(cityContext("San Jose del Cabo")))
+	assert.False(t, NormalizedContains("city", "josé")(cityContext("San Juan")))
+}
+
+func TestNormalizedHasPrefix(t *testing.T) {
+	assert.True(t, NormalizedHasPrefix("city", "são")(cityContext("Sao Luis")))
+	assert.False(t, NormalizedHasPrefix("city", "luis")(cityContext("Sao Luis")))
+}
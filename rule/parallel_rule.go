@@ -0,0 +1,186 @@
+package rule
+
+import (
+	"context"
+	"sync"
+)
+
+type ParallelRule struct {
+	*BaseRule[ParallelRule]
+}
+
+// NewParallelRule creates a rule whose children are evaluated and executed
+// concurrently, one goroutine each, once the rule itself has run. It suits
+// children doing independent I/O, such as calls to several services.
+//
+// Each child runs against a private copy of the context taken when the rule
+// runs, so children don't see each other's writes. Once they are all done,
+// their writes, decisions, reasons and history are applied to the context in
+// the order of the children, so a later child wins when two write the same key.
+// Children share the budget of the run, see WithBudget: optional children are
+// admitted while the budget lasts, whichever branch charges it.
+//
+// The first error stops the run, as in errgroup: it cancels the GoContext of
+// the other children, and children not started yet are skipped. Canceling the
+// GoContext of the run skips them as well. With a Debugger attached, children
+// run one at a time so they can be stepped through, and so they do in a
+// Simulation.
+//
+// T is the kind of the tree the rule is part of, such as ChainRule or
+// BestFirstRule; in a best-first tree, the rule stops its siblings when it
+// matches, as other best-first rules do.
+func NewParallelRule[T any]() *BaseRule[T] {
+	return &BaseRule[T]{
+		ruleType:      ParallelRuleType,
+		context:       NewRuleContext(),
+		children:      make([]*BaseRule[T], 0),
+		onEval:        func(r Context) bool { return true },
+		onPreExecute:  func(r Context) {},
+		onExecute:     func(r Context) {},
+		onPostExecute: func(r Context) {},
+	}
+}
+
+// ParallelRuleRunner executes a list of rules concurrently within a given
+// RuleContext, as the children of a ParallelRule are.
+//
+// Returns:
+//   - The *RuleError stopping the run, if any.
+func ParallelRuleRunner[T any](ruleContext *RuleContext, rules ...*BaseRule[T]) error {
+	return RuleRunner(ParallelRuleType, ruleContext, rules...)
+}
+
+func runParallel[T any](rc *RuleContext, rules []*BaseRule[T]) error {
+	var ctx, cancel = context.WithCancel(rc.GoContext())
+	defer cancel()
+
+	var share = rc.share
+	if share == nil {
+		share = &budgetShare{spent: rc.spent}
+	}
+	var forks = make([]*RuleContext, len(rules))
+	var skipped = make([]error, len(rules))
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		first    error
+		panicked interface{}
+	)
+	for i, r := range rules {
+		forks[i] = rc.fork(ctx, share)
+		r.SetRuleContext(forks[i])
+
+		var fire = func() {
+			defer func() {
+				if p := recover(); p != nil {
+					once.Do(func() { panicked = p })
+					cancel()
+				}
+			}()
+			if err := ctx.Err(); err != nil {
+				skipped[i] = r.newError("", err)
+				return
+			}
+			if _, err := r.fire(); err != nil {
+				once.Do(func() { first = err })
+				cancel()
+			}
+		}
+		if rc.debugger != nil || rc.sequential {
+			fire()
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			fire()
+		}()
+	}
+	wg.Wait()
+
+	if panicked != nil {
+		panic(panicked)
+	}
+	for i, r := range rules {
+		rc.merge(forks[i])
+		r.SetRuleContext(rc)
+	}
+	rc.spent = share.spent
+	if first == nil {
+		for _, err := range skipped {
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return first
+}
+
+// budgetShare is the budget spent by the branches of a parallel rule, which
+// they charge together.
+type budgetShare struct {
+	mu    sync.Mutex
+	spent float64
+}
+
+// fork returns a copy of the context for a branch of a run, with empty
+// journals, so that merge can apply what the branch recorded.
+func (rc *RuleContext) fork(ctx context.Context, share *budgetShare) *RuleContext {
+	var f = *rc
+	f.context = copyMap(rc.context)
+	f.shared = nil
+	f.provenance = nil
+	f.history = nil
+	f.evaluations = nil
+	f.reads = nil
+	f.reasons = nil
+	f.decisions = nil
+	f.skipped = nil
+	f.usage = nil
+	f.watch = &keyWatch{reads: make(map[string]bool), writes: make(map[string]bool)}
+	f.nested = nil
+	f.failure = nil
+	f.goContext = ctx
+	f.share = share
+	return &f
+}
+
+// merge applies to the context what the branch f wrote and recorded. Keys
+// are merged when the branch wrote them, even with the value they already
+// had, so that a later child wins over an earlier one.
+func (rc *RuleContext) merge(f *RuleContext) {
+	for k := range f.watch.writes {
+		if v, ok := f.context[k]; ok {
+			rc.context[k] = v
+		} else {
+			delete(rc.context, k)
+			delete(rc.provenance, k)
+		}
+		if rc.watch != nil {
+			rc.watch.writes[k] = true
+		}
+	}
+	if rc.watch != nil {
+		for k := range f.watch.reads {
+			rc.watch.reads[k] = true
+		}
+	}
+	for k, r := range f.provenance {
+		if rc.provenance == nil {
+			rc.provenance = make(map[string]Context)
+		}
+		rc.provenance[k] = r
+	}
+	rc.shared = nil
+
+	rc.history = append(rc.history, f.history...)
+	rc.evaluations = append(rc.evaluations, f.evaluations...)
+	rc.reads = append(rc.reads, f.reads...)
+	rc.reasons = append(rc.reasons, f.reasons...)
+	rc.decisions = append(rc.decisions, f.decisions...)
+	rc.skipped = append(rc.skipped, f.skipped...)
+	for _, u := range f.usage {
+		rc.charge(u.Rule, u.Duration)
+	}
+	rc.degraded = rc.degraded || f.degraded
+}
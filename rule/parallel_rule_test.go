@@ -0,0 +1,168 @@
+package rule
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewParallelRule(t *testing.T) {
+	rule := NewParallelRule[ChainRule]()
+	assert.Equal(t, ParallelRuleType, rule.RuleType())
+	assert.Equal(t, "parallel", rule.RuleType().String())
+	assert.NoError(t, rule.AddChildrenE(NewChainRule(), NewChainRule()))
+}
+
+func TestParallelRule_RunsChildrenConcurrently(t *testing.T) {
+	var ready sync.WaitGroup
+	ready.Add(2)
+	var child = func(name string) *BaseRule[ChainRule] {
+		return NewChainRule().WithName(name).OnExecute(func(ctx Context) {
+			ready.Done()
+			ready.Wait()
+			ctx.GetRuleContext().Set(name, true)
+		})
+	}
+
+	ruleContext := NewRuleContext()
+	var done = make(chan error)
+	go func() {
+		done <- ChainRuleRunner(ruleContext, NewParallelRule[ChainRule]().AddChildren(child("a"), child("b")))
+	}()
+
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("children did not run concurrently")
+	}
+	assert.Equal(t, true, ruleContext.Get("a"))
+	assert.Equal(t, true, ruleContext.Get("b"))
+}
+
+func TestParallelRule_MergesInOrder(t *testing.T) {
+	var a = NewChainRule().WithName("a").OnExecute(func(ctx Context) {
+		time.Sleep(10 * time.Millisecond)
+		ctx.GetRuleContext().Set("winner", "a")
+		ctx.GetRuleContext().Delete("stale")
+		ctx.GetRuleContext().SetDecision("a", 1)
+	})
+	var b = NewChainRule().WithName("b").OnExecute(func(ctx Context) {
+		assert.Nil(t, ctx.GetRuleContext().Get("winner"))
+		ctx.GetRuleContext().Set("winner", "b")
+		ctx.GetRuleContext().AddReason("b")
+	})
+
+	ruleContext := NewRuleContext(WithHistory())
+	ruleContext.Set("stale", true)
+	ruleContext.Set("kept", true)
+	assert.NoError(t, ParallelRuleRunner(ruleContext, a, b))
+
+	assert.Equal(t, map[string]interface{}{"winner": "b", "kept": true}, ruleContext.Values())
+	assert.Equal(t, b, ruleContext.Provenance()["winner"])
+	assert.Equal(t, 1, len(ruleContext.Decisions()))
+	assert.Equal(t, []string{"b"}, []string{ruleContext.Reasons()[0].Code})
+	assert.Equal(t, 2, len(ruleContext.Evaluations()))
+	assert.Equal(t, 5, len(ruleContext.History()))
+	assert.Same(t, ruleContext, a.GetRuleContext())
+}
+
+func TestParallelRule_MergesWritesOfUnchangedValues(t *testing.T) {
+	var a = NewChainRule().WithName("a").OnExecute(func(ctx Context) { ctx.GetRuleContext().Set("status", "new") })
+	var b = NewChainRule().WithName("b").OnExecute(func(ctx Context) { ctx.GetRuleContext().Set("status", "old") })
+
+	ruleContext := NewRuleContext()
+	ruleContext.Set("status", "old")
+	assert.NoError(t, ParallelRuleRunner(ruleContext, a, b))
+
+	assert.Equal(t, "old", ruleContext.Get("status"))
+	assert.Equal(t, b, ruleContext.Provenance()["status"])
+}
+
+func TestParallelRule_SharesBudget(t *testing.T) {
+	var fired sync.Map
+	var child = func(name string) *BaseRule[ChainRule] {
+		return NewChainRule().WithName(name).Cost(1).Optional().
+			OnExecute(func(Context) { fired.Store(name, true) })
+	}
+
+	ruleContext := NewRuleContext(WithBudget(2))
+	assert.NoError(t, ChainRuleRunner(ruleContext,
+		NewParallelRule[ChainRule]().Cost(1).AddChildren(child("a"), child("b"), child("c"))))
+
+	var count int
+	fired.Range(func(any, any) bool { count++; return true })
+	assert.Equal(t, 1, count)
+	assert.Equal(t, 2.0, ruleContext.Spent())
+	assert.Len(t, ruleContext.Skipped(), 2)
+}
+
+func TestParallelRule_FirstErrorCancels(t *testing.T) {
+	var started = make(chan struct{})
+	var failing = NewChainRule().WithName("failing").OnInit(func() error {
+		<-started
+		return errors.New("boom")
+	})
+	var slow = NewChainRule().WithName("slow").OnExecute(func(ctx Context) {
+		close(started)
+		select {
+		case <-ctx.GetRuleContext().GoContext().Done():
+			ctx.GetRuleContext().Set("canceled", true)
+		case <-time.After(time.Second):
+		}
+	})
+
+	ruleContext := NewRuleContext()
+	var err = ChainRuleRunner(ruleContext, NewParallelRule[ChainRule]().AddChildren(slow, failing))
+
+	assert.EqualError(t, err, `rule "failing" in init: boom`)
+	assert.Equal(t, true, ruleContext.Get("canceled"))
+}
+
+func TestParallelRule_CanceledRun(t *testing.T) {
+	var ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+
+	ruleContext := NewRuleContext(WithGoContext(ctx))
+	var err = ParallelRuleRunner(ruleContext, NewChainRule().WithName("skipped"))
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.EqualError(t, err, `rule "skipped": context canceled`)
+}
+
+func TestParallelRule_BestFirstStopsSiblings(t *testing.T) {
+	ruleContext := NewRuleContext()
+	assert.NoError(t, BestFirstRuleRunner(ruleContext,
+		NewParallelRule[BestFirstRule]().AddChildren(
+			NewBestFirstRule().OnExecute(func(ctx Context) { ctx.GetRuleContext().Set("child", true) }),
+		),
+		NewBestFirstRule().OnExecute(func(ctx Context) { ctx.GetRuleContext().Set("sibling", true) }),
+	))
+
+	assert.Equal(t, true, ruleContext.Get("child"))
+	assert.Nil(t, ruleContext.Get("sibling"))
+}
+
+func TestParallelRule_DebuggerRunsInOrder(t *testing.T) {
+	var paused []string
+	debugger := NewDebugger(func(p Pause) Command {
+		paused = append(paused, p.Rule.(Named).GetName())
+		return Step
+	})
+
+	ruleContext := NewRuleContext(WithDebugger(debugger))
+	assert.NoError(t, ParallelRuleRunner(ruleContext,
+		NewChainRule().WithName("a"), NewChainRule().WithName("b"), NewChainRule().WithName("c")))
+
+	assert.Equal(t, []string{"a", "b", "c"}, paused)
+}
+
+func TestParallelRule_Panics(t *testing.T) {
+	assert.PanicsWithValue(t, "boom", func() {
+		ParallelRuleRunner(NewRuleContext(), NewChainRule().OnExecute(func(Context) { panic("boom") }))
+	})
+}
@@ -0,0 +1,72 @@
+package rule
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Path returns the address of the rule in its tree: the segments of its
+// ancestors and itself joined with "/". A segment is the rule name, or its
+// position among its siblings, such as "[1]", for unnamed rules. The segment
+// of an unnamed root is empty.
+//
+// The rules of an else branch, see WithElse, are under an "else" segment.
+//
+// Paths of named rules don't change when siblings are added or removed, so
+// naming rules with WithName gives the most stable paths.
+func (r *BaseRule[T]) Path() string {
+	if r.parent == nil {
+		return r.name
+	}
+	for i, sibling := range r.parent.elseChildren {
+		if sibling == r {
+			return pathJoin(r.parent.Path()+"/else", r, i)
+		}
+	}
+	var i = 0
+	for j, sibling := range r.parent.children {
+		if sibling == r {
+			i = j
+			break
+		}
+	}
+	return pathJoin(r.parent.Path(), r, i)
+}
+
+// FindPath returns the rule with the given path among the roots and their
+// descendants, or nil when there is none.
+func FindPath[T any](path string, roots ...*BaseRule[T]) *BaseRule[T] {
+	var segments = strings.Split(path, "/")
+	var candidates = roots
+	var found *BaseRule[T]
+	for depth, segment := range segments {
+		var parent = found
+		found = nil
+		for i, r := range candidates {
+			if pathSegment(r, i) == segment || depth == 0 && r.name == segment {
+				found = r
+				break
+			}
+		}
+		if found == nil && parent != nil && segment == "else" {
+			found, candidates = parent, parent.elseChildren
+			continue
+		}
+		if found == nil {
+			return nil
+		}
+		candidates = found.children
+	}
+	return found
+}
+
+func pathSegment[T any](r *BaseRule[T], i int) string {
+	if r.name == "" {
+		return fmt.Sprintf("[%d]", i)
+	}
+	return r.name
+}
+
+func pathJoin[T any](parent string, r *BaseRule[T], i int) string {
+	return parent + "/" + pathSegment(r, i)
+}
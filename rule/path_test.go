@@ -0,0 +1,23 @@
+package rule
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBaseRule_Path(t *testing.T) {
+	score := NewBestFirstRule().WithName("score")
+	unnamed := NewBestFirstRule().AddChildren(score)
+	root := NewBestFirstRule().WithName("root").AddChildren(NewBestFirstRule().WithName("first"), unnamed)
+
+	assert.Equal(t, "root", root.Path())
+	assert.Equal(t, "root/[1]", unnamed.Path())
+	assert.Equal(t, "root/[1]/score", score.Path())
+	assert.Equal(t, "/[0]", NewBestFirstRule().AddChildren(NewBestFirstRule()).GetChildren()[0].Path())
+
+	assert.Equal(t, score, FindPath("root/[1]/score", root))
+	assert.Equal(t, unnamed, FindPath(unnamed.Path(), NewBestFirstRule().WithName("other"), root))
+	assert.Nil(t, FindPath("root/missing", root))
+	assert.Nil(t, FindPath("other", root))
+}
@@ -0,0 +1,55 @@
+package rule
+
+import (
+	"context"
+	"fmt"
+)
+
+// Policy evaluates a boolean query against an input document.
+// Its shape matches an embedded OPA prepared query, so a Rego policy can be
+// wrapped with a few lines:
+//
+//	rule.PolicyFunc(func(ctx context.Context, input map[string]interface{}) (bool, error) {
+//		rs, err := query.Eval(ctx, rego.EvalInput(input))
+//		if err != nil {
+//			return false, err
+//		}
+//		return rs.Allowed(), nil
+//	})
+type Policy interface {
+	Eval(ctx context.Context, input map[string]interface{}) (bool, error)
+}
+
+// PolicyFunc adapts an ordinary function to the Policy interface.
+type PolicyFunc func(ctx context.Context, input map[string]interface{}) (bool, error)
+
+// Eval calls f(ctx, input).
+func (f PolicyFunc) Eval(ctx context.Context, input map[string]interface{}) (bool, error) {
+	return f(ctx, input)
+}
+
+// EvalPolicy returns an OnEval callback that delegates to the given policy.
+// The values stored in the RuleContext are passed as the policy input, and its
+// GoContext as the context of the query.
+// A policy error evaluates to false; use EvalPolicyE to stop the run instead.
+func EvalPolicy(p Policy) func(Context) bool {
+	var eval = EvalPolicyE(p)
+	return func(ctx Context) bool {
+		var allowed, err = eval(ctx)
+		return err == nil && allowed
+	}
+}
+
+// EvalPolicyE is like EvalPolicy but returns policy errors, for
+// OnEvalWithError, so a failing policy stops the run instead of evaluating to
+// false.
+func EvalPolicyE(p Policy) func(Context) (bool, error) {
+	return func(ctx Context) (bool, error) {
+		var rc = ctx.GetRuleContext()
+		allowed, err := p.Eval(rc.GoContext(), rc.Values())
+		if err != nil {
+			return false, fmt.Errorf("policy: %w", err)
+		}
+		return allowed, nil
+	}
+}
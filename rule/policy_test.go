@@ -0,0 +1,68 @@
+package rule
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEvalPolicy_PassesContextAsInput(t *testing.T) {
+	var policy = PolicyFunc(func(ctx context.Context, input map[string]interface{}) (bool, error) {
+		return input["role"] == "admin", nil
+	})
+
+	rule := NewChainRule()
+	rule.OnEval(EvalPolicy(policy)).OnExecute(func(ctx Context) {
+		ctx.GetRuleContext().Set("allowed", true)
+	})
+
+	ruleContext := NewRuleContext()
+	ruleContext.Set("role", "admin")
+
+	ChainRuleRunner(ruleContext, rule)
+
+	assert.True(t, ruleContext.Get("allowed").(bool))
+}
+
+func TestEvalPolicy_ShouldEvalFalseOnError(t *testing.T) {
+	var policy = PolicyFunc(func(ctx context.Context, input map[string]interface{}) (bool, error) {
+		return true, errors.New("policy failure")
+	})
+
+	rule := NewChainRule()
+	rule.SetRuleContext(NewRuleContext())
+
+	assert.False(t, EvalPolicy(policy)(rule))
+}
+
+func TestEvalPolicyE(t *testing.T) {
+	var policy = PolicyFunc(func(ctx context.Context, input map[string]interface{}) (bool, error) {
+		if input["role"] == nil {
+			return false, errors.New("undefined role")
+		}
+		return input["role"] == "admin", nil
+	})
+	var rule = NewChainRule().WithName("admin").OnEvalWithError(EvalPolicyE(policy))
+
+	ruleContext := NewRuleContext()
+	ruleContext.Set("role", "admin")
+	assert.NoError(t, ChainRuleRunner(ruleContext, rule))
+
+	assert.EqualError(t, ChainRuleRunner(NewRuleContext(), rule), `rule "admin" in eval: policy: undefined role`)
+}
+
+func TestEvalPolicy_PassesGoContext(t *testing.T) {
+	type tenantKey struct{}
+	var policy = PolicyFunc(func(ctx context.Context, input map[string]interface{}) (bool, error) {
+		return ctx.Value(tenantKey{}) == "acme", nil
+	})
+
+	rule := NewChainRule()
+	rule.SetRuleContext(NewRuleContext(WithGoContext(context.WithValue(context.Background(), tenantKey{}, "acme"))))
+	assert.True(t, EvalPolicy(policy)(rule))
+
+	rule.SetRuleContext(NewRuleContext())
+	assert.False(t, EvalPolicy(policy)(rule))
+}
@@ -0,0 +1,39 @@
+package rule
+
+import "sort"
+
+// WithPriority sets the salience of the rule among its best-first siblings:
+// siblings run from the highest priority to the lowest, and in the order they
+// were added for equal priorities. Rules default to priority 0.
+func (r *BaseRule[T]) WithPriority(priority int) *BaseRule[T] {
+	r.priority = priority
+	if r.parent != nil {
+		r.parent.index = nil
+	}
+	return r
+}
+
+// Priority returns the priority of the rule.
+func (r *BaseRule[T]) Priority() int {
+	return r.priority
+}
+
+// byPriority returns the rules ordered by decreasing priority. The rules are
+// returned as is when they are already in order.
+func byPriority[T any](rules []*BaseRule[T]) []*BaseRule[T] {
+	var less = func(a, b *BaseRule[T]) bool { return a.priority > b.priority }
+	var sorted = true
+	for i := 1; i < len(rules); i++ {
+		if less(rules[i], rules[i-1]) {
+			sorted = false
+			break
+		}
+	}
+	if sorted {
+		return rules
+	}
+
+	var ordered = append([]*BaseRule[T](nil), rules...)
+	sort.SliceStable(ordered, func(i, j int) bool { return less(ordered[i], ordered[j]) })
+	return ordered
+}
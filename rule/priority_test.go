@@ -0,0 +1,56 @@
+package rule
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func prioritized(name string, priority int) *BaseRule[BestFirstRule] {
+	return NewBestFirstRule().WithName(name).WithPriority(priority).
+		OnExecute(func(ctx Context) { ctx.GetRuleContext().Set("picked", name) })
+}
+
+func TestWithPriority(t *testing.T) {
+	ruleContext := NewRuleContext()
+	assert.NoError(t, BestFirstRuleRunner(ruleContext,
+		prioritized("default", 0), prioritized("high", 10), prioritized("higher", 10), prioritized("low", -1)))
+	assert.Equal(t, "high", ruleContext.Get("picked"))
+
+	var root = NewBestFirstRule().AddChildren(prioritized("low", -1), prioritized("default", 0))
+	assert.NoError(t, BestFirstRuleRunner(ruleContext, root))
+	assert.Equal(t, "default", ruleContext.Get("picked"))
+	assert.Equal(t, -1, root.GetChildren()[0].Priority())
+}
+
+func TestWithPriority_Evaluation(t *testing.T) {
+	ruleContext := NewRuleContext(WithHistory())
+	var rules = []*BaseRule[BestFirstRule]{
+		prioritized("a", 0).OnEval(func(Context) bool { return false }),
+		prioritized("b", 1).OnEval(func(Context) bool { return false }),
+		prioritized("c", 2).OnEval(func(Context) bool { return false }),
+	}
+	assert.NoError(t, BestFirstRuleRunner(ruleContext, rules...))
+
+	var evaluated []string
+	for _, e := range ruleContext.Evaluations() {
+		evaluated = append(evaluated, e.Rule.(Named).GetName())
+	}
+	assert.Equal(t, []string{"c", "b", "a"}, evaluated)
+	assert.Equal(t, "a", rules[0].GetName())
+}
+
+func TestWithPriority_DispatchIndex(t *testing.T) {
+	var low = prioritized("low", 0).WhenKeyIn("tier", "gold")
+	var high = prioritized("high", 0).WhenKeyIn("tier", "gold", "silver")
+	var root = NewBestFirstRule().WithDispatchIndex().AddChildren(low, high)
+
+	ruleContext := NewRuleContext()
+	ruleContext.Set("tier", "gold")
+	assert.NoError(t, BestFirstRuleRunner(ruleContext, root))
+	assert.Equal(t, "low", ruleContext.Get("picked"))
+
+	high.WithPriority(5)
+	assert.NoError(t, BestFirstRuleRunner(ruleContext, root))
+	assert.Equal(t, "high", ruleContext.Get("picked"))
+}
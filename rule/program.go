@@ -0,0 +1,133 @@
+package rule
+
+import "sync"
+
+// Program runs a rule set within a RuleContext, as the runners do.
+type Program func(*RuleContext) error
+
+// Clone returns a deep copy of the rule and its descendants, sharing their
+// hooks. The copy is a root, detached from the parent of the rule, and has
+// its own RuleContext, so it can run concurrently with the original.
+func (r *BaseRule[T]) Clone() *BaseRule[T] {
+	var clone = *r
+	clone.origin = r.original()
+	clone.parent = nil
+	clone.context = NewRuleContext()
+	clone.index = nil
+	if r.hookNames != nil {
+		clone.hookNames = make(map[Phase]string, len(r.hookNames))
+		for phase, name := range r.hookNames {
+			clone.hookNames[phase] = name
+		}
+	}
+	clone.children = make([]*BaseRule[T], len(r.children))
+	for i, child := range r.children {
+		clone.children[i] = child.Clone()
+		clone.children[i].parent = &clone
+	}
+	if r.elseChildren != nil {
+		clone.elseChildren = make([]*BaseRule[T], len(r.elseChildren))
+		for i, child := range r.elseChildren {
+			clone.elseChildren[i] = child.Clone()
+			clone.elseChildren[i].parent = &clone
+		}
+	}
+	return &clone
+}
+
+// NewProgram returns a Program running the rules with the runner of their
+// type. Runners set the context of the rules they fire, so a tree must not be
+// run by several goroutines at once; the Program instead runs a private copy
+// of the tree, made with Clone and reused across runs, and is safe for
+// concurrent use.
+func NewProgram[T any](rules ...*BaseRule[T]) Program {
+	if len(rules) == 0 {
+		return func(rc *RuleContext) error { return RuleRunner[T](ruleTypeOf[T](), rc) }
+	}
+
+	var ruleType = rules[0].ruleType
+	var pool = sync.Pool{New: func() interface{} { return cloneAll(rules) }}
+	return func(rc *RuleContext) error {
+		var clones = pool.Get().([]*BaseRule[T])
+		defer pool.Put(clones)
+		return RuleRunner(ruleType, rc, clones...)
+	}
+}
+
+// NewWarmProgram returns a Program like NewProgram, whose copies of the tree
+// are built upfront: size copies are kept ready, so runs don't pay for
+// cloning large trees, even after a garbage collection, which empties the
+// pool of NewProgram. Runs beyond size at once use extra copies, which are
+// dropped afterwards.
+func NewWarmProgram[T any](size int, rules ...*BaseRule[T]) Program {
+	if len(rules) == 0 {
+		return func(rc *RuleContext) error { return RuleRunner[T](ruleTypeOf[T](), rc) }
+	}
+
+	var ruleType = rules[0].ruleType
+	var warm = make(chan []*BaseRule[T], size)
+	for i := 0; i < size; i++ {
+		warm <- cloneAll(rules)
+	}
+	return func(rc *RuleContext) error {
+		var clones []*BaseRule[T]
+		select {
+		case clones = <-warm:
+		default:
+			clones = cloneAll(rules)
+		}
+		defer func() {
+			select {
+			case warm <- clones:
+			default:
+			}
+		}()
+		return RuleRunner(ruleType, rc, clones...)
+	}
+}
+
+// original returns the rule the rule was cloned from, through any number of
+// copies, or the rule itself.
+func (r *BaseRule[T]) original() *BaseRule[T] {
+	if r.origin != nil {
+		return r.origin
+	}
+	return r
+}
+
+func cloneAll[T any](rules []*BaseRule[T]) []*BaseRule[T] {
+	var clones = make([]*BaseRule[T], len(rules))
+	for i, r := range rules {
+		clones[i] = r.Clone()
+	}
+	return clones
+}
+
+// ExecuteConcurrent runs the program once for each context, with at most
+// parallelism runs at a time, and returns the error of each run at the index
+// of its context. The program must be safe for concurrent use, as the
+// programs of NewProgram are, and each context must only be used by one run.
+func (p Program) ExecuteConcurrent(ctxs []*RuleContext, parallelism int) []error {
+	if parallelism < 1 {
+		parallelism = 1
+	}
+
+	var errs = make([]error, len(ctxs))
+	var next = make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < parallelism && w < len(ctxs); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				errs[i] = p(ctxs[i])
+			}
+		}()
+	}
+	for i := range ctxs {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+	return errs
+}
@@ -0,0 +1,142 @@
+package rule
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func programRules() []*BaseRule[BestFirstRule] {
+	var small = NewBestFirstRule().WithName("small").
+		OnEval(func(ctx Context) bool { return ctx.GetRuleContext().Get("amount").(int) < 100 }).
+		OnExecute(func(ctx Context) { ctx.GetRuleContext().Set("status", "approved") })
+	var large = NewBestFirstRule().WithName("large").
+		OnExecute(func(ctx Context) { ctx.GetRuleContext().Set("status", "review") }).
+		AddChildren(NewBestFirstRule().WithName("notify").
+			OnExecute(func(ctx Context) { ctx.GetRuleContext().Set("notified", true) }))
+	return []*BaseRule[BestFirstRule]{small, large}
+}
+
+func TestBaseRule_Clone(t *testing.T) {
+	var root = NewBestFirstRule().WithName("root").AddChildren(programRules()...)
+	var clone = root.Clone()
+
+	assert.Empty(t, DiffRules([]*BaseRule[BestFirstRule]{root}, []*BaseRule[BestFirstRule]{clone}))
+	assert.NotSame(t, root.GetChildren()[1], clone.GetChildren()[1])
+	assert.Equal(t, clone, clone.GetChildren()[1].Parent())
+	assert.Equal(t, "root/large/notify", clone.GetChildren()[1].GetChildren()[0].Path())
+	assert.Nil(t, root.GetChildren()[0].Clone().Parent())
+
+	rc := NewRuleContext()
+	rc.Set("amount", 500)
+	assert.NoError(t, BestFirstRuleRunner(rc, clone))
+	assert.Equal(t, "review", rc.Get("status"))
+	assert.NotSame(t, rc, root.GetChildren()[1].GetRuleContext())
+}
+
+func TestProgram_ExecuteConcurrent(t *testing.T) {
+	var program = NewProgram(programRules()...)
+	var ctxs = make([]*RuleContext, 200)
+	for i := range ctxs {
+		ctxs[i] = NewRuleContext()
+		ctxs[i].Set("amount", i)
+	}
+
+	var errs = program.ExecuteConcurrent(ctxs, 8)
+
+	assert.Equal(t, len(ctxs), len(errs))
+	for i, rc := range ctxs {
+		assert.NoError(t, errs[i])
+		if i < 100 {
+			assert.Equal(t, "approved", rc.Get("status"), fmt.Sprint(i))
+		} else {
+			assert.Equal(t, "review", rc.Get("status"), fmt.Sprint(i))
+			assert.Equal(t, true, rc.Get("notified"))
+		}
+	}
+}
+
+func TestProgram_ExecuteConcurrentErrors(t *testing.T) {
+	var cause = errors.New("boom")
+	var program = Program(func(rc *RuleContext) error {
+		if rc.Get("fail") == true {
+			return cause
+		}
+		return nil
+	})
+	var failing = NewRuleContext()
+	failing.Set("fail", true)
+
+	assert.Equal(t, []error{nil, cause}, program.ExecuteConcurrent([]*RuleContext{NewRuleContext(), failing}, 0))
+	assert.Empty(t, program.ExecuteConcurrent(nil, 4))
+	assert.NoError(t, NewProgram[ChainRule]()(NewRuleContext()))
+}
+
+func BenchmarkProgram_ExecuteConcurrent(b *testing.B) {
+	var program = NewProgram(programRules()...)
+	var ctxs = make([]*RuleContext, 1000)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		for j := range ctxs {
+			ctxs[j] = NewRuleContext()
+			ctxs[j].Set("amount", j)
+		}
+		program.ExecuteConcurrent(ctxs, 8)
+	}
+}
+
+func TestNewWarmProgram(t *testing.T) {
+	var program = NewWarmProgram(2, programRules()...)
+	var ctxs = make([]*RuleContext, 50)
+	for i := range ctxs {
+		ctxs[i] = NewRuleContext()
+		ctxs[i].Set("amount", i*10)
+	}
+
+	for _, err := range program.ExecuteConcurrent(ctxs, 4) {
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, "approved", ctxs[9].Get("status"))
+	assert.Equal(t, "review", ctxs[10].Get("status"))
+	assert.Equal(t, true, ctxs[10].Get("notified"))
+
+	assert.NoError(t, NewWarmProgram[BestFirstRule](1)(NewRuleContext()))
+}
+
+// largeTree builds a best-first tree of width^depth leaves, of which the run
+// only visits the first path.
+func largeTree(width, depth int) *BaseRule[BestFirstRule] {
+	var root = NewBestFirstRule()
+	if depth == 0 {
+		return root.OnExecute(func(ctx Context) { ctx.GetRuleContext().Set("leaf", true) })
+	}
+	for i := 0; i < width; i++ {
+		root.AddChildren(largeTree(width, depth-1))
+	}
+	return root
+}
+
+func BenchmarkProgram_LargeTree(b *testing.B) {
+	var tree = largeTree(8, 4)
+	var run = func(b *testing.B, program Program) {
+		b.ReportAllocs()
+		b.ResetTimer()
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				program(NewRuleContext())
+			}
+		})
+	}
+
+	b.Run("clone per run", func(b *testing.B) {
+		run(b, func(rc *RuleContext) error { return BestFirstRuleRunner(rc, tree.Clone()) })
+	})
+	b.Run("pooled", func(b *testing.B) {
+		run(b, NewProgram(tree))
+	})
+	b.Run("warm", func(b *testing.B) {
+		run(b, NewWarmProgram(16, tree))
+	})
+}
@@ -0,0 +1,58 @@
+package rule
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProvenance_RecordsLastWriter(t *testing.T) {
+	rule := NewChainRule().WithName("score")
+	rule2 := NewChainRule().WithName("adjust")
+
+	rule.OnExecute(func(ctx Context) {
+		ctx.GetRuleContext().Set("score", 10)
+		ctx.GetRuleContext().Set("reason", "base")
+	}).AddChildren(rule2.OnExecute(func(ctx Context) {
+		ctx.GetRuleContext().Set("score", 20)
+	}))
+
+	ruleContext := NewRuleContext()
+	ruleContext.Set("input", true)
+
+	ChainRuleRunner(ruleContext, rule)
+
+	provenance := ruleContext.Provenance()
+	assert.Equal(t, 2, len(provenance))
+	assert.Equal(t, "adjust", provenance["score"].(Named).GetName())
+	assert.Equal(t, "score", provenance["reason"].(Named).GetName())
+	assert.NotContains(t, provenance, "input")
+}
+
+func TestProvenance_NotRecordedAfterRun(t *testing.T) {
+	rule := NewBestFirstRule()
+	ruleContext := NewRuleContext()
+
+	BestFirstRuleRunner(ruleContext, rule)
+	ruleContext.Set("after", true)
+
+	assert.Empty(t, ruleContext.Provenance())
+}
+
+type plainContext struct {
+	rc *RuleContext
+}
+
+func (c *plainContext) GetRuleContext() *RuleContext   { return c.rc }
+func (c *plainContext) SetRuleContext(rc *RuleContext) { c.rc = rc }
+
+func TestNamed(t *testing.T) {
+	var ctx Context = &plainContext{rc: NewRuleContext()}
+	_, ok := ctx.(Named)
+	assert.False(t, ok)
+	assert.Equal(t, "unnamed rule", ruleLabel(ctx))
+
+	ctx = NewChainRule().WithName("named")
+	assert.Equal(t, "named", ctx.(Named).GetName())
+	assert.Equal(t, `rule "named"`, ruleLabel(ctx))
+}
@@ -0,0 +1,200 @@
+package rule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Dimension is the kind of unit of a Quantity.
+type Dimension int
+
+const (
+	Dimensionless Dimension = iota
+	Bytes
+	Duration
+	Currency
+)
+
+// Quantity is a number with a unit. Byte sizes are stored in bytes and
+// durations in seconds; currency amounts keep their currency code.
+type Quantity struct {
+	Value     float64
+	Dimension Dimension
+	Currency  string
+}
+
+var byteUnits = map[string]float64{
+	"B":  1,
+	"KB": 1e3, "MB": 1e6, "GB": 1e9, "TB": 1e12,
+	"KiB": 1 << 10, "MiB": 1 << 20, "GiB": 1 << 30, "TiB": 1 << 40,
+}
+
+var durationUnits = map[string]float64{
+	"ns": 1e-9, "us": 1e-6, "µs": 1e-6, "ms": 1e-3,
+	"s": 1, "m": 60, "h": 3600, "d": 86400,
+}
+
+// ParseQuantity parses a number followed by an optional unit, such as "10MB",
+// "1.5GiB", "1e3MB", "5m", "250ms", "2d" or "100 USD". Currency units are
+// three letter upper case codes.
+func ParseQuantity(s string) (Quantity, error) {
+	var text = strings.TrimSpace(s)
+	var i = numberPrefix(text)
+
+	value, err := strconv.ParseFloat(text[:i], 64)
+	if err != nil {
+		return Quantity{}, fmt.Errorf("quantity %q: invalid number", s)
+	}
+
+	var unit = strings.TrimSpace(text[i:])
+	switch {
+	case unit == "":
+		return Quantity{Value: value}, nil
+	case byteUnits[unit] != 0:
+		return Quantity{Value: value * byteUnits[unit], Dimension: Bytes}, nil
+	case durationUnits[unit] != 0:
+		return Quantity{Value: value * durationUnits[unit], Dimension: Duration}, nil
+	case isCurrencyCode(unit):
+		return Quantity{Value: value, Dimension: Currency, Currency: unit}, nil
+	}
+	return Quantity{}, fmt.Errorf("quantity %q: unknown unit %q", s, unit)
+}
+
+// numberPrefix returns the length of the decimal floating point number text
+// starts with: an optional sign, digits with an optional fraction, and an
+// optional exponent, which is only taken when digits follow it, so that "5EUR"
+// is 5 euros.
+func numberPrefix(text string) int {
+	var i = 0
+	var digits = func() {
+		for i < len(text) && text[i] >= '0' && text[i] <= '9' {
+			i++
+		}
+	}
+	if i < len(text) && (text[i] == '+' || text[i] == '-') {
+		i++
+	}
+	digits()
+	if i < len(text) && text[i] == '.' {
+		i++
+		digits()
+	}
+	if i < len(text) && (text[i] == 'e' || text[i] == 'E') {
+		var j = i + 1
+		if j < len(text) && (text[j] == '+' || text[j] == '-') {
+			j++
+		}
+		if j < len(text) && text[j] >= '0' && text[j] <= '9' {
+			i = j
+			digits()
+		}
+	}
+	return i
+}
+
+func isCurrencyCode(unit string) bool {
+	if len(unit) != 3 {
+		return false
+	}
+	for _, r := range unit {
+		if r < 'A' || r > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+// Rates gives the value of one unit of each currency in a common reference
+// currency, e.g. Rates{"USD": 1, "EUR": 1.08, "BRL": 0.2}.
+type Rates map[string]float64
+
+// Compare compares q to other and returns -1, 0 or +1. Currency amounts are
+// converted with rates. The boolean result is false when the quantities have
+// different dimensions or a currency rate is missing.
+func (q Quantity) Compare(other Quantity, rates Rates) (int, bool) {
+	if q.Dimension != other.Dimension {
+		return 0, false
+	}
+
+	var a, b = q.Value, other.Value
+	if q.Dimension == Currency && q.Currency != other.Currency {
+		ra, okA := rates[q.Currency]
+		rb, okB := rates[other.Currency]
+		if !okA || !okB {
+			return 0, false
+		}
+		a, b = a*ra, b*rb
+	}
+
+	switch {
+	case a < b:
+		return -1, true
+	case a > b:
+		return 1, true
+	}
+	return 0, true
+}
+
+// CompareQuantity returns an OnEval predicate comparing the value stored under
+// key to a threshold such as "10MB" or "5m" with one of the operators
+// ">", ">=", "<", "<=", "==" or "!=".
+//
+// The value may be a Quantity, a string parsed with ParseQuantity, a
+// time.Duration, or a plain number, or string without unit, expressed in the
+// base unit of the threshold: bytes for byte sizes, seconds for durations and units of the
+// threshold currency for amounts, so 20e6 is more than "10MB". The
+// threshold is parsed once, when the predicate is created; an invalid
+// threshold or operator panics. Incomparable values evaluate to false.
+func CompareQuantity(key string, op string, threshold string, rates Rates) func(Context) bool {
+	limit, err := ParseQuantity(threshold)
+	if err != nil {
+		panic(err)
+	}
+	accept, ok := comparisons[op]
+	if !ok {
+		panic(fmt.Sprintf("unknown comparison operator %q", op))
+	}
+
+	return func(ctx Context) bool {
+		q, ok := quantityOf(ctx.GetRuleContext().Get(key), limit)
+		if !ok {
+			return false
+		}
+		c, ok := q.Compare(limit, rates)
+		return ok && accept(c)
+	}
+}
+
+var comparisons = map[string]func(int) bool{
+	">":  func(c int) bool { return c > 0 },
+	">=": func(c int) bool { return c >= 0 },
+	"<":  func(c int) bool { return c < 0 },
+	"<=": func(c int) bool { return c <= 0 },
+	"==": func(c int) bool { return c == 0 },
+	"!=": func(c int) bool { return c != 0 },
+}
+
+func quantityOf(value interface{}, like Quantity) (Quantity, bool) {
+	switch v := value.(type) {
+	case Quantity:
+		return v, true
+	case string:
+		q, err := ParseQuantity(v)
+		if err != nil {
+			return Quantity{}, false
+		}
+		if q.Dimension == Dimensionless {
+			q.Dimension, q.Currency = like.Dimension, like.Currency
+		}
+		return q, true
+	case time.Duration:
+		return Quantity{Value: v.Seconds(), Dimension: Duration}, true
+	}
+
+	if f, ok := toFloat(value); ok {
+		return Quantity{Value: f, Dimension: like.Dimension, Currency: like.Currency}, true
+	}
+	return Quantity{}, false
+}
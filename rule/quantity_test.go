@@ -0,0 +1,96 @@
+package rule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseQuantity(t *testing.T) {
+	for s, want := range map[string]Quantity{
+		"42":      {Value: 42},
+		"10MB":    {Value: 10e6, Dimension: Bytes},
+		"1.5 KiB": {Value: 1536, Dimension: Bytes},
+		"5m":      {Value: 300, Dimension: Duration},
+		"250ms":   {Value: 0.25, Dimension: Duration},
+		"2d":      {Value: 172800, Dimension: Duration},
+		"100 USD": {Value: 100, Dimension: Currency, Currency: "USD"},
+		"-3 EUR":  {Value: -3, Dimension: Currency, Currency: "EUR"},
+		"1e3MB":   {Value: 1e9, Dimension: Bytes},
+		"2.5E-3s": {Value: 0.0025, Dimension: Duration},
+		"5EUR":    {Value: 5, Dimension: Currency, Currency: "EUR"},
+		"+7":      {Value: 7},
+	} {
+		q, err := ParseQuantity(s)
+		assert.NoError(t, err, s)
+		assert.Equal(t, want, q, s)
+	}
+
+	_, err := ParseQuantity("MB")
+	assert.EqualError(t, err, `quantity "MB": invalid number`)
+	_, err = ParseQuantity("10 parsecs")
+	assert.EqualError(t, err, `quantity "10 parsecs": unknown unit "parsecs"`)
+}
+
+func TestQuantity_Compare(t *testing.T) {
+	var rates = Rates{"USD": 1, "EUR": 1.1}
+	var mb, _ = ParseQuantity("1MB")
+	var kb, _ = ParseQuantity("1000KB")
+	var minute, _ = ParseQuantity("1m")
+	var usd, _ = ParseQuantity("100 USD")
+	var eur, _ = ParseQuantity("100 EUR")
+	var brl, _ = ParseQuantity("100 BRL")
+
+	c, ok := mb.Compare(kb, nil)
+	assert.True(t, ok)
+	assert.Equal(t, 0, c)
+
+	_, ok = mb.Compare(minute, nil)
+	assert.False(t, ok)
+
+	c, ok = eur.Compare(usd, rates)
+	assert.True(t, ok)
+	assert.Equal(t, 1, c)
+
+	_, ok = brl.Compare(usd, rates)
+	assert.False(t, ok)
+}
+
+func quantityContext(value interface{}) Context {
+	rule := NewChainRule()
+	rule.GetRuleContext().Set("value", value)
+	return rule
+}
+
+func TestCompareQuantity(t *testing.T) {
+	var large = CompareQuantity("value", ">", "10MB", nil)
+	assert.True(t, large(quantityContext("11MB")))
+	assert.True(t, large(quantityContext(20e6)))
+	assert.True(t, large(quantityContext("1GiB")))
+	assert.False(t, large(quantityContext("5m")))
+	assert.False(t, large(quantityContext(true)))
+
+	var slow = CompareQuantity("value", ">=", "5m", nil)
+	assert.True(t, slow(quantityContext(5*time.Minute)))
+	assert.False(t, slow(quantityContext(299)))
+	assert.True(t, slow(quantityContext(300)))
+	assert.True(t, slow(quantityContext("300")))
+	assert.False(t, slow(quantityContext("299")))
+
+	var expensive = CompareQuantity("value", ">", "100 USD", Rates{"USD": 1, "EUR": 1.1})
+	assert.True(t, expensive(quantityContext("95 EUR")))
+	assert.False(t, expensive(quantityContext(90)))
+
+	assert.Panics(t, func() { CompareQuantity("value", ">", "ten", nil) })
+	assert.Panics(t, func() { CompareQuantity("value", "=>", "10", nil) })
+}
+
+func FuzzParseQuantity(f *testing.F) {
+	for _, s := range []string{"42", "10MB", "1.5 KiB", "250ms", "100 USD", "-3 EUR", "10 parsecs"} {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		ParseQuantity(s)
+	})
+}
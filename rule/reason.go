@@ -0,0 +1,77 @@
+package rule
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Reason is a reason code attached to a run by a rule to explain its decision.
+type Reason struct {
+	Code string
+	Args []interface{}
+	// Rule is the rule that attached the reason, or nil outside of a run.
+	Rule Context
+}
+
+// AddReason attaches a reason code, with optional message arguments, to the run.
+func (rc *RuleContext) AddReason(code string, args ...interface{}) {
+	rc.reasons = append(rc.reasons, Reason{Code: code, Args: args, Rule: rc.current})
+}
+
+// Reasons returns the reasons attached to the run, in order.
+func (rc *RuleContext) Reasons() []Reason {
+	return append([]Reason{}, rc.reasons...)
+}
+
+// Catalog maps reason codes to human-readable messages per locale.
+// Messages are fmt format strings receiving the reason arguments.
+type Catalog struct {
+	fallback string
+	messages map[string]map[string]string
+}
+
+// NewCatalog creates an empty catalog. Messages missing in a requested locale
+// are looked up in the fallback locale.
+func NewCatalog(fallback string) *Catalog {
+	return &Catalog{fallback: fallback, messages: make(map[string]map[string]string)}
+}
+
+// Add registers the message of a reason code in a locale.
+func (c *Catalog) Add(locale string, code string, message string) *Catalog {
+	if c.messages[locale] == nil {
+		c.messages[locale] = make(map[string]string)
+	}
+	c.messages[locale][code] = message
+	return c
+}
+
+// Message returns the message of a reason code in a locale. A regional locale
+// such as "pt-BR" falls back to its language "pt", then to the catalog
+// fallback locale. Unknown codes are returned as is.
+func (c *Catalog) Message(locale string, code string, args ...interface{}) string {
+	for _, l := range []string{locale, baseLocale(locale), c.fallback} {
+		if message, ok := c.messages[l][code]; ok {
+			if len(args) == 0 {
+				return message
+			}
+			return fmt.Sprintf(message, args...)
+		}
+	}
+	return code
+}
+
+// Explain returns the messages of the reasons attached to a run in a locale.
+func (c *Catalog) Explain(rc *RuleContext, locale string) []string {
+	var messages = make([]string, 0, len(rc.reasons))
+	for _, r := range rc.reasons {
+		messages = append(messages, c.Message(locale, r.Code, r.Args...))
+	}
+	return messages
+}
+
+func baseLocale(locale string) string {
+	if i := strings.IndexAny(locale, "-_"); i > 0 {
+		return locale[:i]
+	}
+	return locale
+}
@@ -0,0 +1,48 @@
+package rule
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func reasonCatalog() *Catalog {
+	return NewCatalog("en").
+		Add("en", "amount.high", "Amount above %d").
+		Add("en", "country.blocked", "Country not supported").
+		Add("pt", "amount.high", "Valor acima de %d").
+		Add("pt-BR", "country.blocked", "País não atendido")
+}
+
+func TestAddReason(t *testing.T) {
+	rule := NewChainRule().WithName("limits").OnExecute(func(ctx Context) {
+		ctx.GetRuleContext().AddReason("amount.high", 1000)
+	})
+
+	ruleContext := NewRuleContext()
+	ChainRuleRunner(ruleContext, rule)
+
+	reasons := ruleContext.Reasons()
+	assert.Equal(t, 1, len(reasons))
+	assert.Equal(t, "amount.high", reasons[0].Code)
+	assert.Equal(t, []interface{}{1000}, reasons[0].Args)
+	assert.Equal(t, "limits", reasons[0].Rule.(Named).GetName())
+}
+
+func TestCatalog_Message(t *testing.T) {
+	catalog := reasonCatalog()
+
+	assert.Equal(t, "Valor acima de 10", catalog.Message("pt-BR", "amount.high", 10))
+	assert.Equal(t, "País não atendido", catalog.Message("pt-BR", "country.blocked"))
+	assert.Equal(t, "Country not supported", catalog.Message("pt-PT", "country.blocked"))
+	assert.Equal(t, "Amount above 10", catalog.Message("fr", "amount.high", 10))
+	assert.Equal(t, "unknown.code", catalog.Message("en", "unknown.code"))
+}
+
+func TestCatalog_Explain(t *testing.T) {
+	ruleContext := NewRuleContext()
+	ruleContext.AddReason("amount.high", 500)
+	ruleContext.AddReason("country.blocked")
+
+	assert.Equal(t, []string{"Valor acima de 500", "País não atendido"}, reasonCatalog().Explain(ruleContext, "pt-BR"))
+}
@@ -0,0 +1,51 @@
+package rule
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// KeyTypeError reports a context value that doesn't have the expected type.
+type KeyTypeError struct {
+	Key   string
+	Want  reflect.Type
+	Value interface{}
+}
+
+func (e *KeyTypeError) Error() string {
+	return fmt.Sprintf("key %q is %T, want %v", e.Key, e.Value, e.Want)
+}
+
+// GetOrErr returns the value of the key with type T. It returns a
+// *MissingKeyError when the key is not set, which strict key mode doesn't
+// record again, and a *KeyTypeError when the value has another type.
+func GetOrErr[T any](rc *RuleContext, key string) (T, error) {
+	var zero T
+	var value, ok = rc.read(key)
+	if !ok {
+		return zero, &MissingKeyError{Key: key}
+	}
+	typed, ok := value.(T)
+	if !ok {
+		return zero, &KeyTypeError{Key: key, Want: reflect.TypeOf((*T)(nil)).Elem(), Value: value}
+	}
+	return typed, nil
+}
+
+// GetOrErr returns the value stored under the key, as the GetOrErr function does.
+func (k Key[T]) GetOrErr(rc *RuleContext) (T, error) {
+	return GetOrErr[T](rc, k.name)
+}
+
+// RequireKeys returns nil when all the keys are set, and otherwise a
+// *MissingKeyError for each missing key, joined with errors.Join.
+func (rc *RuleContext) RequireKeys(keys ...string) error {
+	var errs []error
+	for _, key := range keys {
+		if _, ok := rc.lookup(key); !ok {
+			errs = append(errs, &MissingKeyError{Key: key})
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,48 @@
+package rule
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetOrErr(t *testing.T) {
+	rc := NewRuleContext()
+	rc.Set("amount", 10)
+
+	amount, err := GetOrErr[int](rc, "amount")
+	assert.NoError(t, err)
+	assert.Equal(t, 10, amount)
+
+	_, err = GetOrErr[string](rc, "amount")
+	assert.EqualError(t, err, `key "amount" is int, want string`)
+	var typeErr *KeyTypeError
+	assert.ErrorAs(t, err, &typeErr)
+
+	_, err = GetOrErr[int](rc, "missing")
+	assert.EqualError(t, err, `key "missing" is missing`)
+
+	var ks = NewKeySet()
+	var key = DefineKey[int](ks, "amount", "")
+	amount, err = key.GetOrErr(rc)
+	assert.NoError(t, err)
+	assert.Equal(t, 10, amount)
+}
+
+func TestGetOrErr_StrictKeys(t *testing.T) {
+	var handled error
+	var rule = NewChainRule().OnExecute(func(ctx Context) {
+		_, handled = GetOrErr[int](ctx.GetRuleContext(), "missing")
+	})
+
+	assert.NoError(t, ChainRuleRunner(NewRuleContext(WithStrictKeys()), rule))
+	assert.Error(t, handled)
+}
+
+func TestRuleContext_RequireKeys(t *testing.T) {
+	rc := NewRuleContext(WithDefaults(map[string]interface{}{"currency": "EUR"}))
+	rc.Set("amount", 10)
+
+	assert.NoError(t, rc.RequireKeys("amount", "currency"))
+	assert.EqualError(t, rc.RequireKeys("amount", "country", "user"), "key \"country\" is missing\nkey \"user\" is missing")
+}
@@ -0,0 +1,37 @@
+package rule
+
+import "sync/atomic"
+
+// NewRoundRobinRule creates a rule that takes turns among its children: each
+// time its OnEval returns true, it executes and fires the next of its
+// children, going back to the first after the last. It suits equivalent
+// handlers sharing the work, such as several providers or queues.
+//
+// The turn is kept in an atomic counter shared by the copies of the rule made
+// with Clone, so the runs of a Program rotate as a whole, even concurrently.
+//
+// T is the kind of the tree the rule is part of, such as ChainRule or
+// BestFirstRule, and the else rules of the rule run as in such a tree.
+func NewRoundRobinRule[T any]() *BaseRule[T] {
+	return &BaseRule[T]{
+		ruleType:      RoundRobinRuleType,
+		context:       NewRuleContext(),
+		children:      make([]*BaseRule[T], 0),
+		turn:          new(atomic.Uint64),
+		onEval:        func(r Context) bool { return true },
+		onPreExecute:  func(r Context) {},
+		onExecute:     func(r Context) {},
+		onPostExecute: func(r Context) {},
+	}
+}
+
+// rotate fires the child whose turn it is.
+func (r *BaseRule[T]) rotate() error {
+	if len(r.children) == 0 {
+		return nil
+	}
+	var child = r.children[(r.turn.Add(1)-1)%uint64(len(r.children))]
+	child.SetRuleContext(r.GetRuleContext())
+	var _, err = child.fire()
+	return err
+}
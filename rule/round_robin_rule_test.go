@@ -0,0 +1,103 @@
+package rule
+
+import (
+	"bytes"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func providers(calls []atomic.Int64) []*BaseRule[ChainRule] {
+	var rules []*BaseRule[ChainRule]
+	for i := range calls {
+		var i = i
+		rules = append(rules, NewChainRule().OnExecute(func(ctx Context) {
+			calls[i].Add(1)
+			ctx.GetRuleContext().Set("provider", i)
+		}))
+	}
+	return rules
+}
+
+func TestRoundRobinRule(t *testing.T) {
+	var calls = make([]atomic.Int64, 3)
+	var rule = NewRoundRobinRule[ChainRule]().AddChildren(providers(calls)...)
+
+	var picked []interface{}
+	for i := 0; i < 5; i++ {
+		ruleContext := NewRuleContext()
+		assert.NoError(t, ChainRuleRunner(ruleContext, rule))
+		picked = append(picked, ruleContext.Get("provider"))
+	}
+	assert.Equal(t, []interface{}{0, 1, 2, 0, 1}, picked)
+
+	assert.Equal(t, RoundRobinRuleType, rule.RuleType())
+	assert.Equal(t, "round-robin", rule.RuleType().String())
+}
+
+func TestRoundRobinRule_NotMatched(t *testing.T) {
+	var calls = make([]atomic.Int64, 2)
+	var rule = NewRoundRobinRule[ChainRule]().AddChildren(providers(calls)...).
+		OnEval(func(ctx Context) bool { return ctx.GetRuleContext().Get("enabled") == true })
+
+	assert.NoError(t, ChainRuleRunner(NewRuleContext(), rule))
+	ruleContext := NewRuleContext()
+	ruleContext.Set("enabled", true)
+	assert.NoError(t, ChainRuleRunner(ruleContext, rule))
+
+	assert.Equal(t, 0, ruleContext.Get("provider"))
+	assert.NoError(t, ChainRuleRunner(NewRuleContext(), NewRoundRobinRule[ChainRule]()))
+}
+
+func TestRoundRobinRule_BestFirstSiblings(t *testing.T) {
+	var rule = NewRoundRobinRule[BestFirstRule]().AddChildren(
+		NewBestFirstRule().OnExecute(func(ctx Context) { ctx.GetRuleContext().Set("queue", "a") }),
+		NewBestFirstRule().OnExecute(func(ctx Context) { ctx.GetRuleContext().Set("queue", "b") }),
+	)
+	var next = NewBestFirstRule().OnExecute(func(ctx Context) { ctx.GetRuleContext().Set("next", true) })
+
+	ruleContext := NewRuleContext()
+	assert.NoError(t, BestFirstRuleRunner(ruleContext, rule, next))
+	assert.Equal(t, "a", ruleContext.Get("queue"))
+	assert.Nil(t, ruleContext.Get("next"))
+}
+
+func TestRoundRobinRule_Program(t *testing.T) {
+	var calls = make([]atomic.Int64, 3)
+	var program = NewProgram(NewChainRule().AddChildren(
+		NewRoundRobinRule[ChainRule]().AddChildren(providers(calls)...)))
+
+	var ctxs = make([]*RuleContext, 300)
+	for i := range ctxs {
+		ctxs[i] = NewRuleContext()
+	}
+	for _, err := range program.ExecuteConcurrent(ctxs, 8) {
+		assert.NoError(t, err)
+	}
+	for i := range calls {
+		assert.Equal(t, int64(100), calls[i].Load())
+	}
+}
+
+func TestRoundRobinRule_Encode(t *testing.T) {
+	var funcs = NewFuncs().
+		Register("a", func(ctx Context) { ctx.GetRuleContext().Set("queue", "a") }).
+		Register("b", func(ctx Context) { ctx.GetRuleContext().Set("queue", "b") })
+	var rule = NewRoundRobinRule[ChainRule]().AddChildren(
+		NewChainRule().Use(funcs, PhaseExecute, "a"),
+		NewChainRule().Use(funcs, PhaseExecute, "b"),
+	)
+
+	var buf bytes.Buffer
+	assert.NoError(t, EncodeRules(&buf, rule))
+	decoded, err := DecodeRules[ChainRule](&buf, funcs)
+	assert.NoError(t, err)
+	assert.Equal(t, rule.Hash(), decoded[0].Hash())
+
+	for _, want := range []string{"a", "b", "a"} {
+		ruleContext := NewRuleContext()
+		assert.NoError(t, ChainRuleRunner(ruleContext, decoded[0]))
+		assert.Equal(t, want, ruleContext.Get("queue"))
+	}
+}
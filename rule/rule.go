@@ -1,30 +1,205 @@
 package rule
 
-type ruleType int
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// RuleType identifies how a rule runs its children and siblings.
+type RuleType int
 
+// The rule types.
 const (
-	chainRuleType ruleType = iota
-	bestFirstRuleType
+	ChainRuleType RuleType = iota
+	BestFirstRuleType
+	AllMatchRuleType
+	ParallelRuleType
+	LoopRuleType
+	FallbackRuleType
+	VotingRuleType
+	RoundRobinRuleType
+	WeightedRandomRuleType
 )
 
+func (t RuleType) String() string {
+	switch t {
+	case ChainRuleType:
+		return "chain"
+	case BestFirstRuleType:
+		return "best-first"
+	case AllMatchRuleType:
+		return "all-match"
+	case ParallelRuleType:
+		return "parallel"
+	case LoopRuleType:
+		return "loop"
+	case FallbackRuleType:
+		return "fallback"
+	case VotingRuleType:
+		return "voting"
+	case RoundRobinRuleType:
+		return "round-robin"
+	case WeightedRandomRuleType:
+		return "weighted-random"
+	}
+	return fmt.Sprintf("RuleType(%d)", int(t))
+}
+
 // RuleContext represents a context for storing key-value pairs.
 type RuleContext struct {
-	context map[string]interface{}
+	context       map[string]interface{}
+	keySet        *KeySet
+	shared        map[string]bool
+	current       Context
+	provenance    map[string]Context
+	recording     bool
+	history       []HistoryEntry
+	evaluations   []Evaluation
+	reads         []KeyRead
+	reasons       []Reason
+	decisions     []ScoredDecision
+	policy        DecisionPolicy
+	clock         Clock
+	debugger      *Debugger
+	runID         string
+	budget        *float64
+	spent         float64
+	skipped       []Context
+	softDeadline  time.Duration
+	deadline      time.Time
+	degrade       DegradePolicy
+	degraded      bool
+	strict        bool
+	failure       error
+	defaults      map[string]interface{}
+	stats         *KeyStats
+	tracer        *Tracer
+	stacks        bool
+	global        map[string]interface{}
+	goContext     context.Context
+	backtracking  bool
+	slowThreshold time.Duration
+	slowReport    func(SlowHook)
+	chaos         *chaosRun
+	watch         *keyWatch
+	maxFirings    int
+	sequential    bool
+	usage         []RuleDuration
+	nested        []time.Duration
+	rand          func() float64
+	inspect       func(ruleSet)
+	canaryKey     string
+	share         *budgetShare
 }
 
+// ContextOption configures optional behavior of a RuleContext.
+type ContextOption func(*RuleContext)
+
 // NewRuleContext creates a new RuleContext with an initialized map.
-func NewRuleContext() *RuleContext {
-	return &RuleContext{context: make(map[string]interface{})}
+func NewRuleContext(opts ...ContextOption) *RuleContext {
+	var rc = &RuleContext{context: make(map[string]interface{})}
+	for _, opt := range opts {
+		opt(rc)
+	}
+	return rc
 }
 
 // Get retrieves a value from the context by its key.
 func (rc *RuleContext) Get(key string) interface{} {
-	return rc.context[key]
+	var value, ok = rc.read(key)
+	if !ok && rc.strict && rc.current != nil {
+		rc.failure = errors.Join(rc.failure, &MissingKeyError{Key: key})
+	}
+	return value
+}
+
+// read looks a key up, checking and recording the access.
+func (rc *RuleContext) read(key string) (interface{}, bool) {
+	rc.checkKey(key, nil, false)
+	if rc.recording && rc.current != nil {
+		rc.reads = append(rc.reads, KeyRead{Key: key, Rule: rc.current})
+	}
+	if rc.watch != nil {
+		rc.watch.reads[key] = true
+	}
+	if rc.stats != nil {
+		rc.stats.count(key, false)
+	}
+	return rc.lookup(key)
 }
 
 // Set adds or updates a key-value pair in the context.
 func (rc *RuleContext) Set(key string, value interface{}) {
+	if !rc.checkKey(key, value, true) {
+		return
+	}
 	rc.context[key] = value
+	rc.shared = nil
+	if rc.watch != nil {
+		rc.watch.writes[key] = true
+	}
+	if rc.stats != nil {
+		rc.stats.count(key, true)
+	}
+	rc.record(key, value, false)
+	if rc.debugger != nil {
+		rc.debugger.written(rc, key)
+	}
+	if rc.current != nil {
+		if rc.provenance == nil {
+			rc.provenance = make(map[string]Context)
+		}
+		rc.provenance[key] = rc.current
+	}
+}
+
+// Values returns a copy of all the key-value pairs in the context, including
+// the defaults of keys that are not set.
+func (rc *RuleContext) Values() map[string]interface{} {
+	var values = make(map[string]interface{}, len(rc.context)+len(rc.defaults))
+	for k, v := range rc.defaults {
+		values[k] = v
+	}
+	for k, v := range rc.context {
+		values[k] = v
+	}
+	return values
+}
+
+// Delete removes a key from the context.
+func (rc *RuleContext) Delete(key string) {
+	if !rc.checkKey(key, nil, true) {
+		return
+	}
+	delete(rc.context, key)
+	rc.shared = nil
+	if rc.watch != nil {
+		rc.watch.writes[key] = true
+	}
+	if rc.stats != nil {
+		rc.stats.count(key, true)
+	}
+	rc.record(key, nil, true)
+	if rc.debugger != nil {
+		rc.debugger.written(rc, key)
+	}
+	if rc.provenance != nil {
+		delete(rc.provenance, key)
+	}
+}
+
+// Provenance returns, for every key written by a rule during a run, the rule
+// that last wrote it. Keys set from outside a rule are not included.
+func (rc *RuleContext) Provenance() map[string]Context {
+	var provenance = make(map[string]Context, len(rc.provenance))
+	for k, v := range rc.provenance {
+		provenance[k] = v
+	}
+	return provenance
 }
 
 type Context interface {
@@ -32,15 +207,67 @@ type Context interface {
 	SetRuleContext(*RuleContext)
 }
 
+// Named is implemented by the Contexts having a name, such as the rules, whose
+// name is set with WithName. The rules referenced by errors, traces and
+// provenance are Contexts; assert them to Named to get their name.
+type Named interface {
+	GetName() string
+}
+
+// nameOf returns the name of the Context, or "" when it has none.
+func nameOf(r Context) string {
+	if n, ok := r.(Named); ok {
+		return n.GetName()
+	}
+	return ""
+}
+
 // BaseRule represents a generic rule with a context and various lifecycle hooks.
 type BaseRule[T any] struct {
-	ruleType      ruleType
+	name          string
+	ruleType      RuleType
 	context       *RuleContext
+	parent        *BaseRule[T]
 	children      []*BaseRule[T]
 	onEval        func(Context) bool
 	onExecute     func(Context)
+	onExecuteErr  func(Context) error
 	onPreExecute  func(Context)
 	onPostExecute func(Context)
+	dispatch      *dispatchCond
+	indexChildren bool
+	index         *dispatchIndex
+	canary        *float64
+	cost          float64
+	optional      bool
+	init          *ruleInit
+	examples      []Example
+	assert        func(Context) error
+	annotation    bool
+	priority      int
+	onEvalScore   func(Context) float64
+	minScore      float64
+	score         *float64
+	maxIterations int
+	iteration     int
+	onElse        func(Context)
+	elseChildren  []*BaseRule[T]
+	hookNames     map[Phase]string
+	quorum        quorum
+	votes         int
+	machine       *StateMachine
+	states        []string
+	produces      []string
+	switchKey     string
+	turn          *atomic.Uint64
+	weight        *int
+	origin        *BaseRule[T]
+}
+
+type ruleInit struct {
+	once sync.Once
+	f    func() error
+	err  error
 }
 
 // GetRuleContext returns the RuleContext associated with the rule.
@@ -53,43 +280,126 @@ func (r *BaseRule[T]) SetRuleContext(context *RuleContext) {
 	r.context = context
 }
 
+// GetName returns the name of the rule.
+func (r *BaseRule[T]) GetName() string {
+	return r.name
+}
+
+// WithName sets a name identifying the rule in diffs, traces and errors.
+func (r *BaseRule[T]) WithName(name string) *BaseRule[T] {
+	r.name = name
+	return r
+}
+
 func (r *BaseRule[T]) eval() bool {
-	return r.onEval(r)
+	defer r.timeHook(PhaseEval)()
+	if r.onEvalScore != nil {
+		return r.evalScore()
+	}
+	var result = r.inCanary() && r.inState() && r.onEval(r)
+	if rc := r.GetRuleContext(); rc != nil && rc.recording {
+		rc.evaluations = append(rc.evaluations, Evaluation{Rule: r, Result: result})
+	}
+	return result
 }
 
 // OnEval sets the evaluation function for the rule.
 func (r *BaseRule[T]) OnEval(f func(Context) bool) *BaseRule[T] {
 	r.onEval = f
+	r.onEvalScore = nil
+	r.dispatch = nil
+	r.nameHook(PhaseEval, "")
 	return r
 }
 
+// OnEvalWithError sets an evaluation function that can fail, in place of
+// OnEval, such as one querying a database. Its error stops the run as a
+// *RuleError in PhaseEval.
+func (r *BaseRule[T]) OnEvalWithError(f func(Context) (bool, error)) *BaseRule[T] {
+	return r.OnEval(func(ctx Context) bool {
+		var matched, err = f(ctx)
+		if err != nil {
+			var rc = ctx.GetRuleContext()
+			rc.failure = errors.Join(rc.failure, err)
+			return false
+		}
+		return matched
+	})
+}
+
 func (r *BaseRule[T]) preExecute() {
+	defer r.timeHook(PhasePreExecute)()
 	r.onPreExecute(r)
 }
 
+// OnInit sets a function preparing the rule, such as compiling patterns or
+// warming caches. It runs exactly once, even across concurrent runs, before the
+// rule is first evaluated; if it fails, every run firing the rule stops with
+// its error.
+func (r *BaseRule[T]) OnInit(f func() error) *BaseRule[T] {
+	r.init = &ruleInit{f: f}
+	r.nameHook(PhaseInit, "")
+	return r
+}
+
+func (r *BaseRule[T]) initialize() error {
+	if r.init == nil {
+		return nil
+	}
+	r.init.once.Do(func() {
+		defer r.timeHook(PhaseInit)()
+		r.init.err = r.init.f()
+	})
+	return r.init.err
+}
+
 // OnPreExecute sets the pre-execution function for the rule.
 func (r *BaseRule[T]) OnPreExecute(f func(Context)) *BaseRule[T] {
 	r.onPreExecute = f
+	r.nameHook(PhasePreExecute, "")
 	return r
 }
 
-func (r *BaseRule[T]) execute() {
+func (r *BaseRule[T]) execute() error {
+	defer r.timeHook(PhaseExecute)()
+	if rc := r.GetRuleContext(); rc != nil && rc.chaos != nil {
+		if err := rc.chaos.inject(rc, r.Path()); err != nil {
+			return err
+		}
+	}
+	if r.onExecuteErr != nil {
+		return r.onExecuteErr(r)
+	}
 	r.onExecute(r)
+	return nil
 }
 
 // OnExecute sets the execution function for the rule.
 func (r *BaseRule[T]) OnExecute(f func(Context)) *BaseRule[T] {
 	r.onExecute = f
+	r.onExecuteErr = nil
+	r.nameHook(PhaseExecute, "")
+	return r
+}
+
+// OnExecuteWithError sets an execution function that can fail, in place of
+// OnExecute. Its error stops the run as a *RuleError in PhaseExecute, except
+// among FallbackRule siblings, where the next sibling is tried instead.
+func (r *BaseRule[T]) OnExecuteWithError(f func(Context) error) *BaseRule[T] {
+	r.onExecuteErr = f
+	r.nameHook(PhaseExecute, "")
 	return r
 }
 
 func (r *BaseRule[T]) postExecute() {
+	defer r.timeHook(PhasePostExecute)()
 	r.onPostExecute(r)
 }
 
 // OnPostExecute sets the post-execution function for the rule.
 func (r *BaseRule[T]) OnPostExecute(f func(Context)) *BaseRule[T] {
 	r.onPostExecute = f
+	r.nameHook(PhasePostExecute, "")
 	return r
 }
 
@@ -98,65 +408,321 @@ func (r *BaseRule[T]) GetChildren() []*BaseRule[T] {
 	return r.children
 }
 
-// AddChildren adds child rules to the rule.
+// ErrTooManyChildren is returned by AddChildrenE and WithElseE when a chain
+// rule would get more than one child, and by ChainRuleRunner when given more
+// than one rule.
+var ErrTooManyChildren = errors.New("ChainRule can only have one child")
+
+// AddChildren adds child rules to the rule. It panics when a chain rule would
+// get more than one child; AddChildrenE returns the error instead.
 func (r *BaseRule[T]) AddChildren(rules ...*BaseRule[T]) *BaseRule[T] {
+	if err := r.AddChildrenE(rules...); err != nil {
+		panic(err.Error())
+	}
+	return r
+}
+
+// AddChildrenE adds child rules to the rule, as AddChildren does, or returns
+// ErrTooManyChildren without adding any when a chain rule would get more
+// than one child.
+func (r *BaseRule[T]) AddChildrenE(rules ...*BaseRule[T]) error {
 	switch r.ruleType {
-	case chainRuleType:
+	case ChainRuleType:
 		if len(r.children)+len(rules) > 1 {
-			panic("ChainRule can only have one child")
+			return ErrTooManyChildren
 		}
+	case LoopRuleType:
+		if ruleTypeOf[T]() == ChainRuleType && len(r.children)+len(rules) > 1 {
+			return ErrTooManyChildren
+		}
+	}
+	for _, child := range rules {
+		child.parent = r
 	}
 	r.children = append(r.children, rules...)
+	r.index = nil
+	return nil
+}
+
+// RuleType returns the type of the rule.
+func (r *BaseRule[T]) RuleType() RuleType {
+	return r.ruleType
+}
+
+// Parent returns the rule the rule was last added to as a child, or nil for
+// a root.
+func (r *BaseRule[T]) Parent() *BaseRule[T] {
+	return r.parent
+}
+
+// Detach removes the rule, with its children, from its parent, making it a
+// root that can be added elsewhere.
+func (r *BaseRule[T]) Detach() *BaseRule[T] {
+	if r.parent == nil {
+		return r
+	}
+	var p = r.parent
+	for i, child := range p.children {
+		if child == r {
+			p.children = append(p.children[:i:i], p.children[i+1:]...)
+			break
+		}
+	}
+	for i, child := range p.elseChildren {
+		if child == r {
+			p.elseChildren = append(p.elseChildren[:i:i], p.elseChildren[i+1:]...)
+			break
+		}
+	}
+	p.index = nil
+	r.parent = nil
 	return r
 }
 
-func (r *BaseRule[T]) fire() bool {
+// Depth returns the number of ancestors of the rule, 0 for a root.
+func (r *BaseRule[T]) Depth() int {
+	var depth = 0
+	for p := r.parent; p != nil; p = p.parent {
+		depth++
+	}
+	return depth
+}
+
+// fire runs the rule and its children. It reports whether a best-first runner
+// should go on to the next sibling, and the first error stopping the run.
+func (r *BaseRule[T]) fire() (bool, error) {
+	return r.fireEvaluated(nil)
+}
+
+// fireEvaluated fires the rule as fire does. When evaluated is not nil, it is
+// the result of the OnEval of the rule, already run and traced by the caller,
+// as in inference runs, which evaluate rules ahead of firing them.
+func (r *BaseRule[T]) fireEvaluated(evaluated *bool) (bool, error) {
+	if r.annotation {
+		if rc := r.GetRuleContext(); rc != nil && rc.recording {
+			rc.evaluations = append(rc.evaluations, Evaluation{Rule: r, Result: true, Annotation: true})
+		}
+		return runRules(r.ruleType, r.GetRuleContext(), r.children)
+	}
+	if rc := r.GetRuleContext(); rc != nil {
+		var previous = rc.current
+		rc.current = r
+		defer func() { rc.current = previous }()
+
+		if rc.debugger != nil && !rc.debugger.pause(r, rc) {
+			return true, nil
+		}
+		if !rc.admit(r, r.cost, r.optional) {
+			return true, nil
+		}
+		if rc.softDeadline > 0 {
+			defer rc.timeRule(r)()
+		}
+	}
+	if err := r.initialize(); err != nil {
+		return false, r.newError(PhaseInit, err)
+	}
+	if r.assert != nil {
+		var done = r.timeHook(PhaseAssert)
+		var err = r.assert(r)
+		done()
+		if err != nil {
+			return false, r.newError(PhaseAssert, err)
+		}
+		if err := r.failed(PhaseAssert); err != nil {
+			return false, err
+		}
+	}
+
+	var matched bool
+	if evaluated != nil {
+		matched = *evaluated
+	} else {
+		matched = r.eval()
+		if err := r.failed(PhaseEval); err != nil {
+			return false, err
+		}
+	}
+	if matched && r.ruleType == VotingRuleType {
+		var err error
+		if matched, err = r.vote(); err != nil {
+			return false, err
+		}
+	}
+	if evaluated == nil {
+		r.trace(TraceEval, matched)
+	}
+
+	if !matched && r.hasElse() {
+		return ruleTypeOf[T]() != BestFirstRuleType, r.runElse()
+	}
+
 	switch r.ruleType {
-	case chainRuleType:
-		if r.eval() {
-			r.preExecute()
-			r.execute()
-			r.postExecute()
-			r.runChildren()
-		}
-	case bestFirstRuleType:
-		if r.eval() {
-			r.preExecute()
-			r.execute()
-			r.postExecute()
-			r.runChildren()
-			return false
+	case ChainRuleType:
+		if matched {
+			if err := r.run(); err != nil {
+				return false, err
+			}
+			return true, r.runChildren()
+		}
+	case BestFirstRuleType, FallbackRuleType:
+		if matched {
+			if err := r.run(); err != nil {
+				return false, err
+			}
+			if rc := r.GetRuleContext(); rc != nil && rc.backtracking && r.ruleType == BestFirstRuleType {
+				return r.explore()
+			}
+			return false, r.runChildren()
+		}
+	case AllMatchRuleType:
+		if matched {
+			if err := r.run(); err != nil {
+				return false, err
+			}
+			return true, r.runChildren()
+		}
+	case ParallelRuleType:
+		if matched {
+			if err := r.run(); err != nil {
+				return false, err
+			}
+			return ruleTypeOf[T]() != BestFirstRuleType, r.runChildren()
+		}
+	case LoopRuleType:
+		if matched {
+			return ruleTypeOf[T]() != BestFirstRuleType, r.loop()
+		}
+	case VotingRuleType:
+		if matched {
+			return ruleTypeOf[T]() != BestFirstRuleType, r.run()
+		}
+	case RoundRobinRuleType:
+		if matched {
+			if err := r.run(); err != nil {
+				return false, err
+			}
+			return ruleTypeOf[T]() != BestFirstRuleType, r.rotate()
+		}
+	case WeightedRandomRuleType:
+		if matched {
+			if err := r.run(); err != nil {
+				return false, err
+			}
+			return ruleTypeOf[T]() != BestFirstRuleType, r.pick()
 		}
 	}
-	return true
+	return true, nil
 }
 
-func (r *BaseRule[T]) runChildren() {
-	RuleRunner(r.ruleType, r.GetRuleContext(), r.GetChildren()...)
+// run executes the rule hooks, stopping at an error from the execution.
+func (r *BaseRule[T]) run() error {
+	r.preExecute()
+	if err := r.failed(PhasePreExecute); err != nil {
+		return err
+	}
+	if err := r.execute(); err != nil {
+		return r.newError(PhaseExecute, err)
+	}
+	if err := r.failed(PhaseExecute); err != nil {
+		return err
+	}
+	r.postExecute()
+	if err := r.failed(PhasePostExecute); err != nil {
+		return err
+	}
+	r.trace(TraceExecute, true)
+	return nil
 }
 
-// RuleRunner executes a list of rules within a given RuleContext.
-func RuleRunner[T any](ruleType ruleType, ruleContext *RuleContext, rules ...*BaseRule[T]) {
+func (r *BaseRule[T]) trace(kind TraceKind, result bool) {
+	if rc := r.GetRuleContext(); rc != nil && rc.tracer != nil {
+		rc.tracer.add(r.original(), kind, result)
+	}
+}
+
+func (r *BaseRule[T]) runChildren() error {
+	if r.indexChildren && r.ruleType == BestFirstRuleType {
+		if child, ok := r.dispatchChild(); ok {
+			if child != nil {
+				child.SetRuleContext(r.GetRuleContext())
+				var _, err = child.fire()
+				return err
+			}
+			return nil
+		}
+	}
+	return RuleRunner(r.childRuleType(), r.GetRuleContext(), r.GetChildren()...)
+}
+
+// childRuleType returns the type the children of the rule run as.
+func (r *BaseRule[T]) childRuleType() RuleType {
+	if r.ruleType == LoopRuleType || r.ruleType == VotingRuleType ||
+		r.ruleType == RoundRobinRuleType || r.ruleType == WeightedRandomRuleType {
+		return ruleTypeOf[T]()
+	}
+	return r.ruleType
+}
+
+// RuleRunner executes a list of rules within a given RuleContext. It stops at
+// the first error, such as a failed OnInit, and returns it as a *RuleError.
+func RuleRunner[T any](ruleType RuleType, ruleContext *RuleContext, rules ...*BaseRule[T]) error {
+	if inspected(ruleContext, rules) {
+		return errInspected
+	}
+	var _, err = runRules(ruleType, ruleContext, rules)
+	return err
+}
+
+// runRules runs rules as RuleRunner does, also reporting whether none of them
+// matched, so a group of best-first rules can be followed by the next sibling.
+func runRules[T any](ruleType RuleType, ruleContext *RuleContext, rules []*BaseRule[T]) (bool, error) {
 	if len(rules) == 0 {
-		return
+		return true, nil
 	}
 
 	switch ruleType {
-	case chainRuleType:
+	case ChainRuleType:
 		if len(rules) > 1 {
-			panic("ChainRuleRunner only supports one rule")
+			return false, rules[1].newError("", ErrTooManyChildren)
 		}
 
 		var r = rules[0]
 		r.SetRuleContext(ruleContext)
-		r.fire()
+		return r.fire()
 
-	case bestFirstRuleType:
+	case BestFirstRuleType:
+		var selected, err = selectScored(ruleContext, byPriority(rules))
+		if err != nil {
+			return false, err
+		}
+		if ruleContext != nil && ruleContext.backtracking {
+			return runBacktracking(ruleContext, selected)
+		}
+		for _, r := range selected {
+			r.SetRuleContext(ruleContext)
+			var next, err = r.fire()
+			if err != nil || !next {
+				return next, err
+			}
+		}
+
+	case AllMatchRuleType:
 		for _, r := range rules {
 			r.SetRuleContext(ruleContext)
-			if !r.fire() {
-				break
+			if _, err := r.fire(); err != nil {
+				return false, err
 			}
 		}
+
+	case ParallelRuleType:
+		return true, runParallel(ruleContext, rules)
+
+	case LoopRuleType, VotingRuleType, RoundRobinRuleType, WeightedRandomRuleType:
+		return runRules(ruleTypeOf[T](), ruleContext, rules)
+
+	case FallbackRuleType:
+		return runFallback(ruleContext, rules)
 	}
+	return true, nil
 }
@@ -1,6 +1,9 @@
 package rule
 
 import (
+	"errors"
+	"sync"
+	"sync/atomic"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -35,7 +38,7 @@ func TestBaseRule_AddChild(t *testing.T) {
 
 func TestBaseRule_AddChildren(t *testing.T) {
 	r := &BaseRule[int]{}
-	r.ruleType = bestFirstRuleType
+	r.ruleType = BestFirstRuleType
 	child1 := &BaseRule[int]{}
 	child2 := &BaseRule[int]{}
 	r.AddChildren(child1, child2)
@@ -46,7 +49,7 @@ func TestBaseRule_AddChildren(t *testing.T) {
 
 func TestBaseRule_ChainRule_Panics_When_More_Than_One_Child(t *testing.T) {
 	r := &BaseRule[int]{}
-	r.ruleType = chainRuleType
+	r.ruleType = ChainRuleType
 	child1 := &BaseRule[int]{}
 	child2 := &BaseRule[int]{}
 	assert.Panics(t, func() {
@@ -54,20 +57,166 @@ func TestBaseRule_ChainRule_Panics_When_More_Than_One_Child(t *testing.T) {
 	})
 }
 
+func TestBaseRule_Structure(t *testing.T) {
+	grandchild := NewBestFirstRule()
+	child := NewBestFirstRule().AddChildren(grandchild)
+	root := NewBestFirstRule().AddChildren(child, NewBestFirstRule())
+
+	assert.Equal(t, BestFirstRuleType, root.RuleType())
+	assert.Equal(t, ChainRuleType, NewChainRule().RuleType())
+	assert.Nil(t, root.Parent())
+	assert.Equal(t, child, grandchild.Parent())
+	assert.Equal(t, root, child.Parent())
+	assert.Equal(t, 0, root.Depth())
+	assert.Equal(t, 2, grandchild.Depth())
+	assert.Equal(t, "best-first", BestFirstRuleType.String())
+	assert.Equal(t, "RuleType(9)", RuleType(9).String())
+}
+
+func TestBaseRule_Detach(t *testing.T) {
+	first := NewBestFirstRule()
+	second := NewBestFirstRule().AddChildren(NewBestFirstRule())
+	third := NewBestFirstRule()
+	root := NewBestFirstRule().AddChildren(first, second, third)
+
+	assert.Equal(t, second, second.Detach())
+	assert.Nil(t, second.Parent())
+	assert.Equal(t, []*BaseRule[BestFirstRule]{first, third}, root.GetChildren())
+	assert.Equal(t, 1, len(second.GetChildren()))
+	assert.Equal(t, second, second.Detach())
+
+	chain := NewChainRule().AddChildren(NewChainRule())
+	chain.GetChildren()[0].Detach()
+	assert.NotPanics(t, func() { chain.AddChildren(NewChainRule()) })
+}
+
+func TestBaseRule_AddChildrenE(t *testing.T) {
+	r := NewChainRule()
+	child1 := NewChainRule()
+
+	assert.ErrorIs(t, r.AddChildrenE(child1, NewChainRule()), ErrTooManyChildren)
+	assert.Empty(t, r.GetChildren())
+	assert.NoError(t, r.AddChildrenE(child1))
+	assert.ErrorIs(t, r.AddChildrenE(NewChainRule()), ErrTooManyChildren)
+	assert.Equal(t, []*BaseRule[ChainRule]{child1}, r.GetChildren())
+
+	assert.NoError(t, NewBestFirstRule().AddChildrenE(NewBestFirstRule(), NewBestFirstRule()))
+}
+
 func TestBaseRule_FireChainRuleType(t *testing.T) {
-	r := &BaseRule[int]{ruleType: chainRuleType}
+	r := &BaseRule[int]{ruleType: ChainRuleType}
 	r.OnEval(func(ctx Context) bool { return true })
 	r.OnPreExecute(func(ctx Context) {})
 	r.OnExecute(func(ctx Context) {})
 	r.OnPostExecute(func(ctx Context) {})
-	assert.True(t, r.fire())
+	next, err := r.fire()
+	assert.NoError(t, err)
+	assert.True(t, next)
 }
 
 func TestBaseRule_FireBestFirstRuleType(t *testing.T) {
-	r := &BaseRule[int]{ruleType: bestFirstRuleType}
+	r := &BaseRule[int]{ruleType: BestFirstRuleType}
 	r.OnEval(func(ctx Context) bool { return true })
 	r.OnPreExecute(func(ctx Context) {})
 	r.OnExecute(func(ctx Context) {})
 	r.OnPostExecute(func(ctx Context) {})
-	assert.False(t, r.fire())
+	next, err := r.fire()
+	assert.NoError(t, err)
+	assert.False(t, next)
+}
+
+func TestBaseRule_WithName(t *testing.T) {
+	r := NewChainRule().WithName("validate")
+	assert.Equal(t, "validate", r.GetName())
+}
+
+func TestRuleContext_Values(t *testing.T) {
+	rc := NewRuleContext()
+	rc.Set("key", "value")
+
+	values := rc.Values()
+	values["other"] = true
+
+	assert.Equal(t, map[string]interface{}{"key": "value"}, rc.Values())
+}
+
+func TestBaseRule_OnInit(t *testing.T) {
+	var inits, evals = 0, 0
+	r := NewChainRule().OnInit(func() error {
+		inits++
+		return nil
+	}).OnEval(func(Context) bool {
+		evals++
+		return inits == 1
+	})
+
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, ChainRuleRunner(NewRuleContext(), r))
+	}
+	assert.Equal(t, 1, inits)
+	assert.Equal(t, 3, evals)
+}
+
+func TestBaseRule_OnInitConcurrent(t *testing.T) {
+	var inits int32
+	var wg sync.WaitGroup
+	r := NewChainRule().OnInit(func() error {
+		atomic.AddInt32(&inits, 1)
+		return nil
+	})
+
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r := *r
+			assert.NoError(t, ChainRuleRunner(NewRuleContext(), &r))
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), inits)
+}
+
+func TestBaseRule_OnInitError(t *testing.T) {
+	var cause = errors.New("invalid pattern")
+	var executed = false
+	var broken = NewBestFirstRule().WithName("broken").OnInit(func() error { return cause })
+	var next = NewBestFirstRule().OnExecute(func(Context) { executed = true })
+
+	err := BestFirstRuleRunner(NewRuleContext(), NewBestFirstRule().OnEval(func(Context) bool { return false }), broken, next)
+
+	var ruleErr *RuleError
+	assert.ErrorAs(t, err, &ruleErr)
+	assert.Equal(t, broken, ruleErr.Rule)
+	assert.ErrorIs(t, err, cause)
+	assert.False(t, executed)
+	assert.ErrorIs(t, ChainRuleRunner(NewRuleContext(), NewChainRule().AddChildren(NewChainRule().OnInit(func() error { return cause }))), cause)
+}
+
+func TestBaseRule_OnEvalWithError(t *testing.T) {
+	var executed []string
+	var rules = []*BaseRule[BestFirstRule]{
+		NewBestFirstRule().WithName("lookup").
+			OnEvalWithError(func(ctx Context) (bool, error) {
+				if ctx.GetRuleContext().Get("down") == true {
+					return false, errors.New("connection refused")
+				}
+				return true, nil
+			}).
+			OnExecute(func(Context) { executed = append(executed, "lookup") }),
+		NewBestFirstRule().OnExecute(func(Context) { executed = append(executed, "next") }),
+	}
+
+	assert.NoError(t, BestFirstRuleRunner(NewRuleContext(), rules...))
+	assert.Equal(t, []string{"lookup"}, executed)
+
+	executed = nil
+	ruleContext := NewRuleContext()
+	ruleContext.Set("down", true)
+	var err = BestFirstRuleRunner(ruleContext, rules...)
+	assert.EqualError(t, err, `rule "lookup" in eval: connection refused`)
+	var ruleErr *RuleError
+	assert.ErrorAs(t, err, &ruleErr)
+	assert.Equal(t, PhaseEval, ruleErr.Phase)
+	assert.Empty(t, executed)
 }
@@ -0,0 +1,180 @@
+package rule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Cron is a parsed five-field cron expression: minute, hour, day of month,
+// month and day of week. Fields accept '*', numbers, ranges "a-b", steps "/n"
+// and comma separated lists. As in cron, when both the day of month and the
+// day of week are restricted, a time matches if either does.
+type Cron struct {
+	minute, hour, dom, month, dow uint64
+	domAny, dowAny                bool
+}
+
+var cronBounds = [5][2]int{{0, 59}, {0, 23}, {1, 31}, {1, 12}, {0, 7}}
+
+// ParseCron parses a five-field cron expression.
+func ParseCron(expr string) (*Cron, error) {
+	var fields = strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron %q: expected 5 fields, got %d", expr, len(fields))
+	}
+
+	var sets [5]uint64
+	for i, field := range fields {
+		set, err := parseCronField(field, cronBounds[i][0], cronBounds[i][1])
+		if err != nil {
+			return nil, fmt.Errorf("cron %q: %w", expr, err)
+		}
+		sets[i] = set
+	}
+
+	// Sunday is both 0 and 7.
+	if sets[4]&(1<<7) != 0 {
+		sets[4] |= 1
+	}
+
+	return &Cron{
+		minute: sets[0],
+		hour:   sets[1],
+		dom:    sets[2],
+		month:  sets[3],
+		dow:    sets[4],
+		domAny: fields[2] == "*",
+		dowAny: fields[4] == "*",
+	}, nil
+}
+
+func parseCronField(field string, min, max int) (uint64, error) {
+	var set uint64
+	for _, part := range strings.Split(field, ",") {
+		var rangePart, stepPart, hasStep = strings.Cut(part, "/")
+		var step = 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(stepPart); err != nil || step <= 0 {
+				return 0, fmt.Errorf("invalid step %q", stepPart)
+			}
+		}
+
+		var lo, hi = min, max
+		if rangePart != "*" {
+			var from, to, isRange = strings.Cut(rangePart, "-")
+			var err error
+			if lo, err = strconv.Atoi(from); err != nil {
+				return 0, fmt.Errorf("invalid value %q", from)
+			}
+			hi = lo
+			if isRange {
+				if hi, err = strconv.Atoi(to); err != nil {
+					return 0, fmt.Errorf("invalid value %q", to)
+				}
+			} else if hasStep {
+				hi = max
+			}
+		}
+		if lo < min || hi > max || lo > hi {
+			return 0, fmt.Errorf("value %q out of range [%d, %d]", rangePart, min, max)
+		}
+
+		for v := lo; v <= hi; v += step {
+			set |= 1 << v
+			if step > hi-v {
+				break
+			}
+		}
+	}
+	return set, nil
+}
+
+// Matches reports whether the minute of t matches the expression.
+func (c *Cron) Matches(t time.Time) bool {
+	if c.minute&(1<<t.Minute()) == 0 || c.hour&(1<<t.Hour()) == 0 || c.month&(1<<int(t.Month())) == 0 {
+		return false
+	}
+
+	var dom = c.dom&(1<<t.Day()) != 0
+	var dow = c.dow&(1<<int(t.Weekday())) != 0
+	switch {
+	case c.domAny && c.dowAny:
+		return true
+	case c.domAny:
+		return dow
+	case c.dowAny:
+		return dom
+	}
+	return dom || dow
+}
+
+// CronMatches returns an OnEval predicate reporting whether the current time
+// of the context Clock matches the cron expression. The expression is parsed
+// once, when the predicate is created; an invalid expression panics.
+func CronMatches(expr string) func(Context) bool {
+	cron, err := ParseCron(expr)
+	if err != nil {
+		panic(err)
+	}
+	return func(ctx Context) bool {
+		return cron.Matches(ctx.GetRuleContext().Now())
+	}
+}
+
+// Between returns an OnEval predicate reporting whether the current time of
+// the context Clock is within [start, end).
+func Between(start, end time.Time) func(Context) bool {
+	return func(ctx Context) bool {
+		var now = ctx.GetRuleContext().Now()
+		return !now.Before(start) && now.Before(end)
+	}
+}
+
+// Calendar tells business days from weekends and holidays.
+type Calendar struct {
+	weekend  map[time.Weekday]bool
+	holidays map[string]bool
+}
+
+// NewCalendar creates a Calendar with Saturday and Sunday as weekend days and
+// the dates of the given times as holidays.
+func NewCalendar(holidays ...time.Time) *Calendar {
+	var c = &Calendar{
+		weekend:  map[time.Weekday]bool{time.Saturday: true, time.Sunday: true},
+		holidays: make(map[string]bool),
+	}
+	return c.AddHolidays(holidays...)
+}
+
+// WithWeekend replaces the weekend days of the calendar.
+func (c *Calendar) WithWeekend(days ...time.Weekday) *Calendar {
+	c.weekend = make(map[time.Weekday]bool, len(days))
+	for _, d := range days {
+		c.weekend[d] = true
+	}
+	return c
+}
+
+// AddHolidays adds the dates of the given times as holidays.
+func (c *Calendar) AddHolidays(holidays ...time.Time) *Calendar {
+	for _, h := range holidays {
+		c.holidays[h.Format(time.DateOnly)] = true
+	}
+	return c
+}
+
+// IsBusinessDay reports whether the date of t is neither a weekend day nor a holiday.
+func (c *Calendar) IsBusinessDay(t time.Time) bool {
+	return !c.weekend[t.Weekday()] && !c.holidays[t.Format(time.DateOnly)]
+}
+
+// BusinessDay returns an OnEval predicate reporting whether the current date
+// of the context Clock is a business day of the calendar.
+func BusinessDay(c *Calendar) func(Context) bool {
+	return func(ctx Context) bool {
+		return c.IsBusinessDay(ctx.GetRuleContext().Now())
+	}
+}
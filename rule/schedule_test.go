@@ -0,0 +1,96 @@
+package rule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func at(t time.Time) Context {
+	rule := NewChainRule()
+	rule.SetRuleContext(NewRuleContext(WithClock(ClockFunc(func() time.Time { return t }))))
+	return rule
+}
+
+func TestParseCron(t *testing.T) {
+	for _, expr := range []string{"* * * * *", "*/15 9-17 * * 1-5", "0 0 1,15 * *", "30 2 * * 7", "5-20/5 * * 1-6/2 *", "1/9223372036854775807 * * * *"} {
+		_, err := ParseCron(expr)
+		assert.NoError(t, err, expr)
+	}
+
+	for expr, msg := range map[string]string{
+		"* * * *":     `cron "* * * *": expected 5 fields, got 4`,
+		"60 * * * *":  `cron "60 * * * *": value "60" out of range [0, 59]`,
+		"* * 0 * *":   `cron "* * 0 * *": value "0" out of range [1, 31]`,
+		"*/0 * * * *": `cron "*/0 * * * *": invalid step "0"`,
+		"a * * * *":   `cron "a * * * *": invalid value "a"`,
+		"5-1 * * * *": `cron "5-1 * * * *": value "5-1" out of range [0, 59]`,
+	} {
+		_, err := ParseCron(expr)
+		assert.EqualError(t, err, msg)
+	}
+}
+
+func TestCron_Matches(t *testing.T) {
+	var monday = time.Date(2024, 3, 4, 9, 30, 0, 0, time.UTC)
+	var sunday = time.Date(2024, 3, 3, 9, 30, 0, 0, time.UTC)
+
+	cron, _ := ParseCron("*/15 9-17 * * 1-5")
+	assert.True(t, cron.Matches(monday))
+	assert.False(t, cron.Matches(monday.Add(time.Minute)))
+	assert.False(t, cron.Matches(sunday))
+
+	cron, _ = ParseCron("30 9 * * 7")
+	assert.True(t, cron.Matches(sunday))
+
+	cron, _ = ParseCron("30 9 1 * 1")
+	assert.True(t, cron.Matches(monday), "day of week matches")
+	assert.True(t, cron.Matches(time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)), "day of month matches")
+	assert.False(t, cron.Matches(sunday))
+}
+
+func TestCronMatches(t *testing.T) {
+	var match = CronMatches("0 12 * * *")
+
+	assert.True(t, match(at(time.Date(2024, 3, 4, 12, 0, 42, 0, time.UTC))))
+	assert.False(t, match(at(time.Date(2024, 3, 4, 12, 1, 0, 0, time.UTC))))
+	assert.Panics(t, func() { CronMatches("bogus") })
+}
+
+func TestBetween(t *testing.T) {
+	var start = time.Date(2024, 11, 29, 0, 0, 0, 0, time.UTC)
+	var blackFriday = Between(start, start.Add(24*time.Hour))
+
+	assert.True(t, blackFriday(at(start)))
+	assert.True(t, blackFriday(at(start.Add(23*time.Hour))))
+	assert.False(t, blackFriday(at(start.Add(24*time.Hour))))
+	assert.False(t, blackFriday(at(start.Add(-time.Second))))
+}
+
+func TestCalendar(t *testing.T) {
+	var christmas = time.Date(2024, 12, 25, 0, 0, 0, 0, time.UTC)
+	var calendar = NewCalendar(christmas)
+
+	assert.False(t, calendar.IsBusinessDay(christmas.Add(15*time.Hour)))
+	assert.True(t, calendar.IsBusinessDay(christmas.AddDate(0, 0, 1)))
+	assert.False(t, calendar.IsBusinessDay(time.Date(2024, 12, 28, 0, 0, 0, 0, time.UTC)))
+
+	calendar.WithWeekend(time.Friday)
+	assert.True(t, BusinessDay(calendar)(at(time.Date(2024, 12, 28, 0, 0, 0, 0, time.UTC))))
+	assert.False(t, BusinessDay(calendar)(at(time.Date(2024, 12, 27, 0, 0, 0, 0, time.UTC))))
+}
+
+func FuzzParseCron(f *testing.F) {
+	for _, expr := range []string{"* * * * *", "*/15 9-17 * * 1-5", "0 0 1,15 * *", "5-20/5 * * 1-6/2 *", "*/0 * * * *"} {
+		f.Add(expr)
+	}
+	var now = time.Date(2024, 2, 29, 12, 30, 0, 0, time.UTC)
+	f.Fuzz(func(t *testing.T, expr string) {
+		cron, err := ParseCron(expr)
+		if err != nil {
+			return
+		}
+		cron.Matches(now)
+	})
+}
@@ -0,0 +1,125 @@
+package rule
+
+// OnEvalScore sets a scoring function as the evaluation of the rule, in place
+// of OnEval. The rule matches when its score is above its minimum score, see
+// WithMinScore.
+//
+// Among best-first siblings, the scored rules are evaluated all at once, where
+// the first of them stands in the sibling order, and only the highest scoring
+// one above its minimum is kept; ties go to the first in order. The other
+// siblings are evaluated as usual, so a boolean rule placed, or prioritized,
+// before the scored ones still takes precedence over them. Scored rules that
+// would be skipped, by their canary, their states or the budget, are left out
+// of the selection, so the best of the others is kept.
+func (r *BaseRule[T]) OnEvalScore(f func(Context) float64) *BaseRule[T] {
+	r.onEvalScore = f
+	r.dispatch = nil
+	r.nameHook(PhaseEval, "")
+	return r
+}
+
+// WithMinScore sets the score a rule evaluated with OnEvalScore must exceed
+// to match. It defaults to 0.
+func (r *BaseRule[T]) WithMinScore(min float64) *BaseRule[T] {
+	r.minScore = min
+	return r
+}
+
+// evalScore evaluates a scored rule, reusing the score computed while
+// selecting it among its siblings.
+func (r *BaseRule[T]) evalScore() bool {
+	var score float64
+	if r.score != nil {
+		score, r.score = *r.score, nil
+	} else {
+		score = r.onEvalScore(r)
+	}
+	var result = r.inCanary() && r.inState() && score > r.minScore
+	if rc := r.GetRuleContext(); rc != nil && rc.recording {
+		rc.evaluations = append(rc.evaluations, Evaluation{Rule: r, Result: result, Score: score})
+	}
+	return result
+}
+
+// selectScored replaces the scored rules among best-first siblings with the
+// highest scoring one above its minimum, if any, at the position of the first
+// scored rule. Rules that fire would skip are not scored.
+func selectScored[T any](rc *RuleContext, rules []*BaseRule[T]) ([]*BaseRule[T], error) {
+	var first = -1
+	for i, r := range rules {
+		if r.onEvalScore != nil {
+			first = i
+			break
+		}
+	}
+	if first < 0 {
+		return rules, nil
+	}
+
+	var best *BaseRule[T]
+	var bestScore float64
+	var losers []Evaluation
+	for _, r := range rules[first:] {
+		if r.onEvalScore == nil {
+			continue
+		}
+		r.SetRuleContext(rc)
+		r.score = nil
+		if !r.inCanary() || !r.inState() {
+			continue
+		}
+		if rc != nil && !rc.affords(r.cost, r.optional) {
+			rc.skipped = append(rc.skipped, r)
+			continue
+		}
+		var score, err = r.scoreOf(rc)
+		if err != nil {
+			return nil, err
+		}
+		if score <= r.minScore {
+			losers = append(losers, Evaluation{Rule: r, Score: score})
+			continue
+		}
+		if best == nil || score > bestScore {
+			if best != nil {
+				losers = append(losers, Evaluation{Rule: best, Score: bestScore})
+			}
+			best, bestScore = r, score
+		} else {
+			losers = append(losers, Evaluation{Rule: r, Score: score})
+		}
+	}
+	if rc != nil && rc.recording {
+		rc.evaluations = append(rc.evaluations, losers...)
+	}
+
+	var selected = make([]*BaseRule[T], 0, len(rules))
+	for i, r := range rules {
+		switch {
+		case r.onEvalScore == nil:
+			selected = append(selected, r)
+		case i == first && best != nil:
+			var score = bestScore
+			best.score = &score
+			selected = append(selected, best)
+		}
+	}
+	return selected, nil
+}
+
+// scoreOf computes the score of the rule with the rule as the current one, so
+// that its reads are attributed to it.
+func (r *BaseRule[T]) scoreOf(rc *RuleContext) (float64, error) {
+	if rc != nil {
+		var previous = rc.current
+		rc.current = r
+		defer func() { rc.current = previous }()
+	}
+	if err := r.initialize(); err != nil {
+		return 0, r.newError(PhaseInit, err)
+	}
+	var done = r.timeHook(PhaseEval)
+	var score = r.onEvalScore(r)
+	done()
+	return score, r.failed(PhaseEval)
+}
@@ -0,0 +1,114 @@
+package rule
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func scored(name string, score float64) *BaseRule[BestFirstRule] {
+	return NewBestFirstRule().WithName(name).
+		OnEvalScore(func(ctx Context) float64 {
+			var rc = ctx.GetRuleContext()
+			rc.Set("scored."+name, true)
+			return score
+		}).
+		OnExecute(func(ctx Context) { ctx.GetRuleContext().Set("picked", name) })
+}
+
+func TestOnEvalScore_PicksHighest(t *testing.T) {
+	ruleContext := NewRuleContext(WithHistory())
+	assert.NoError(t, BestFirstRuleRunner(ruleContext,
+		scored("low", 0.2), scored("high", 0.9), scored("tie", 0.9), scored("negative", -1)))
+
+	assert.Equal(t, "high", ruleContext.Get("picked"))
+	for _, name := range []string{"low", "high", "tie", "negative"} {
+		assert.Equal(t, true, ruleContext.Get("scored."+name), name)
+	}
+
+	var scores = make(map[string]float64)
+	var matched []string
+	for _, e := range ruleContext.Evaluations() {
+		scores[e.Rule.(Named).GetName()] = e.Score
+		if e.Result {
+			matched = append(matched, e.Rule.(Named).GetName())
+		}
+	}
+	assert.Equal(t, map[string]float64{"low": 0.2, "high": 0.9, "tie": 0.9, "negative": -1}, scores)
+	assert.Equal(t, []string{"high"}, matched)
+}
+
+func TestOnEvalScore_MinScore(t *testing.T) {
+	ruleContext := NewRuleContext()
+	assert.NoError(t, BestFirstRuleRunner(ruleContext,
+		scored("a", 0.4).WithMinScore(0.5),
+		scored("b", 0.3).WithMinScore(0.5),
+		NewBestFirstRule().OnExecute(func(ctx Context) { ctx.GetRuleContext().Set("picked", "fallback") }),
+	))
+	assert.Equal(t, "fallback", ruleContext.Get("picked"))
+}
+
+func TestOnEvalScore_SkipsIneligible(t *testing.T) {
+	ruleContext := NewRuleContext(WithBudget(1))
+	assert.NoError(t, BestFirstRuleRunner(ruleContext,
+		scored("canary", 0.9).Canary(0),
+		scored("expensive", 0.8).Cost(5).Optional(),
+		scored("cheap", 0.3).Cost(1).Optional(),
+	))
+
+	assert.Equal(t, "cheap", ruleContext.Get("picked"))
+	assert.Nil(t, ruleContext.Get("scored.canary"))
+	assert.Nil(t, ruleContext.Get("scored.expensive"))
+	assert.Len(t, ruleContext.Skipped(), 1)
+	assert.Equal(t, 1.0, ruleContext.Spent())
+}
+
+func TestOnEvalScore_MixedWithBooleanRules(t *testing.T) {
+	var boolean = func(name string, match bool) *BaseRule[BestFirstRule] {
+		return NewBestFirstRule().WithName(name).
+			OnEval(func(Context) bool { return match }).
+			OnExecute(func(ctx Context) { ctx.GetRuleContext().Set("picked", name) })
+	}
+
+	ruleContext := NewRuleContext()
+	assert.NoError(t, BestFirstRuleRunner(ruleContext,
+		boolean("miss", false), scored("a", 0.1), boolean("hit", true), scored("b", 0.7)))
+	assert.Equal(t, "b", ruleContext.Get("picked"))
+
+	ruleContext = NewRuleContext()
+	assert.NoError(t, BestFirstRuleRunner(ruleContext,
+		boolean("hit", true), scored("a", 0.1), scored("b", 0.7)))
+	assert.Equal(t, "hit", ruleContext.Get("picked"))
+
+	ruleContext = NewRuleContext()
+	assert.NoError(t, BestFirstRuleRunner(ruleContext,
+		scored("a", 0.1).WithPriority(-1), boolean("hit", true)))
+	assert.Equal(t, "hit", ruleContext.Get("picked"))
+}
+
+func TestOnEvalScore_OtherRunners(t *testing.T) {
+	var rule = NewChainRule().OnEvalScore(func(Context) float64 { return 0.6 }).WithMinScore(0.5).
+		OnExecute(func(ctx Context) { ctx.GetRuleContext().Set("ran", true) })
+
+	ruleContext := NewRuleContext()
+	assert.NoError(t, ChainRuleRunner(ruleContext, rule))
+	assert.Equal(t, true, ruleContext.Get("ran"))
+
+	ruleContext = NewRuleContext()
+	assert.NoError(t, ChainRuleRunner(ruleContext, rule.WithMinScore(0.6)))
+	assert.Nil(t, ruleContext.Get("ran"))
+
+	rule.OnEval(func(Context) bool { return true })
+	assert.NoError(t, ChainRuleRunner(ruleContext, rule))
+	assert.Equal(t, true, ruleContext.Get("ran"))
+}
+
+func TestOnEvalScore_StrictKeys(t *testing.T) {
+	ruleContext := NewRuleContext(WithStrictKeys())
+	var err = BestFirstRuleRunner(ruleContext, NewBestFirstRule().WithName("risk").
+		OnEvalScore(func(ctx Context) float64 {
+			ctx.GetRuleContext().Get("missing")
+			return 1
+		}))
+	assert.EqualError(t, err, `rule "risk" in eval: key "missing" is missing`)
+}
@@ -0,0 +1,27 @@
+package rule
+
+// Shared returns an OnEval predicate that evaluates f at most once per
+// RuleContext state and shares the result between every rule using the same id.
+//
+// This lets many rules reuse an expensive condition, as in the alpha network
+// of a RETE engine: the first rule to evaluate it pays the cost and the others
+// read the cached result. Any Set on the RuleContext discards the cached
+// results, so a condition is never reused after its inputs may have changed.
+//
+// Conditions sharing an id must be identical: whichever one evaluates first
+// provides the result for all of them.
+func Shared(id string, f func(Context) bool) func(Context) bool {
+	return func(ctx Context) bool {
+		var rc = ctx.GetRuleContext()
+		if result, ok := rc.shared[id]; ok {
+			return result
+		}
+
+		var result = f(ctx)
+		if rc.shared == nil {
+			rc.shared = make(map[string]bool)
+		}
+		rc.shared[id] = result
+		return result
+	}
+}
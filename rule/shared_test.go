@@ -0,0 +1,48 @@
+package rule
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShared_EvaluatesOncePerRun(t *testing.T) {
+	var calls int
+	var isVip = Shared("is_vip", func(ctx Context) bool {
+		calls++
+		return ctx.GetRuleContext().Get("tier") == "vip"
+	})
+
+	rule := NewBestFirstRule()
+	rule2 := NewBestFirstRule()
+	rule3 := NewBestFirstRule()
+	rule.OnEval(func(ctx Context) bool { return !isVip(ctx) })
+	rule2.OnEval(func(ctx Context) bool { return !isVip(ctx) })
+	rule3.OnEval(isVip).OnExecute(func(ctx Context) {})
+
+	ruleContext := NewRuleContext()
+	ruleContext.Set("tier", "vip")
+
+	BestFirstRuleRunner(ruleContext, rule, rule2, rule3)
+	assert.Equal(t, 1, calls)
+
+	BestFirstRuleRunner(NewRuleContext(), rule, rule2, rule3)
+	assert.Equal(t, 2, calls)
+}
+
+func TestShared_DiscardedOnSet(t *testing.T) {
+	var calls int
+	var isVip = Shared("is_vip", func(ctx Context) bool {
+		calls++
+		return ctx.GetRuleContext().Get("tier") == "vip"
+	})
+
+	rule := NewChainRule()
+	rule.GetRuleContext().Set("tier", "regular")
+	assert.False(t, isVip(rule))
+	assert.False(t, isVip(rule))
+
+	rule.GetRuleContext().Set("tier", "vip")
+	assert.True(t, isVip(rule))
+	assert.Equal(t, 2, calls)
+}
@@ -0,0 +1,110 @@
+package rule
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// SimClock is a virtual Clock for simulations and tests: its time only moves
+// when it is advanced. It is safe for concurrent use.
+type SimClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewSimClock creates a SimClock set to start.
+func NewSimClock(start time.Time) *SimClock {
+	return &SimClock{now: start}
+}
+
+// Now returns the virtual time.
+func (c *SimClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Advance moves the virtual time forward by d.
+func (c *SimClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	c.now = c.now.Add(d)
+	c.mu.Unlock()
+}
+
+// Set moves the virtual time to t, which must not be before the current time.
+func (c *SimClock) Set(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if t.Before(c.now) {
+		panic("simulated time can't go backwards")
+	}
+	c.now = t
+}
+
+// SimResult is the outcome of a simulated run.
+type SimResult struct {
+	Time    time.Time
+	Context *RuleContext
+	Err     error
+}
+
+type simEvent struct {
+	at     time.Time
+	values map[string]interface{}
+}
+
+// Simulation runs a program over events scheduled at virtual times, one at a
+// time and in time order, on a SimClock. Time-based features, such as
+// windows, schedules, soft deadlines and cached configurations created with
+// the clock, see the virtual time, so scenarios spanning hours run instantly
+// and deterministically. Parallel rules run their children one at a time.
+type Simulation struct {
+	clock   *SimClock
+	start   time.Time
+	program Program
+	opts    []ContextOption
+	events  []simEvent
+}
+
+// NewSimulation creates a Simulation of the program starting at start. Each
+// run gets a new RuleContext created with the options.
+func NewSimulation(start time.Time, program Program, opts ...ContextOption) *Simulation {
+	return &Simulation{clock: NewSimClock(start), start: start, program: program, opts: opts}
+}
+
+// Clock returns the virtual clock of the simulation.
+func (s *Simulation) Clock() *SimClock {
+	return s.clock
+}
+
+// At schedules a run at offset from the start of the simulation, with the
+// given context values. Runs scheduled at the same time run in the order
+// they were added.
+func (s *Simulation) At(offset time.Duration, values map[string]interface{}) *Simulation {
+	s.events = append(s.events, simEvent{at: s.start.Add(offset), values: values})
+	return s
+}
+
+// Run runs the scheduled events not run yet, advancing the clock to the time
+// of each, and returns their results in time order.
+func (s *Simulation) Run() []SimResult {
+	var events = s.events
+	s.events = nil
+	sort.SliceStable(events, func(i, j int) bool { return events[i].at.Before(events[j].at) })
+
+	var results = make([]SimResult, 0, len(events))
+	for _, e := range events {
+		if e.at.After(s.clock.Now()) {
+			s.clock.Set(e.at)
+		}
+		var rc = NewRuleContext(append(append([]ContextOption(nil), s.opts...), WithClock(s.clock))...)
+		rc.sequential = true
+		for k, v := range e.values {
+			rc.Set(k, v)
+		}
+		var err = s.program(rc)
+		results = append(results, SimResult{Time: s.clock.Now(), Context: rc, Err: err})
+	}
+	return results
+}
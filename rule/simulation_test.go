@@ -0,0 +1,86 @@
+package rule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var simStart = time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func TestSimulation_Window(t *testing.T) {
+	var logins = NewWindow("logins", SlidingWindow, 10*time.Minute).By("user")
+	var program = NewProgram(NewChainRule().
+		OnExecuteWithError(func(ctx Context) error { return logins.Observe(ctx.GetRuleContext()) }).
+		AddChildren(NewChainRule().
+			OnEval(func(ctx Context) bool { return ctx.GetRuleContext().Get("logins.count").(int) > 3 }).
+			OnExecute(func(ctx Context) { ctx.GetRuleContext().Set("flagged", true) })))
+
+	var sim = NewSimulation(simStart, program)
+	for _, offset := range []time.Duration{0, time.Minute, 2 * time.Minute, 3 * time.Minute, 20 * time.Minute} {
+		sim.At(offset, map[string]interface{}{"user": "alice"})
+	}
+	sim.At(3*time.Minute, map[string]interface{}{"user": "bob"})
+
+	var results = sim.Run()
+	var flagged []interface{}
+	for _, r := range results {
+		assert.NoError(t, r.Err)
+		flagged = append(flagged, r.Context.Get("flagged"))
+	}
+	assert.Equal(t, []interface{}{nil, nil, nil, true, nil, nil}, flagged)
+	assert.Equal(t, "bob", results[4].Context.Get("user"))
+	assert.Equal(t, simStart.Add(20*time.Minute), results[5].Time)
+	assert.Equal(t, simStart.Add(20*time.Minute), sim.Clock().Now())
+	assert.Empty(t, sim.Run())
+}
+
+func TestSimulation_CachedConfig(t *testing.T) {
+	var lookups int
+	var source = configFunc(func(name string) (string, bool) {
+		lookups++
+		return "on", true
+	})
+
+	var sim = NewSimulation(simStart, func(*RuleContext) error { return nil })
+	var config = CachedConfigClock(source, time.Hour, sim.Clock())
+	config.Lookup("flag")
+	sim.Clock().Advance(59 * time.Minute)
+	config.Lookup("flag")
+	assert.Equal(t, 1, lookups)
+	sim.Clock().Advance(2 * time.Minute)
+	config.Lookup("flag")
+	assert.Equal(t, 2, lookups)
+}
+
+type configFunc func(name string) (string, bool)
+
+func (f configFunc) Lookup(name string) (string, bool) {
+	return f(name)
+}
+
+func TestSimulation_Parallel(t *testing.T) {
+	var order []string
+	var child = func(name string) *BaseRule[ChainRule] {
+		return NewChainRule().OnExecute(func(Context) { order = append(order, name) })
+	}
+	var program = NewProgram(NewParallelRule[ChainRule]().AddChildren(child("a"), child("b"), child("c")))
+
+	var sim = NewSimulation(simStart, program)
+	for i := 0; i < 20; i++ {
+		sim.At(time.Duration(i)*time.Second, nil)
+	}
+	sim.Run()
+	assert.Len(t, order, 60)
+	for i := 0; i < len(order); i += 3 {
+		assert.Equal(t, []string{"a", "b", "c"}, order[i:i+3])
+	}
+}
+
+func TestSimClock(t *testing.T) {
+	var clock = NewSimClock(simStart)
+	clock.Advance(time.Hour)
+	assert.Equal(t, simStart.Add(time.Hour), clock.Now())
+	assert.PanicsWithValue(t, "simulated time can't go backwards", func() { clock.Set(simStart) })
+}
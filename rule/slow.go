@@ -0,0 +1,53 @@
+package rule
+
+import (
+	"log/slog"
+	"time"
+)
+
+// SlowHook describes a hook that took longer than the slow rule threshold.
+type SlowHook struct {
+	Rule     Context
+	Path     string
+	Phase    Phase
+	Duration time.Duration
+}
+
+// WithSlowRuleThreshold reports every hook of the runs of the RuleContext
+// taking longer than d, with the path of its rule and its duration, to
+// report, or as a warning of the default slog logger when report is nil. It
+// is a cheap safeguard to leave enabled, unlike tracing. Time is read from
+// the Clock of the context.
+func WithSlowRuleThreshold(d time.Duration, report func(SlowHook)) ContextOption {
+	return func(rc *RuleContext) {
+		rc.slowThreshold = d
+		rc.slowReport = report
+	}
+}
+
+func logSlowHook(h SlowHook) {
+	slog.Warn("slow rule", "path", h.Path, "phase", string(h.Phase), "duration", h.Duration)
+}
+
+var untimed = func() {}
+
+// timeHook starts timing a hook of the rule, which is reported when the
+// returned function is called after the slow rule threshold.
+func (r *BaseRule[T]) timeHook(phase Phase) func() {
+	var rc = r.GetRuleContext()
+	if rc == nil || rc.slowThreshold <= 0 {
+		return untimed
+	}
+	var start = rc.Now()
+	return func() {
+		var elapsed = rc.Now().Sub(start)
+		if elapsed <= rc.slowThreshold {
+			return
+		}
+		var report = rc.slowReport
+		if report == nil {
+			report = logSlowHook
+		}
+		report(SlowHook{Rule: r, Path: r.Path(), Phase: phase, Duration: elapsed})
+	}
+}
@@ -0,0 +1,75 @@
+package rule
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithSlowRuleThreshold(t *testing.T) {
+	var now = time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	var wait = func(d time.Duration) { now = now.Add(d) }
+
+	var root = NewChainRule().WithName("root").
+		OnInit(func() error { wait(time.Second); return nil }).
+		OnEval(func(Context) bool { wait(5 * time.Millisecond); return true })
+	var lookup = NewChainRule().WithName("lookup").
+		OnExecute(func(Context) { wait(200 * time.Millisecond) }).
+		OnPostExecute(func(Context) { wait(10 * time.Millisecond) })
+	root.AddChildren(lookup)
+
+	var slow []SlowHook
+	rc := NewRuleContext(
+		WithClock(ClockFunc(func() time.Time { return now })),
+		WithSlowRuleThreshold(10*time.Millisecond, func(h SlowHook) { slow = append(slow, h) }),
+	)
+	assert.NoError(t, ChainRuleRunner(rc, root))
+	assert.NoError(t, ChainRuleRunner(rc, root))
+
+	assert.Equal(t, []SlowHook{
+		{Rule: root, Path: "root", Phase: PhaseInit, Duration: time.Second},
+		{Rule: lookup, Path: "root/lookup", Phase: PhaseExecute, Duration: 200 * time.Millisecond},
+		{Rule: lookup, Path: "root/lookup", Phase: PhaseExecute, Duration: 200 * time.Millisecond},
+	}, slow)
+}
+
+func TestWithSlowRuleThreshold_Phases(t *testing.T) {
+	var now = time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	var wait = func(d time.Duration) { now = now.Add(d) }
+	var phases []Phase
+	var newContext = func() *RuleContext {
+		return NewRuleContext(
+			WithClock(ClockFunc(func() time.Time { return now })),
+			WithSlowRuleThreshold(time.Millisecond, func(h SlowHook) { phases = append(phases, h.Phase) }),
+		)
+	}
+
+	assert.NoError(t, BestFirstRuleRunner(newContext(),
+		NewBestFirstRule().OnEval(func(Context) bool { wait(time.Second); return false }).
+			OnElse(func(Context) { wait(time.Second) }),
+		NewBestFirstRule().OnEvalScore(func(Context) float64 { wait(time.Second); return 1 }),
+	))
+	assert.NoError(t, ChainRuleRunner(newContext(), NewChainRule().OnPreExecute(func(Context) { wait(time.Second) })))
+	assert.Error(t, ChainRuleRunner(newContext(), NewAssertRule[ChainRule]("slow",
+		func(Context) bool { wait(time.Second); return false })))
+	assert.Equal(t, []Phase{PhaseEval, PhaseEval, PhaseElse, PhasePreExecute, PhaseAssert}, phases)
+}
+
+func TestWithSlowRuleThreshold_Log(t *testing.T) {
+	var buf bytes.Buffer
+	var logger = slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&buf, nil)))
+	defer slog.SetDefault(logger)
+
+	var now = time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	rc := NewRuleContext(
+		WithClock(ClockFunc(func() time.Time { return now })),
+		WithSlowRuleThreshold(time.Millisecond, nil),
+	)
+	assert.NoError(t, ChainRuleRunner(rc, NewChainRule().WithName("lookup").
+		OnExecute(func(Context) { now = now.Add(time.Second) })))
+	assert.Contains(t, buf.String(), `level=WARN msg="slow rule" path=lookup phase=execute duration=1s`)
+}
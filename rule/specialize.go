@@ -0,0 +1,61 @@
+package rule
+
+// Specialize prunes a rule tree for context keys whose values are known
+// constants of the deployment, such as region or environment.
+//
+// Rules whose condition was set with WhenKeyIn or WhenKeyBetween on a constant
+// key are folded: when the condition can never hold the rule is removed along
+// with its children, and when it always holds the condition is replaced by a
+// constant true. In a BestFirstRule, siblings following an always-true rule can
+// never fire and are removed as well, in the order set by their priorities,
+// unless the rule can still be skipped at run time, as canary, optional and
+// state-gated rules are. Other conditions, and rules with an else branch, are
+// left untouched.
+//
+// The tree is modified in place and the rules that can still fire are returned.
+// The constant keys must not be written by the rules during a run.
+func Specialize[T any](constants map[string]interface{}, rules ...*BaseRule[T]) []*BaseRule[T] {
+	var rc = &RuleContext{context: constants}
+	var keep = make(map[*BaseRule[T]]bool, len(rules))
+	var scored, final = false, false
+
+	// Rules are visited in the order they run, by priority. Once a rule always
+	// fires, only the scored rules can still run, selected with those before.
+	for _, r := range byPriority(rules) {
+		if final && (r.onEvalScore == nil || !scored) {
+			continue
+		}
+		scored = scored || r.onEvalScore != nil
+
+		if cond := r.dispatch; cond != nil && !r.hasElse() {
+			if _, ok := constants[cond.key]; ok {
+				var probe = &BaseRule[T]{context: rc}
+				if !cond.eval(probe) {
+					continue
+				}
+				r.OnEval(func(Context) bool { return true })
+				r.children = Specialize(constants, r.children...)
+				r.index = nil
+				keep[r] = true
+
+				if r.ruleType == BestFirstRuleType && r.canary == nil && !r.optional && len(r.states) == 0 {
+					final = true
+				}
+				continue
+			}
+		}
+
+		r.children = Specialize(constants, r.children...)
+		r.elseChildren = Specialize(constants, r.elseChildren...)
+		r.index = nil
+		keep[r] = true
+	}
+
+	var kept = make([]*BaseRule[T], 0, len(keep))
+	for _, r := range rules {
+		if keep[r] {
+			kept = append(kept, r)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,96 @@
+package rule
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSpecialize_PrunesImpossibleRules(t *testing.T) {
+	eu := NewBestFirstRule().WhenKeyIn("region", "eu")
+	us := NewBestFirstRule().WhenKeyIn("region", "us")
+	other := NewBestFirstRule()
+	root := NewBestFirstRule().AddChildren(us, eu, other)
+
+	rules := Specialize(map[string]interface{}{"region": "eu"}, root)
+
+	assert.Equal(t, []*BaseRule[BestFirstRule]{root}, rules)
+	assert.Equal(t, []*BaseRule[BestFirstRule]{eu}, root.GetChildren())
+	assert.Nil(t, eu.dispatch)
+	assert.True(t, eu.eval())
+}
+
+func TestSpecialize_KeepsRulesOnUnknownKeys(t *testing.T) {
+	low := NewBestFirstRule().WhenKeyBetween("amount", 0, 100)
+	high := NewBestFirstRule().WhenKeyBetween("amount", 100, 1000)
+
+	rules := Specialize(map[string]interface{}{"region": "eu"}, low, high)
+
+	assert.Equal(t, []*BaseRule[BestFirstRule]{low, high}, rules)
+	assert.NotNil(t, low.dispatch)
+}
+
+func TestSpecialize_ChainRule(t *testing.T) {
+	prod := NewChainRule().WhenKeyIn("env", "prod")
+	root := NewChainRule().AddChildren(prod.AddChildren(NewChainRule()))
+
+	Specialize(map[string]interface{}{"env": "staging"}, root)
+	assert.Empty(t, root.GetChildren())
+
+	assert.Empty(t, Specialize(map[string]interface{}{"env": "staging"}, prod))
+}
+
+func TestSpecialize_KeepsSiblingsOfCanaryRules(t *testing.T) {
+	canary := NewBestFirstRule().WhenKeyIn("region", "eu").Canary(1)
+	fallback := NewBestFirstRule()
+
+	rules := Specialize(map[string]interface{}{"region": "eu"}, canary, fallback)
+
+	assert.Equal(t, []*BaseRule[BestFirstRule]{canary, fallback}, rules)
+}
+
+func TestSpecialize_KeepsSiblingsOfSkippableRules(t *testing.T) {
+	var constants = map[string]interface{}{"region": "eu"}
+	optional := NewBestFirstRule().WhenKeyIn("region", "eu").Cost(5).Optional()
+	fallback := NewBestFirstRule().WithName("b").OnExecute(func(ctx Context) { ctx.GetRuleContext().Set("hit", "b") })
+
+	rules := Specialize(constants, optional, fallback)
+	assert.Equal(t, []*BaseRule[BestFirstRule]{optional, fallback}, rules)
+
+	ruleContext := NewRuleContext(WithBudget(1))
+	assert.NoError(t, BestFirstRuleRunner(ruleContext, rules...))
+	assert.Equal(t, "b", ruleContext.Get("hit"))
+
+	gated := NewBestFirstRule().WhenKeyIn("region", "eu").Handles("paid")
+	fallback = NewBestFirstRule()
+	assert.Equal(t, []*BaseRule[BestFirstRule]{gated, fallback}, Specialize(constants, gated, fallback))
+}
+
+func TestSpecialize_Priorities(t *testing.T) {
+	a := NewBestFirstRule().WithName("a").WhenKeyIn("region", "eu")
+	b := NewBestFirstRule().WithName("b").WithPriority(10)
+	c := NewBestFirstRule().WithName("c")
+
+	rules := Specialize(map[string]interface{}{"region": "eu"}, a, b, c)
+
+	assert.Equal(t, []*BaseRule[BestFirstRule]{a, b}, rules)
+}
+
+func TestSpecialize_PreservesBehavior(t *testing.T) {
+	root := NewBestFirstRule().AddChildren(
+		NewBestFirstRule().WhenKeyIn("region", "us").OnExecute(func(ctx Context) {
+			ctx.GetRuleContext().Set("result", "us")
+		}),
+		NewBestFirstRule().WhenKeyIn("region", "eu").OnExecute(func(ctx Context) {
+			ctx.GetRuleContext().Set("result", "eu")
+		}),
+	)
+
+	rules := Specialize(map[string]interface{}{"region": "eu"}, root)
+
+	ruleContext := NewRuleContext()
+	ruleContext.Set("region", "eu")
+	BestFirstRuleRunner(ruleContext, rules...)
+
+	assert.Equal(t, "eu", ruleContext.Get("result"))
+}
@@ -0,0 +1,138 @@
+package rule
+
+import "fmt"
+
+// Transition is an allowed change of state in a StateMachine.
+type Transition struct {
+	From string
+	To   string
+	// When guards the transition, which is always allowed when When is nil.
+	// Like OnEval functions, guards should have no side effects.
+	When func(Context) bool
+	// Action runs when the transition is taken, before the new state is
+	// written. When it fails, the state is left unchanged.
+	Action func(Context) error
+}
+
+// StateMachine describes the allowed transitions between the states of an
+// entity, such as an order or an account, whose current state is kept under a
+// context key.
+type StateMachine struct {
+	key         string
+	initial     string
+	transitions []Transition
+}
+
+// NewStateMachine creates a StateMachine keeping the state under the key.
+func NewStateMachine(key string) *StateMachine {
+	return &StateMachine{key: key}
+}
+
+// Initial sets the state of entities whose key is not set. Without it, such
+// entities take no transition.
+func (m *StateMachine) Initial(state string) *StateMachine {
+	m.initial = state
+	return m
+}
+
+// On adds a transition. Transitions are tried in the order they were added
+// and the first allowed one is taken.
+func (m *StateMachine) On(t Transition) *StateMachine {
+	m.transitions = append(m.transitions, t)
+	return m
+}
+
+// State returns the current state in the context, or the initial state when
+// the key is not set. States that are not strings are formatted with fmt.Sprint.
+func (m *StateMachine) State(rc *RuleContext) string {
+	var value, ok = rc.read(m.key)
+	if !ok || value == nil {
+		return m.initial
+	}
+	if s, ok := value.(string); ok {
+		return s
+	}
+	return fmt.Sprint(value)
+}
+
+// next returns the first transition allowed from the current state.
+func (m *StateMachine) next(ctx Context) (Transition, bool) {
+	var state = m.State(ctx.GetRuleContext())
+	if state == "" {
+		return Transition{}, false
+	}
+	for _, t := range m.transitions {
+		if t.From == state && (t.When == nil || t.When(ctx)) {
+			return t, true
+		}
+	}
+	return Transition{}, false
+}
+
+// NewStateMachineRule creates a rule applying the machine to the context. The
+// rule matches when a transition is allowed from the current state; running it
+// calls the transition Action and writes the new state. Its children run
+// afterwards, so those declaring the states they handle with Handles act on
+// entering the new state.
+//
+// T is the kind of the tree the rule is part of, such as ChainRule or BestFirstRule.
+func NewStateMachineRule[T any](machine *StateMachine) *BaseRule[T] {
+	var r = &BaseRule[T]{
+		ruleType: ruleTypeOf[T](),
+		context:  NewRuleContext(),
+		machine:  machine,
+		onEval: func(ctx Context) bool {
+			var _, ok = machine.next(ctx)
+			return ok
+		},
+		onPreExecute:  func(r Context) {},
+		onExecute:     func(r Context) {},
+		onPostExecute: func(r Context) {},
+	}
+	r.onExecuteErr = func(ctx Context) error {
+		var t, ok = machine.next(ctx)
+		if !ok {
+			return fmt.Errorf("no transition allowed from state %q", machine.State(ctx.GetRuleContext()))
+		}
+		if t.Action != nil {
+			if err := t.Action(ctx); err != nil {
+				return fmt.Errorf("transition %s -> %s: %w", t.From, t.To, err)
+			}
+		}
+		ctx.GetRuleContext().Set(machine.key, t.To)
+		return nil
+	}
+	r.nameHook(PhaseEval, "")
+	r.nameHook(PhaseExecute, "")
+	return r
+}
+
+// Handles restricts the rule to the given states of the state machine of its
+// nearest state machine rule ancestor: the rule only matches, on top of its
+// OnEval, while the machine is in one of the states. Without such an
+// ancestor, the rule never matches.
+func (r *BaseRule[T]) Handles(states ...string) *BaseRule[T] {
+	r.states = states
+	return r
+}
+
+// inState reports whether the rule handles the current state of its state
+// machine, always true for rules not restricted with Handles.
+func (r *BaseRule[T]) inState() bool {
+	if len(r.states) == 0 {
+		return true
+	}
+	for p := r.parent; p != nil; p = p.parent {
+		if p.machine == nil {
+			continue
+		}
+		var state = p.machine.State(r.GetRuleContext())
+		for _, s := range r.states {
+			if s == state {
+				return true
+			}
+		}
+		return false
+	}
+	return false
+}
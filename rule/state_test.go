@@ -0,0 +1,118 @@
+package rule
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type orderState string
+
+func orderMachine(actions *[]string) *StateMachine {
+	var log = func(name string) func(Context) error {
+		return func(Context) error {
+			*actions = append(*actions, name)
+			return nil
+		}
+	}
+	return NewStateMachine("order.state").
+		Initial("new").
+		On(Transition{From: "new", To: "paid", Action: log("charge"),
+			When: func(ctx Context) bool { return ctx.GetRuleContext().Get("paid") == true }}).
+		On(Transition{From: "new", To: "cancelled", Action: log("notify")}).
+		On(Transition{From: "paid", To: "shipped", Action: log("ship")})
+}
+
+func TestStateMachineRule(t *testing.T) {
+	var actions []string
+	var r = NewStateMachineRule[ChainRule](orderMachine(&actions))
+
+	rc := NewRuleContext()
+	rc.Set("paid", true)
+	assert.NoError(t, ChainRuleRunner(rc, r))
+	assert.Equal(t, "paid", rc.Get("order.state"))
+
+	assert.NoError(t, ChainRuleRunner(rc, r))
+	assert.Equal(t, "shipped", rc.Get("order.state"))
+
+	assert.NoError(t, ChainRuleRunner(rc, r))
+	assert.Equal(t, "shipped", rc.Get("order.state"))
+	assert.Equal(t, []string{"charge", "ship"}, actions)
+
+	rc = NewRuleContext()
+	assert.NoError(t, ChainRuleRunner(rc, r))
+	assert.Equal(t, "cancelled", rc.Get("order.state"))
+}
+
+func TestStateMachineRule_BestFirstFallsThrough(t *testing.T) {
+	var machine = NewStateMachine("state").On(Transition{From: "open", To: "closed"})
+
+	rc := NewRuleContext()
+	rc.Set("state", orderState("locked"))
+	assert.NoError(t, BestFirstRuleRunner(rc,
+		NewStateMachineRule[BestFirstRule](machine),
+		NewBestFirstRule().OnExecute(func(ctx Context) { ctx.GetRuleContext().Set("fallback", true) }),
+	))
+	assert.Equal(t, orderState("locked"), rc.Get("state"))
+	assert.Equal(t, true, rc.Get("fallback"))
+
+	rc = NewRuleContext()
+	rc.Set("state", orderState("open"))
+	assert.NoError(t, BestFirstRuleRunner(rc, NewStateMachineRule[BestFirstRule](machine)))
+	assert.Equal(t, "closed", rc.Get("state"))
+}
+
+func TestStateMachineRule_ActionError(t *testing.T) {
+	var machine = NewStateMachine("state").Initial("new").
+		On(Transition{From: "new", To: "paid", Action: func(Context) error { return errors.New("card declined") }})
+
+	rc := NewRuleContext()
+	var err = ChainRuleRunner(rc, NewStateMachineRule[ChainRule](machine).WithName("payment"))
+	assert.EqualError(t, err, `rule "payment" in execute: transition new -> paid: card declined`)
+	assert.Nil(t, rc.Get("state"))
+}
+
+func TestHandles(t *testing.T) {
+	var actions []string
+	var entered = func(name string) func(Context) {
+		return func(Context) { actions = append(actions, name) }
+	}
+	var r = NewStateMachineRule[BestFirstRule](orderMachine(&actions)).AddChildren(
+		NewBestFirstRule().Handles("paid").OnExecute(entered("receipt")),
+		NewBestFirstRule().Handles("shipped", "cancelled").OnExecute(entered("close")),
+		NewBestFirstRule().OnExecute(entered("other")),
+	)
+
+	rc := NewRuleContext()
+	rc.Set("paid", true)
+	assert.NoError(t, BestFirstRuleRunner(rc, r))
+	assert.NoError(t, BestFirstRuleRunner(rc, r))
+	assert.Equal(t, []string{"charge", "receipt", "ship", "close"}, actions)
+
+	actions = nil
+	rc = NewRuleContext()
+	rc.Set("order.state", "returned")
+	assert.NoError(t, BestFirstRuleRunner(rc, NewBestFirstRule().AddChildren(r.GetChildren()[0].Detach(), NewBestFirstRule().OnExecute(entered("other")))))
+	assert.Equal(t, []string{"other"}, actions)
+}
+
+func TestHandles_DispatchIndex(t *testing.T) {
+	var machine = NewStateMachine("state").On(Transition{From: "open", To: "closed"})
+	var r = NewStateMachineRule[BestFirstRule](machine).WithDispatchIndex().AddChildren(
+		NewBestFirstRule().WhenKeyIn("channel", "email").Handles("open").
+			OnExecute(func(ctx Context) { ctx.GetRuleContext().Set("handler", "open") }),
+		NewBestFirstRule().WhenKeyIn("channel", "email").Handles("closed").
+			OnExecute(func(ctx Context) { ctx.GetRuleContext().Set("handler", "closed") }),
+	)
+
+	rc := NewRuleContext()
+	rc.Set("state", "open")
+	rc.Set("channel", "email")
+	assert.NoError(t, BestFirstRuleRunner(rc, r))
+	assert.Equal(t, "closed", rc.Get("handler"))
+	assert.NotEqual(t, Hash(r), Hash(NewStateMachineRule[BestFirstRule](machine).WithDispatchIndex().AddChildren(
+		NewBestFirstRule().WhenKeyIn("channel", "email"),
+		NewBestFirstRule().WhenKeyIn("channel", "email"),
+	)))
+}
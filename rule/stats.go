@@ -0,0 +1,83 @@
+package rule
+
+import (
+	"sort"
+	"sync"
+)
+
+// KeyStat counts the accesses to a context key.
+type KeyStat struct {
+	Key    string
+	Reads  int
+	Writes int
+}
+
+// KeyStats counts how often each context key is read and written across all
+// the runs of the contexts it is attached to with WithKeyStats. It is safe
+// for concurrent use.
+type KeyStats struct {
+	mu    sync.Mutex
+	stats map[string]*KeyStat
+}
+
+// NewKeyStats creates an empty KeyStats.
+func NewKeyStats() *KeyStats {
+	return &KeyStats{stats: make(map[string]*KeyStat)}
+}
+
+// WithKeyStats counts the reads and writes of the RuleContext keys in stats.
+func WithKeyStats(stats *KeyStats) ContextOption {
+	return func(rc *RuleContext) {
+		rc.stats = stats
+	}
+}
+
+func (s *KeyStats) count(key string, write bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var stat, ok = s.stats[key]
+	if !ok {
+		stat = &KeyStat{Key: key}
+		s.stats[key] = stat
+	}
+	if write {
+		stat.Writes++
+	} else {
+		stat.Reads++
+	}
+}
+
+// Stats returns the counts of every key accessed so far, sorted by key.
+func (s *KeyStats) Stats() []KeyStat {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var stats = make([]KeyStat, 0, len(s.stats))
+	for _, stat := range s.stats {
+		stats = append(stats, *stat)
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Key < stats[j].Key })
+	return stats
+}
+
+// Unread returns the sorted keys that were written but never read, such as
+// inputs no rule uses.
+func (s *KeyStats) Unread() []string {
+	var keys []string
+	for _, stat := range s.Stats() {
+		if stat.Reads == 0 {
+			keys = append(keys, stat.Key)
+		}
+	}
+	return keys
+}
+
+// Hot returns the n most read keys, most read first, as candidates for
+// precomputation.
+func (s *KeyStats) Hot(n int) []KeyStat {
+	var stats = s.Stats()
+	sort.SliceStable(stats, func(i, j int) bool { return stats[i].Reads > stats[j].Reads })
+	if n < len(stats) {
+		stats = stats[:n]
+	}
+	return stats
+}
@@ -0,0 +1,38 @@
+package rule
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeyStats(t *testing.T) {
+	var stats = NewKeyStats()
+	var rule = NewBestFirstRule().
+		OnEval(func(ctx Context) bool { return ctx.GetRuleContext().Get("amount").(int) > 10 }).
+		OnExecute(func(ctx Context) { ctx.GetRuleContext().Set("status", "review") })
+
+	var wg sync.WaitGroup
+	for _, amount := range []int{5, 50, 500} {
+		wg.Add(1)
+		go func(amount int) {
+			defer wg.Done()
+			rc := NewRuleContext(WithKeyStats(stats))
+			rc.Set("amount", amount)
+			rc.Set("country", "PT")
+			var r = *rule
+			assert.NoError(t, BestFirstRuleRunner(rc, &r))
+		}(amount)
+	}
+	wg.Wait()
+
+	assert.Equal(t, []KeyStat{
+		{Key: "amount", Reads: 3, Writes: 3},
+		{Key: "country", Writes: 3},
+		{Key: "status", Writes: 2},
+	}, stats.Stats())
+	assert.Equal(t, []string{"country", "status"}, stats.Unread())
+	assert.Equal(t, []KeyStat{{Key: "amount", Reads: 3, Writes: 3}}, stats.Hot(1))
+	assert.Equal(t, 3, len(stats.Hot(10)))
+}
@@ -0,0 +1,59 @@
+package rule
+
+// NewSwitchRule creates a best-first rule dispatching on the value stored
+// under key: Case adds the child run for some values of the key, and Default
+// the child run for any other value. The child is found through a dispatch
+// index, see WithDispatchIndex, in constant time however many cases the rule
+// has. It panics if key is empty.
+//
+// When the index can't be used, such as when cases handle states, the cases
+// are evaluated in order instead, the default one matching any value.
+//
+// T is the kind of the tree the rule is part of, such as ChainRule or
+// BestFirstRule.
+func NewSwitchRule[T any](key string) *BaseRule[T] {
+	if key == "" {
+		panic("switch rule key must not be empty")
+	}
+	return &BaseRule[T]{
+		ruleType:      BestFirstRuleType,
+		context:       NewRuleContext(),
+		children:      make([]*BaseRule[T], 0),
+		indexChildren: true,
+		switchKey:     key,
+		onEval:        func(r Context) bool { return true },
+		onPreExecute:  func(r Context) {},
+		onExecute:     func(r Context) {},
+		onPostExecute: func(r Context) {},
+	}
+}
+
+// Case adds child to the switch rule, run when the value of the key equals one
+// of values. A value already listed by an earlier case stays with it. It
+// panics if the rule was not created with NewSwitchRule.
+func (r *BaseRule[T]) Case(child *BaseRule[T], values ...interface{}) *BaseRule[T] {
+	r.mustSwitch()
+	return r.AddChildren(child.WhenKeyIn(r.switchKey, values...))
+}
+
+// Default adds child to the switch rule, run when the value of the key
+// matches no case. It panics if the rule was not created with NewSwitchRule.
+func (r *BaseRule[T]) Default(child *BaseRule[T]) *BaseRule[T] {
+	r.mustSwitch()
+	return r.AddChildren(child.whenOtherwise(r.switchKey))
+}
+
+func (r *BaseRule[T]) mustSwitch() {
+	if r.switchKey == "" {
+		panic("rule " + r.Path() + " is not a switch rule")
+	}
+}
+
+// whenOtherwise sets the evaluation function of the rule to always match, as
+// the default case of the siblings dispatching on key.
+func (r *BaseRule[T]) whenOtherwise(key string) *BaseRule[T] {
+	var cond = &dispatchCond{key: key, otherwise: true}
+	r.OnEval(cond.eval)
+	r.dispatch = cond
+	return r
+}
@@ -0,0 +1,100 @@
+package rule
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func taxRule(rate string) *BaseRule[BestFirstRule] {
+	return NewBestFirstRule().WithName(rate).Use(switchFuncs(), PhaseExecute, rate)
+}
+
+func switchFuncs() *Funcs {
+	var funcs = NewFuncs()
+	for _, rate := range []string{"reduced", "standard", "none"} {
+		var rate = rate
+		funcs.Register(rate, func(ctx Context) { ctx.GetRuleContext().Set("rate", rate) })
+	}
+	return funcs
+}
+
+func taxSwitch() *BaseRule[BestFirstRule] {
+	return NewSwitchRule[BestFirstRule]("country").
+		Case(taxRule("reduced"), "PT", "ES").
+		Case(taxRule("standard"), "DE", "FR", "PT").
+		Default(taxRule("none"))
+}
+
+func switchRun(rule *BaseRule[BestFirstRule], country interface{}) interface{} {
+	ruleContext := NewRuleContext()
+	ruleContext.Set("country", country)
+	if err := BestFirstRuleRunner(ruleContext, rule); err != nil {
+		panic(err)
+	}
+	return ruleContext.Get("rate")
+}
+
+func TestSwitchRule(t *testing.T) {
+	var rule = taxSwitch()
+
+	assert.Equal(t, "reduced", switchRun(rule, "ES"))
+	assert.Equal(t, "reduced", switchRun(rule, "PT"))
+	assert.Equal(t, "standard", switchRun(rule, "FR"))
+	assert.Equal(t, "none", switchRun(rule, "US"))
+	assert.Equal(t, "none", switchRun(rule, []string{"PT"}))
+	assert.True(t, rule.index.ok)
+
+	var noDefault = NewSwitchRule[BestFirstRule]("country").Case(taxRule("standard"), "DE")
+	assert.Nil(t, switchRun(noDefault, "US"))
+}
+
+func TestSwitchRule_ManyCases(t *testing.T) {
+	var rule = NewSwitchRule[ChainRule]("product")
+	for i := 0; i < 500; i++ {
+		var code = fmt.Sprintf("P%03d", i)
+		rule.Case(NewChainRule().WithName(code).OnExecute(func(ctx Context) {
+			ctx.GetRuleContext().Set("matched", code)
+		}), code)
+	}
+
+	ruleContext := NewRuleContext()
+	ruleContext.Set("product", "P421")
+	assert.NoError(t, ChainRuleRunner(ruleContext, NewChainRule().AddChildren(rule)))
+	assert.Equal(t, "P421", ruleContext.Get("matched"))
+}
+
+func TestSwitchRule_WithoutIndex(t *testing.T) {
+	var rule = NewSwitchRule[BestFirstRule]("country").
+		Case(taxRule("reduced").Handles("open"), "PT").
+		Default(taxRule("none"))
+
+	assert.Equal(t, "none", switchRun(rule, "PT"))
+	assert.False(t, rule.index.ok)
+}
+
+func TestSwitchRule_Encode(t *testing.T) {
+	var rule = taxSwitch()
+
+	var buf bytes.Buffer
+	assert.NoError(t, EncodeRules(&buf, rule))
+	decoded, err := DecodeRules[BestFirstRule](&buf, switchFuncs())
+	assert.NoError(t, err)
+	assert.Equal(t, rule.Hash(), decoded[0].Hash())
+	assert.Equal(t, "none", switchRun(decoded[0], "US"))
+	assert.Equal(t, "standard", switchRun(decoded[0].Case(taxRule("standard"), "IT"), "IT"))
+
+	assert.NotEqual(t, rule.Hash(), NewSwitchRule[BestFirstRule]("region").
+		Case(taxRule("reduced"), "PT", "ES").
+		Case(taxRule("standard"), "DE", "FR", "PT").
+		Default(taxRule("none")).Hash())
+}
+
+func TestSwitchRule_Panics(t *testing.T) {
+	assert.PanicsWithValue(t, "switch rule key must not be empty", func() { NewSwitchRule[ChainRule]("") })
+	assert.PanicsWithValue(t, "rule root is not a switch rule", func() {
+		NewBestFirstRule().WithName("root").Case(NewBestFirstRule(), "PT")
+	})
+}
@@ -0,0 +1,94 @@
+package rule
+
+import "sync"
+
+// TraceKind is the kind of a trace event.
+type TraceKind uint8
+
+const (
+	// TraceEval is recorded when a rule is evaluated.
+	TraceEval TraceKind = iota
+	// TraceExecute is recorded when a rule was executed.
+	TraceExecute
+)
+
+// TraceEvent is a traced step of a run. Rules are referenced by their index
+// in the Tracer, so recording an event doesn't allocate.
+type TraceEvent struct {
+	Kind   TraceKind
+	Rule   int32
+	Result bool
+}
+
+// Tracer records the last events of runs in a pre-allocated ring buffer. Once
+// every rule of a tree has been seen, tracing adds no allocations to a run,
+// so it can stay enabled in production. Copies of a rule made with Clone, such
+// as the ones Programs run, are traced as the rule they were cloned from, so
+// the rules known to the Tracer don't grow with the copies. It is safe for
+// concurrent use.
+type Tracer struct {
+	mu      sync.Mutex
+	events  []TraceEvent
+	next    int
+	full    bool
+	rules   []Context
+	indexes map[Context]int32
+}
+
+// NewTracer creates a Tracer keeping the given number of most recent events.
+func NewTracer(size int) *Tracer {
+	if size <= 0 {
+		panic("trace buffer size must be positive")
+	}
+	return &Tracer{events: make([]TraceEvent, size), indexes: make(map[Context]int32)}
+}
+
+// WithTracer records the steps of the runs of the RuleContext in tracer.
+func WithTracer(tracer *Tracer) ContextOption {
+	return func(rc *RuleContext) {
+		rc.tracer = tracer
+	}
+}
+
+func (t *Tracer) add(r Context, kind TraceKind, result bool) {
+	t.mu.Lock()
+	var i, ok = t.indexes[r]
+	if !ok {
+		i = int32(len(t.rules))
+		t.rules = append(t.rules, r)
+		t.indexes[r] = i
+	}
+	t.events[t.next] = TraceEvent{Kind: kind, Rule: i, Result: result}
+	t.next++
+	if t.next == len(t.events) {
+		t.next = 0
+		t.full = true
+	}
+	t.mu.Unlock()
+}
+
+// Events returns the recorded events, oldest first.
+func (t *Tracer) Events() []TraceEvent {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.full {
+		return append([]TraceEvent(nil), t.events[:t.next]...)
+	}
+	return append(append([]TraceEvent(nil), t.events[t.next:]...), t.events[:t.next]...)
+}
+
+// Rule returns the rule referenced by an event, the original one for rules run
+// through copies.
+func (t *Tracer) Rule(i int32) Context {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.rules[i]
+}
+
+// Reset discards the recorded events, keeping the buffer and rule indexes.
+func (t *Tracer) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.next = 0
+	t.full = false
+}
@@ -0,0 +1,96 @@
+package rule
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func tracedTree() *BaseRule[BestFirstRule] {
+	return NewBestFirstRule().WithName("root").AddChildren(
+		NewBestFirstRule().WithName("small").OnEval(func(ctx Context) bool { return ctx.GetRuleContext().Get("amount").(int) < 10 }),
+		NewBestFirstRule().WithName("large"),
+	)
+}
+
+func TestTracer(t *testing.T) {
+	var tracer = NewTracer(8)
+	var root = tracedTree()
+	rc := NewRuleContext(WithTracer(tracer))
+	rc.Set("amount", 50)
+	assert.NoError(t, BestFirstRuleRunner(rc, root))
+
+	var events = tracer.Events()
+	assert.Equal(t, []TraceEvent{
+		{Kind: TraceEval, Rule: 0, Result: true},
+		{Kind: TraceExecute, Rule: 0, Result: true},
+		{Kind: TraceEval, Rule: 1, Result: false},
+		{Kind: TraceEval, Rule: 2, Result: true},
+		{Kind: TraceExecute, Rule: 2, Result: true},
+	}, events)
+	assert.Equal(t, "large", tracer.Rule(events[3].Rule).(Named).GetName())
+
+	assert.NoError(t, BestFirstRuleRunner(rc, root))
+	events = tracer.Events()
+	assert.Equal(t, 8, len(events))
+	assert.Equal(t, TraceEvent{Kind: TraceExecute, Rule: 2, Result: true}, events[7])
+	assert.Equal(t, TraceEvent{Kind: TraceEval, Rule: 1, Result: false}, events[0])
+
+	tracer.Reset()
+	assert.Empty(t, tracer.Events())
+	assert.Panics(t, func() { NewTracer(0) })
+}
+
+func TestTracer_NoAllocs(t *testing.T) {
+	var root = tracedTree()
+	rc := NewRuleContext()
+	rc.Set("amount", 50)
+	var untraced = testing.AllocsPerRun(100, func() { _ = BestFirstRuleRunner(rc, root) })
+
+	rc = NewRuleContext(WithTracer(NewTracer(64)))
+	rc.Set("amount", 50)
+	var traced = testing.AllocsPerRun(100, func() { _ = BestFirstRuleRunner(rc, root) })
+
+	assert.Equal(t, untraced, traced)
+}
+
+func TestTracer_Clones(t *testing.T) {
+	var root = tracedTree()
+	var tracer = NewTracer(64)
+	var program = NewProgram(root)
+	for i := 0; i < 200; i++ {
+		rc := NewRuleContext(WithTracer(tracer))
+		rc.Set("amount", 50)
+		assert.NoError(t, program(rc))
+		assert.NoError(t, BestFirstRuleRunner(rc, root.Clone()))
+		if i%20 == 0 {
+			runtime.GC()
+		}
+	}
+
+	assert.Len(t, tracer.rules, 3)
+	assert.Equal(t, root, tracer.Rule(tracer.Events()[0].Rule))
+}
+
+func BenchmarkTracer(b *testing.B) {
+	var root = tracedTree()
+	rc := NewRuleContext(WithTracer(NewTracer(1024)))
+	rc.Set("amount", 50)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = BestFirstRuleRunner(rc, root)
+	}
+}
+
+func BenchmarkUntraced(b *testing.B) {
+	var root = tracedTree()
+	rc := NewRuleContext()
+	rc.Set("amount", 50)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = BestFirstRuleRunner(rc, root)
+	}
+}
@@ -0,0 +1,126 @@
+package rule
+
+import (
+	"fmt"
+	"math"
+)
+
+// quorum is the number of votes a voting rule needs, as set by its options.
+// The zero quorum is a majority.
+type quorum struct {
+	count    int
+	fraction float64
+}
+
+// need returns the number of votes needed among n voters.
+func (q quorum) need(n int) int {
+	switch {
+	case q.count > 0:
+		return q.count
+	case q.fraction > 0:
+		return int(math.Ceil(q.fraction * float64(n)))
+	}
+	return n/2 + 1
+}
+
+func (q quorum) String() string {
+	switch {
+	case q.count > 0:
+		return fmt.Sprintf("at least %d", q.count)
+	case q.fraction > 0:
+		return fmt.Sprintf("at least %v", q.fraction)
+	}
+	return "majority"
+}
+
+// VoteOption configures a voting rule.
+type VoteOption func(*quorum)
+
+// AtLeast makes a voting rule match when at least n of its children vote for
+// it. It panics if n is not positive.
+func AtLeast(n int) VoteOption {
+	if n < 1 {
+		panic("voting rule quorum must be positive")
+	}
+	return func(q *quorum) {
+		*q = quorum{count: n}
+	}
+}
+
+// AtLeastFraction makes a voting rule match when at least the fraction of its
+// children, rounded up, vote for it. It panics if the fraction is not in (0, 1].
+func AtLeastFraction(fraction float64) VoteOption {
+	if fraction <= 0 || fraction > 1 {
+		panic(fmt.Sprintf("voting rule fraction %v out of range (0, 1]", fraction))
+	}
+	return func(q *quorum) {
+		*q = quorum{fraction: fraction}
+	}
+}
+
+// Unanimous makes a voting rule match when all of its children vote for it.
+func Unanimous() VoteOption {
+	return AtLeastFraction(1)
+}
+
+// NewVotingRule creates a rule whose children are voters: when its OnEval
+// returns true, the OnEval of each child is run as a vote, and the rule
+// matches when enough children voted for it, a majority unless set otherwise
+// with AtLeast, AtLeastFraction or Unanimous. The children are only evaluated;
+// the execution hooks of the rule read the number of votes with Votes.
+//
+// T is the kind of the tree the rule is part of, such as ChainRule or
+// BestFirstRule, and the else rules of the rule run as in such a tree.
+func NewVotingRule[T any](opts ...VoteOption) *BaseRule[T] {
+	var r = &BaseRule[T]{
+		ruleType:      VotingRuleType,
+		context:       NewRuleContext(),
+		children:      make([]*BaseRule[T], 0),
+		onEval:        func(r Context) bool { return true },
+		onPreExecute:  func(r Context) {},
+		onExecute:     func(r Context) {},
+		onPostExecute: func(r Context) {},
+	}
+	for _, opt := range opts {
+		opt(&r.quorum)
+	}
+	return r
+}
+
+// Votes returns the number of children that voted for the rule when it was
+// last evaluated, from the Context passed to the hooks of a voting rule.
+func Votes(ctx Context) int {
+	if v, ok := ctx.(interface{ votesCast() int }); ok {
+		return v.votesCast()
+	}
+	return 0
+}
+
+func (r *BaseRule[T]) votesCast() int {
+	return r.votes
+}
+
+// vote evaluates the children of the voting rule and reports whether enough
+// of them voted for it.
+func (r *BaseRule[T]) vote() (bool, error) {
+	var rc = r.GetRuleContext()
+	r.votes = 0
+	for _, child := range r.children {
+		child.SetRuleContext(rc)
+		if rc != nil {
+			rc.current = child
+		}
+		var yes = child.eval()
+		if rc != nil {
+			rc.current = r
+		}
+		if err := child.failed(PhaseEval); err != nil {
+			return false, err
+		}
+		child.trace(TraceEval, yes)
+		if yes {
+			r.votes++
+		}
+	}
+	return r.votes >= r.quorum.need(len(r.children)), nil
+}
@@ -0,0 +1,126 @@
+package rule
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func fraudSignals() []*BaseRule[ChainRule] {
+	var signal = func(key string) *BaseRule[ChainRule] {
+		return NewChainRule().WithName(key).
+			OnEval(func(ctx Context) bool { return ctx.GetRuleContext().Get(key) == true }).
+			OnExecute(func(ctx Context) { ctx.GetRuleContext().Set("executed", key) })
+	}
+	return []*BaseRule[ChainRule]{signal("new-device"), signal("foreign-ip"), signal("night")}
+}
+
+func fraudRun(rule *BaseRule[ChainRule], signals ...string) *RuleContext {
+	ruleContext := NewRuleContext()
+	for _, s := range signals {
+		ruleContext.Set(s, true)
+	}
+	if err := ChainRuleRunner(ruleContext, rule); err != nil {
+		panic(err)
+	}
+	return ruleContext
+}
+
+func TestVotingRule(t *testing.T) {
+	var newRule = func(opts ...VoteOption) *BaseRule[ChainRule] {
+		return NewVotingRule[ChainRule](opts...).AddChildren(fraudSignals()...).
+			OnExecute(func(ctx Context) { ctx.GetRuleContext().Set("votes", Votes(ctx)) })
+	}
+
+	var majority = newRule()
+	assert.Nil(t, fraudRun(majority, "night").Get("votes"))
+	assert.Equal(t, 2, fraudRun(majority, "night", "new-device").Get("votes"))
+
+	var ruleContext = fraudRun(newRule(AtLeast(1)), "night")
+	assert.Equal(t, 1, ruleContext.Get("votes"))
+	assert.Nil(t, ruleContext.Get("executed"))
+
+	assert.Nil(t, fraudRun(newRule(Unanimous()), "night", "new-device").Get("votes"))
+	assert.Equal(t, 3, fraudRun(newRule(Unanimous()), "night", "new-device", "foreign-ip").Get("votes"))
+	assert.Equal(t, 2, fraudRun(newRule(AtLeastFraction(0.5)), "night", "foreign-ip").Get("votes"))
+
+	assert.Equal(t, VotingRuleType, majority.RuleType())
+	assert.Equal(t, "voting", majority.RuleType().String())
+	assert.Equal(t, 0, Votes(NewChainRule()))
+}
+
+func TestVotingRule_OnEval(t *testing.T) {
+	var voted bool
+	var rule = NewVotingRule[ChainRule](AtLeast(1)).
+		OnEval(func(ctx Context) bool { return ctx.GetRuleContext().Get("enabled") == true }).
+		AddChildren(NewChainRule().OnEval(func(Context) bool { voted = true; return true })).
+		OnElse(func(ctx Context) { ctx.GetRuleContext().Set("else", true) })
+
+	var ruleContext = fraudRun(rule)
+	assert.False(t, voted)
+	assert.Equal(t, true, ruleContext.Get("else"))
+}
+
+func TestVotingRule_BestFirst(t *testing.T) {
+	var voting = NewVotingRule[BestFirstRule](AtLeast(2)).AddChildren(
+		NewBestFirstRule().WhenKeyIn("country", "xx"),
+		NewBestFirstRule().WhenKeyBetween("amount", 1000, 1e9),
+	).OnExecute(func(ctx Context) { ctx.GetRuleContext().Set("decision", "review") })
+	var fallback = NewBestFirstRule().OnExecute(func(ctx Context) { ctx.GetRuleContext().Set("decision", "approve") })
+
+	ruleContext := NewRuleContext(WithHistory())
+	ruleContext.Set("country", "xx")
+	ruleContext.Set("amount", 5000)
+	assert.NoError(t, BestFirstRuleRunner(ruleContext, voting, fallback))
+	assert.Equal(t, "review", ruleContext.Get("decision"))
+	assert.Len(t, ruleContext.Evaluations(), 3)
+
+	ruleContext = NewRuleContext()
+	ruleContext.Set("country", "xx")
+	ruleContext.Set("amount", 10)
+	assert.NoError(t, BestFirstRuleRunner(ruleContext, voting, fallback))
+	assert.Equal(t, "approve", ruleContext.Get("decision"))
+}
+
+func TestVotingRule_StrictKeys(t *testing.T) {
+	var voter = NewChainRule().WithName("voter").
+		OnEval(func(ctx Context) bool { return ctx.GetRuleContext().Get("missing") != nil })
+	var rule = NewVotingRule[ChainRule]().WithName("vote").AddChildren(voter)
+
+	var err = ChainRuleRunner(NewRuleContext(WithStrictKeys()), rule)
+	var ruleErr *RuleError
+	assert.ErrorAs(t, err, &ruleErr)
+	assert.Equal(t, Context(voter), ruleErr.Rule)
+	assert.Equal(t, PhaseEval, ruleErr.Phase)
+}
+
+func TestVotingRule_Options(t *testing.T) {
+	assert.PanicsWithValue(t, "voting rule quorum must be positive", func() { AtLeast(0) })
+	assert.PanicsWithValue(t, "voting rule fraction 1.5 out of range (0, 1]", func() { AtLeastFraction(1.5) })
+
+	assert.Equal(t, 3, quorum{}.need(4))
+	assert.Equal(t, 2, quorum{}.need(3))
+	assert.Equal(t, 2, quorum{fraction: 0.4}.need(4))
+	assert.Equal(t, "majority", quorum{}.String())
+	assert.Equal(t, "at least 2", quorum{count: 2}.String())
+}
+
+func TestVotingRule_Structure(t *testing.T) {
+	var newRules = func(opts ...VoteOption) []*BaseRule[BestFirstRule] {
+		return []*BaseRule[BestFirstRule]{NewVotingRule[BestFirstRule](opts...).WithName("vote").AddChildren(
+			NewBestFirstRule().WhenKeyIn("country", "xx"),
+			NewBestFirstRule().WhenKeyBetween("amount", 1000, 1e9),
+		)}
+	}
+
+	assert.NotEqual(t, Hash(newRules()...), Hash(newRules(AtLeast(1))...))
+	assert.Equal(t, []Change{{Kind: Modified, Path: "vote", OldName: "vote", NewName: "vote",
+		Detail: "quorum changed from majority to at least 1"}}, DiffRules(newRules(), newRules(AtLeast(1))))
+
+	var buf bytes.Buffer
+	assert.NoError(t, EncodeRules(&buf, newRules(AtLeastFraction(0.5))...))
+	decoded, err := DecodeRules[BestFirstRule](&buf, NewFuncs())
+	assert.NoError(t, err)
+	assert.Equal(t, Hash(newRules(AtLeastFraction(0.5))...), Hash(decoded...))
+}
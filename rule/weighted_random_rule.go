@@ -0,0 +1,84 @@
+package rule
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// NewWeightedRandomRule creates a rule that picks one of its children at
+// random: each time its OnEval returns true, it executes and fires one child,
+// picked with a probability proportional to its weight, set with WithWeight.
+// It suits traffic splitting and A/B routing.
+//
+// The random numbers come from the source set on the RuleContext with
+// WithRand, for reproducible runs.
+//
+// T is the kind of the tree the rule is part of, such as ChainRule or
+// BestFirstRule, and the else rules of the rule run as in such a tree.
+func NewWeightedRandomRule[T any]() *BaseRule[T] {
+	return &BaseRule[T]{
+		ruleType:      WeightedRandomRuleType,
+		context:       NewRuleContext(),
+		children:      make([]*BaseRule[T], 0),
+		onEval:        func(r Context) bool { return true },
+		onPreExecute:  func(r Context) {},
+		onExecute:     func(r Context) {},
+		onPostExecute: func(r Context) {},
+	}
+}
+
+// WithWeight sets the weight of the rule among the children of a weighted
+// random rule. It defaults to 1, and a rule with a weight of 0 is never
+// picked. It panics if weight is negative.
+func (r *BaseRule[T]) WithWeight(weight int) *BaseRule[T] {
+	if weight < 0 {
+		panic(fmt.Sprintf("rule weight %d is negative", weight))
+	}
+	r.weight = &weight
+	return r
+}
+
+func (r *BaseRule[T]) weightOf() int {
+	if r.weight == nil {
+		return 1
+	}
+	return *r.weight
+}
+
+// WithRand sets the source of random numbers in [0, 1) of the RuleContext,
+// used by weighted random rules. It defaults to math/rand; with parallel
+// rules, it must be safe for concurrent use.
+func WithRand(f func() float64) ContextOption {
+	return func(rc *RuleContext) {
+		rc.rand = f
+	}
+}
+
+func (rc *RuleContext) random() float64 {
+	if rc == nil || rc.rand == nil {
+		return rand.Float64()
+	}
+	return rc.rand()
+}
+
+// pick fires a child picked at random according to the weights.
+func (r *BaseRule[T]) pick() error {
+	var total = 0
+	for _, child := range r.children {
+		total += child.weightOf()
+	}
+	if total == 0 {
+		return nil
+	}
+
+	var rc = r.GetRuleContext()
+	var n = min(int(rc.random()*float64(total)), total-1)
+	for _, child := range r.children {
+		if n -= child.weightOf(); n < 0 {
+			child.SetRuleContext(rc)
+			var _, err = child.fire()
+			return err
+		}
+	}
+	return nil
+}
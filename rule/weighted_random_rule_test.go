@@ -0,0 +1,96 @@
+package rule
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func variant(name string) *BaseRule[ChainRule] {
+	return NewChainRule().WithName(name).OnExecute(func(ctx Context) { ctx.GetRuleContext().Set("variant", name) })
+}
+
+func splitRun(rule *BaseRule[ChainRule], random float64) interface{} {
+	ruleContext := NewRuleContext(WithRand(func() float64 { return random }))
+	if err := ChainRuleRunner(ruleContext, rule); err != nil {
+		panic(err)
+	}
+	return ruleContext.Get("variant")
+}
+
+func TestWeightedRandomRule(t *testing.T) {
+	var rule = NewWeightedRandomRule[ChainRule]().AddChildren(
+		variant("control").WithWeight(90),
+		variant("treatment").WithWeight(10),
+		variant("disabled").WithWeight(0),
+	)
+
+	assert.Equal(t, "control", splitRun(rule, 0))
+	assert.Equal(t, "control", splitRun(rule, 0.89))
+	assert.Equal(t, "treatment", splitRun(rule, 0.9))
+	assert.Equal(t, "treatment", splitRun(rule, 0.999))
+	assert.Equal(t, "treatment", splitRun(rule, 1))
+
+	assert.Equal(t, WeightedRandomRuleType, rule.RuleType())
+	assert.Equal(t, "weighted-random", rule.RuleType().String())
+}
+
+func TestWeightedRandomRule_DefaultWeights(t *testing.T) {
+	var rule = NewWeightedRandomRule[ChainRule]().AddChildren(variant("a"), variant("b"))
+
+	assert.Equal(t, "a", splitRun(rule, 0.49))
+	assert.Equal(t, "b", splitRun(rule, 0.5))
+	assert.Nil(t, splitRun(NewWeightedRandomRule[ChainRule]().AddChildren(variant("a").WithWeight(0)), 0))
+
+	var seen = map[interface{}]int{}
+	for i := 0; i < 200; i++ {
+		ruleContext := NewRuleContext()
+		assert.NoError(t, ChainRuleRunner(ruleContext, rule))
+		seen[ruleContext.Get("variant")]++
+	}
+	assert.Len(t, seen, 2)
+}
+
+func TestWeightedRandomRule_BestFirstSiblings(t *testing.T) {
+	var rule = NewWeightedRandomRule[BestFirstRule]().
+		OnEval(func(ctx Context) bool { return ctx.GetRuleContext().Get("eligible") == true }).
+		AddChildren(NewBestFirstRule().OnExecute(func(ctx Context) { ctx.GetRuleContext().Set("route", "canary") }))
+	var stable = NewBestFirstRule().OnExecute(func(ctx Context) { ctx.GetRuleContext().Set("route", "stable") })
+
+	ruleContext := NewRuleContext()
+	assert.NoError(t, BestFirstRuleRunner(ruleContext, rule, stable))
+	assert.Equal(t, "stable", ruleContext.Get("route"))
+
+	ruleContext = NewRuleContext()
+	ruleContext.Set("eligible", true)
+	assert.NoError(t, BestFirstRuleRunner(ruleContext, rule, stable))
+	assert.Equal(t, "canary", ruleContext.Get("route"))
+}
+
+func TestWithWeight(t *testing.T) {
+	assert.PanicsWithValue(t, "rule weight -1 is negative", func() { NewChainRule().WithWeight(-1) })
+
+	var a = []*BaseRule[ChainRule]{variant("a").WithWeight(3)}
+	var b = []*BaseRule[ChainRule]{variant("a").WithWeight(5)}
+	assert.NotEqual(t, Hash(a...), Hash(b...))
+	assert.Equal(t, []Change{{Kind: Modified, Path: "a", OldName: "a", NewName: "a", Detail: "weight changed from 3 to 5"}}, DiffRules(a, b))
+}
+
+func TestWeightedRandomRule_Encode(t *testing.T) {
+	var funcs = NewFuncs().
+		Register("control", func(ctx Context) { ctx.GetRuleContext().Set("variant", "control") }).
+		Register("treatment", func(ctx Context) { ctx.GetRuleContext().Set("variant", "treatment") })
+	var rule = NewWeightedRandomRule[ChainRule]().AddChildren(
+		NewChainRule().Use(funcs, PhaseExecute, "control").WithWeight(3),
+		NewChainRule().Use(funcs, PhaseExecute, "treatment"),
+	)
+
+	var buf bytes.Buffer
+	assert.NoError(t, EncodeRules(&buf, rule))
+	decoded, err := DecodeRules[ChainRule](&buf, funcs)
+	assert.NoError(t, err)
+	assert.Equal(t, rule.Hash(), decoded[0].Hash())
+	assert.Equal(t, "control", splitRun(decoded[0], 0.7))
+	assert.Equal(t, "treatment", splitRun(decoded[0], 0.8))
+}
@@ -0,0 +1,147 @@
+package rule
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// WindowKind is the way a Window groups events in time.
+type WindowKind int
+
+const (
+	// SlidingWindow aggregates the events of the last window size, up to the
+	// current event.
+	SlidingWindow WindowKind = iota
+	// TumblingWindow aggregates the events of fixed, non-overlapping periods of
+	// the window size, starting over when a period ends.
+	TumblingWindow
+)
+
+// Window keeps count, sum and average aggregations of a stream of events, one
+// RuleContext per event, optionally keyed by a context field. Observing an
+// event exposes the aggregations of its key as context keys, for velocity rules
+// such as "more than 5 logins in 10 minutes":
+//
+//	<name>.count   number of events in the window, including the current one
+//	<name>.sum     sum of the Sum field in the window
+//	<name>.avg     average of the Sum field in the window
+//
+// The event time is the RuleContext Now, and events are expected in time
+// order. A Window is safe for concurrent use.
+type Window struct {
+	name   string
+	kind   WindowKind
+	size   time.Duration
+	by     string
+	field  string
+	mu     sync.Mutex
+	groups map[string]*windowGroup
+}
+
+type windowGroup struct {
+	start  time.Time
+	times  []time.Time
+	values []float64
+	sum    float64
+}
+
+// NewWindow creates a Window of the given kind and size, exposing its
+// aggregations under the name.
+func NewWindow(name string, kind WindowKind, size time.Duration) *Window {
+	if size <= 0 {
+		panic("window size must be positive")
+	}
+	return &Window{name: name, kind: kind, size: size, groups: make(map[string]*windowGroup)}
+}
+
+// By keys the aggregations by the value of a context field, such as the user
+// id. Without it, all events share the same aggregations.
+func (w *Window) By(key string) *Window {
+	w.by = key
+	return w
+}
+
+// Sum sets the numeric context field summed and averaged by the Window.
+// Without it, only the count is kept.
+func (w *Window) Sum(key string) *Window {
+	w.field = key
+	return w
+}
+
+// Observe adds the event of the context to the Window and sets the
+// aggregations of its key in the context. It fails when the context lacks the
+// By field or when the Sum field isn't numeric, leaving the Window unchanged.
+func (w *Window) Observe(rc *RuleContext) error {
+	var group string
+	if w.by != "" {
+		var value, ok = rc.read(w.by)
+		if !ok {
+			return fmt.Errorf("window %q: %w", w.name, &MissingKeyError{Key: w.by})
+		}
+		group = fmt.Sprint(value)
+	}
+
+	var value float64
+	if w.field != "" {
+		var raw, _ = rc.read(w.field)
+		var ok bool
+		if value, ok = toFloat(raw); !ok {
+			return fmt.Errorf("window %q: key %q holds %T, not a number", w.name, w.field, raw)
+		}
+	}
+
+	var now = rc.Now()
+	w.mu.Lock()
+	var g = w.groups[group]
+	if g == nil {
+		g = &windowGroup{}
+		w.groups[group] = g
+	}
+	w.evict(g, now)
+	g.times = append(g.times, now)
+	g.values = append(g.values, value)
+	g.sum += value
+	var count, sum = len(g.times), g.sum
+	w.mu.Unlock()
+
+	rc.Set(w.name+".count", count)
+	if w.field != "" {
+		rc.Set(w.name+".sum", sum)
+		rc.Set(w.name+".avg", sum/float64(count))
+	}
+	return nil
+}
+
+// evict drops the events of the group that are out of the window at now.
+func (w *Window) evict(g *windowGroup, now time.Time) {
+	if w.kind == TumblingWindow {
+		var start = now.Truncate(w.size)
+		if !start.Equal(g.start) {
+			g.start, g.times, g.values, g.sum = start, g.times[:0], g.values[:0], 0
+		}
+		return
+	}
+
+	var cutoff = now.Add(-w.size)
+	var i = 0
+	for i < len(g.times) && !g.times[i].After(cutoff) {
+		g.sum -= g.values[i]
+		i++
+	}
+	g.times, g.values = g.times[i:], g.values[i:]
+}
+
+// Prune drops the keys with no events in the window at now, to bound the
+// memory of long-running streams, and returns the number of keys left.
+func (w *Window) Prune(now time.Time) int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for key, g := range w.groups {
+		w.evict(g, now)
+		if len(g.times) == 0 {
+			delete(w.groups, key)
+		}
+	}
+	return len(w.groups)
+}
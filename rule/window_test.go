@@ -0,0 +1,77 @@
+package rule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func windowEvent(t *testing.T, w *Window, at time.Time, values map[string]interface{}) *RuleContext {
+	rc := NewRuleContext(WithClock(ClockFunc(func() time.Time { return at })))
+	for k, v := range values {
+		rc.Set(k, v)
+	}
+	assert.NoError(t, w.Observe(rc))
+	return rc
+}
+
+func TestWindow_Sliding(t *testing.T) {
+	var start = time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	var w = NewWindow("logins", SlidingWindow, 10*time.Minute).By("user")
+
+	for i := 0; i < 5; i++ {
+		windowEvent(t, w, start.Add(time.Duration(i)*time.Minute), map[string]interface{}{"user": "ann"})
+	}
+	var rc = windowEvent(t, w, start.Add(9*time.Minute), map[string]interface{}{"user": "ann"})
+	assert.Equal(t, 6, rc.Get("logins.count"))
+
+	rc = windowEvent(t, w, start.Add(9*time.Minute), map[string]interface{}{"user": "bob"})
+	assert.Equal(t, 1, rc.Get("logins.count"))
+
+	rc = windowEvent(t, w, start.Add(12*time.Minute), map[string]interface{}{"user": "ann"})
+	assert.Equal(t, 4, rc.Get("logins.count"))
+	assert.Nil(t, rc.Get("logins.sum"))
+}
+
+func TestWindow_Tumbling(t *testing.T) {
+	var start = time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	var w = NewWindow("spent", TumblingWindow, time.Hour).Sum("amount")
+
+	windowEvent(t, w, start, map[string]interface{}{"amount": 10})
+	var rc = windowEvent(t, w, start.Add(59*time.Minute), map[string]interface{}{"amount": 20.5})
+	assert.Equal(t, 2, rc.Get("spent.count"))
+	assert.Equal(t, 30.5, rc.Get("spent.sum"))
+	assert.Equal(t, 15.25, rc.Get("spent.avg"))
+
+	rc = windowEvent(t, w, start.Add(61*time.Minute), map[string]interface{}{"amount": uint8(4)})
+	assert.Equal(t, 1, rc.Get("spent.count"))
+	assert.Equal(t, 4.0, rc.Get("spent.sum"))
+}
+
+func TestWindow_Errors(t *testing.T) {
+	var w = NewWindow("spent", SlidingWindow, time.Hour).By("user").Sum("amount")
+
+	rc := NewRuleContext()
+	rc.Set("amount", 1)
+	assert.EqualError(t, w.Observe(rc), `window "spent": key "user" is missing`)
+
+	rc.Set("user", "ann")
+	rc.Set("amount", "ten")
+	assert.EqualError(t, w.Observe(rc), `window "spent": key "amount" holds string, not a number`)
+	assert.Equal(t, 0, w.Prune(time.Now()))
+
+	assert.PanicsWithValue(t, "window size must be positive", func() { NewWindow("w", SlidingWindow, 0) })
+}
+
+func TestWindow_Prune(t *testing.T) {
+	var start = time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	var w = NewWindow("logins", SlidingWindow, 10*time.Minute).By("user")
+
+	windowEvent(t, w, start, map[string]interface{}{"user": "ann"})
+	windowEvent(t, w, start.Add(5*time.Minute), map[string]interface{}{"user": "bob"})
+
+	assert.Equal(t, 2, w.Prune(start.Add(9*time.Minute)))
+	assert.Equal(t, 1, w.Prune(start.Add(10*time.Minute)))
+	assert.Equal(t, 0, w.Prune(start.Add(15*time.Minute)))
+}
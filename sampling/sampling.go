@@ -0,0 +1,120 @@
+// Package sampling captures a fraction of rule set runs, with their inputs
+// and outputs, to a sink for offline analysis and backtesting.
+package sampling
+
+import (
+	"math/rand"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/leoslamas/dredd-go/rule"
+)
+
+// Redacted replaces the value of redacted keys in samples.
+const Redacted = "[REDACTED]"
+
+// Sample is a captured run.
+type Sample struct {
+	Time time.Time
+	// Input holds the context values before the run.
+	Input map[string]interface{}
+	// Output holds the keys added or changed by the run.
+	Output map[string]interface{}
+	// Decision is the aggregated decision of the run, if any was set.
+	Decision *rule.ScoredDecision
+	// Error is the message of the error returned by the run, if it failed.
+	Error string
+}
+
+// Sink receives captured samples.
+type Sink interface {
+	Write(sample Sample) error
+}
+
+// SinkFunc adapts an ordinary function to the Sink interface.
+type SinkFunc func(sample Sample) error
+
+// Write calls f(sample).
+func (f SinkFunc) Write(sample Sample) error {
+	return f(sample)
+}
+
+// Sampler captures a fraction of the runs it wraps. It is safe for concurrent use.
+type Sampler struct {
+	rate   float64
+	sink   Sink
+	redact map[string]bool
+	mu     sync.Mutex
+	rand   func() float64
+	errors func(error)
+}
+
+// New creates a Sampler capturing the given fraction of runs, between 0 and 1.
+func New(rate float64, sink Sink) *Sampler {
+	return &Sampler{rate: rate, sink: sink, redact: make(map[string]bool), rand: rand.Float64}
+}
+
+// Redact hides the values of the given keys in captured samples.
+func (s *Sampler) Redact(keys ...string) *Sampler {
+	for _, k := range keys {
+		s.redact[k] = true
+	}
+	return s
+}
+
+// WithRand sets the source of random numbers in [0, 1) deciding which runs are
+// sampled, for deterministic tests.
+func (s *Sampler) WithRand(f func() float64) *Sampler {
+	s.rand = f
+	return s
+}
+
+// OnError sets a function receiving the sink errors, which are dropped by default.
+func (s *Sampler) OnError(f func(error)) *Sampler {
+	s.errors = f
+	return s
+}
+
+// Run runs the program with the context, captures the run if it is sampled,
+// failed or not, and returns the error of the program.
+func (s *Sampler) Run(rc *rule.RuleContext, run rule.Program) error {
+	s.mu.Lock()
+	var sampled = s.rand() < s.rate
+	s.mu.Unlock()
+
+	if !sampled {
+		return run(rc)
+	}
+
+	var input = rc.Values()
+	var runErr = run(rc)
+
+	var sample = Sample{Time: rc.Now(), Input: s.redacted(input), Output: make(map[string]interface{})}
+	for k, v := range rc.Values() {
+		if before, ok := input[k]; !ok || !reflect.DeepEqual(before, v) {
+			sample.Output[k] = v
+		}
+	}
+	sample.Output = s.redacted(sample.Output)
+	if decision, ok := rc.Decision(); ok {
+		sample.Decision = &decision
+	}
+	if runErr != nil {
+		sample.Error = runErr.Error()
+	}
+
+	if err := s.sink.Write(sample); err != nil && s.errors != nil {
+		s.errors(err)
+	}
+	return runErr
+}
+
+func (s *Sampler) redacted(values map[string]interface{}) map[string]interface{} {
+	for k := range values {
+		if s.redact[k] {
+			values[k] = Redacted
+		}
+	}
+	return values
+}
@@ -0,0 +1,84 @@
+package sampling
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/leoslamas/dredd-go/rule"
+	"github.com/stretchr/testify/assert"
+)
+
+func decide(rc *rule.RuleContext) error {
+	return rule.ChainRuleRunner(rc, rule.NewChainRule().OnExecute(func(ctx rule.Context) {
+		ctx.GetRuleContext().Set("decision", "approve")
+		ctx.GetRuleContext().Set("card", "4111-xxxx")
+		ctx.GetRuleContext().SetDecision("approve", 0.9)
+	}))
+}
+
+func TestSampler_CapturesSampledRuns(t *testing.T) {
+	var samples []Sample
+	var draws = []float64{0.05, 0.5, 0.09}
+	sampler := New(0.1, SinkFunc(func(s Sample) error {
+		samples = append(samples, s)
+		return nil
+	})).Redact("card").WithRand(func() float64 {
+		var d = draws[0]
+		draws = draws[1:]
+		return d
+	})
+
+	for i := 0; i < 3; i++ {
+		rc := rule.NewRuleContext()
+		rc.Set("amount", 100)
+		rc.Set("card", "4111-1111")
+		assert.NoError(t, sampler.Run(rc, decide))
+		assert.Equal(t, "approve", rc.Get("decision"))
+	}
+
+	assert.Equal(t, 2, len(samples))
+	assert.Equal(t, map[string]interface{}{"amount": 100, "card": Redacted}, samples[0].Input)
+	assert.Equal(t, map[string]interface{}{"decision": "approve", "card": Redacted}, samples[0].Output)
+	assert.Equal(t, 0.9, samples[0].Decision.Confidence)
+	assert.False(t, samples[0].Time.IsZero())
+	assert.Empty(t, samples[0].Error)
+}
+
+func TestSampler_FailedRuns(t *testing.T) {
+	var samples []Sample
+	sampler := New(1, SinkFunc(func(s Sample) error {
+		samples = append(samples, s)
+		return nil
+	}))
+
+	var failure = errors.New("model down")
+	var err = sampler.Run(rule.NewRuleContext(), func(rc *rule.RuleContext) error {
+		rc.Set("decision", "approve")
+		return failure
+	})
+
+	assert.Equal(t, failure, err)
+	assert.Equal(t, "model down", samples[0].Error)
+
+	var unsampled = New(0, SinkFunc(func(Sample) error { return nil }))
+	assert.Equal(t, failure, unsampled.Run(rule.NewRuleContext(), func(*rule.RuleContext) error { return failure }))
+}
+
+func TestSampler_SinkErrors(t *testing.T) {
+	var failure = errors.New("sink down")
+	var reported error
+	sampler := New(1, SinkFunc(func(Sample) error { return failure })).OnError(func(err error) { reported = err })
+
+	sampler.Run(rule.NewRuleContext(), decide)
+
+	assert.Equal(t, failure, reported)
+}
+
+func TestSampler_NeverSamplesAtZeroRate(t *testing.T) {
+	sampler := New(0, SinkFunc(func(Sample) error {
+		t.Error("unexpected sample")
+		return nil
+	}))
+
+	sampler.Run(rule.NewRuleContext(), decide)
+}